@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/metolius25/spotirice/internal/daemon"
+)
+
+// barPollInterval is how often `spotirice bar --follow` checks the player.
+const barPollInterval = 2 * time.Second
+
+// defaultBarText is the bar's text unless --text says otherwise.
+const defaultBarText = "{icon} {artist} – {track}"
+
+// waybarOutput is one update of a waybar custom module in its JSON mode.
+type waybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Alt        string `json:"alt"`
+	Percentage int    `json:"percentage"`
+}
+
+// runBar prints the playing track for a status bar: waybar JSON by default,
+// a plain line for polybar with --format polybar. It prints once, or with
+// --follow a new line whenever the output changes, until interrupted.
+func runBar(args []string) error {
+	fs := flag.NewFlagSet("bar", flag.ContinueOnError)
+	format := fs.String("format", "waybar", "output format: waybar or polybar")
+	text := fs.String("text", defaultBarText, "what to show; {track}, {artist}, {device}, {volume}, {progress}, {duration}, {status} and {icon} are filled in")
+	follow := fs.Bool("follow", false, "keep running and print each change")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "waybar" && *format != "polybar" {
+		return fmt.Errorf("unknown format %q (want waybar or polybar)", *format)
+	}
+
+	read, err := statusReader()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !*follow {
+		st, err := read(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(barLine(*format, *text, st))
+		return nil
+	}
+
+	t := time.NewTicker(barPollInterval)
+	defer t.Stop()
+	last := ""
+	for {
+		// A failed read keeps the last line up rather than blanking the bar
+		if st, err := read(ctx); err == nil {
+			if line := barLine(*format, *text, st); line != last {
+				fmt.Println(line)
+				last = line
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// barStatus names the playback state; it is also the waybar class.
+func barStatus(st daemon.Status) string {
+	switch {
+	case st.Track == "":
+		return "stopped"
+	case st.Playing:
+		return "playing"
+	}
+	return "paused"
+}
+
+// barText fills in the placeholders of text from st, passing the values
+// through escape so the bar shows them as they are.
+func barText(text string, st daemon.Status, escape func(string) string) string {
+	icon := "⏸"
+	if st.Playing {
+		icon = "▶"
+	}
+	return strings.NewReplacer(
+		"{track}", escape(st.Track),
+		"{artist}", escape(st.Artist),
+		"{device}", escape(st.Device),
+		"{volume}", strconv.Itoa(st.Volume),
+		"{progress}", barDuration(st.ProgressMs),
+		"{duration}", barDuration(st.DurationMs),
+		"{status}", barStatus(st),
+		"{icon}", icon,
+	).Replace(text)
+}
+
+func barDuration(ms int) string {
+	s := ms / 1000
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// barLine is the line printed for st. Nothing playing gives an empty text,
+// which hides the module in both bars.
+func barLine(format, text string, st daemon.Status) string {
+	status := barStatus(st)
+	if format == "polybar" {
+		if status == "stopped" {
+			return ""
+		}
+		// %{ starts a polybar formatting tag; %%{ is a literal one
+		return barText(text, st, func(s string) string { return strings.ReplaceAll(s, "%{", "%%{") })
+	}
+
+	w := waybarOutput{Class: status, Alt: status}
+	if status != "stopped" {
+		// Waybar reads the text as Pango markup, so --text may use it too
+		w.Text = barText(text, st, html.EscapeString)
+		w.Tooltip = html.EscapeString(fmt.Sprintf("%s\n%s\n%s / %s on %s",
+			st.Track, st.Artist, barDuration(st.ProgressMs), barDuration(st.DurationMs), st.Device))
+	}
+	if st.DurationMs > 0 {
+		w.Percentage = st.ProgressMs * 100 / st.DurationMs
+	}
+	b, _ := json.Marshal(w)
+	return string(b)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/auth"
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/daemon"
+	"github.com/metolius25/spotirice/internal/metrics"
+)
+
+const usage = `Usage:
+  spotirice [--config FILE] [--portable] [--profile NAME]
+                                           start the player
+  spotirice --mock                         start the player against canned data, no account needed
+  spotirice --record FILE                  start the player, saving its API calls (without
+                                           your name, email or token) to FILE on exit
+  spotirice --demo FILE                    replay a recorded session, no account needed
+  spotirice login [--headless]             log in again; --headless to paste the redirect
+                                           address instead of waiting for a local browser
+  spotirice secret                         save the client secret in the system keyring
+  spotirice [--config FILE] daemon         run the background daemon
+  spotirice service install                install and start the daemon as a systemd user service
+  spotirice service uninstall              stop and remove the service
+  spotirice play|pause|toggle|next|prev|like
+                                           control playback through the daemon
+  spotirice volume N                       set the volume (0-100) through the daemon
+  spotirice seek MS                        jump to MS milliseconds into the track through the daemon
+  spotirice run NAME [ARGS...]             run a command defined by a Lua script in the daemon
+  spotirice queue <uri|url|query>          add a track, album or playlist (or the
+                                           first search result) to the queue
+  spotirice rpc                            JSON-RPC 2.0 over stdin/stdout, for editor plugins
+  spotirice events [--json]                stream playback events until interrupted
+  spotirice status [--json]                what is playing; --json for scripts
+  spotirice bar [--format waybar|polybar] [--text FORMAT] [--follow]
+                                           the playing track for a status bar
+  spotirice devices [--json]               list Connect devices (* = active)
+  spotirice devices --transfer NAME|ID [--play]
+                                           move playback to another device
+  spotirice wrapped [--format text|markdown|json] [--limit N]
+                                           top tracks and artists, plus local history
+
+Environment:
+  SPOTIRICE_CONFIG_DIR   config.toml and credentials.json (default ~/.config/spotirice)
+  SPOTIRICE_STATE_DIR    token and saved state
+  SPOTIRICE_CACHE_DIR    cached data
+  SPOTIRICE_PROFILE      the profile to use when --profile isn't given
+
+With --portable, or a file named "portable" next to the binary, everything is
+kept in spotirice-data/ next to the binary.
+
+--profile NAME (before any command) keeps a separate config, login and cache
+in profiles/NAME of each of these directories, so several accounts can be used.
+`
+
+// runCommand runs the subcommand named by args and returns the exit code.
+// ok is false when args don't name a subcommand, so the TUI should start.
+func runCommand(args []string) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+
+	var err error
+	switch args[0] {
+	case "login":
+		err = runLogin(args[1:])
+	case "secret":
+		err = runSecret()
+	case "daemon":
+		err = runDaemon()
+	case "service":
+		err = runService(args[1:])
+	case "play", "pause", "toggle", "next", "prev", "like", "volume", "seek", "run":
+		err = runControl(args)
+	case "queue":
+		err = runQueue(args[1:])
+	case "devices":
+		err = runDevices(args[1:])
+	case "rpc":
+		err = runRPC()
+	case "events":
+		err = runEvents(args[1:])
+	case "wrapped":
+		err = runWrapped(args[1:])
+	case "status":
+		err = runStatus(args[1:])
+	case "bar":
+		err = runBar(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return 0, true
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2, true
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1, true
+	}
+	return 0, true
+}
+
+func runDaemon() error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	opts := daemon.Options{
+		GlobalHotkeys: settings.GlobalHotkeys,
+		Webhooks:      settings.Webhooks,
+		Scripts:       settings.Scripts,
+		PauseOn:       settings.PauseOn,
+		ResumeOn:      settings.ResumeOn,
+		Party:         settings.Party,
+		History:       settings.RecordHistory,
+	}
+	if settings.MetricsAddr != "" {
+		// Installed before authenticating so every client counts its calls
+		opts.Metrics = metrics.New()
+		opts.MetricsAddr = settings.MetricsAddr
+		auth.SetTransport(opts.Metrics.Transport(nil))
+	}
+	client, err := auth.Authenticate()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if settings.ControlFIFO {
+		if opts.FIFO, err = daemon.FIFOPath(); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Listening on", daemon.SocketPath())
+	return daemon.Serve(ctx, client, opts)
+}
+
+// apiClient returns a Spotify client for a command: with a daemon running,
+// one whose calls the daemon makes, so no login is needed; otherwise a
+// freshly authenticated one.
+func apiClient() (*spotify.Client, error) {
+	if daemon.Running() {
+		return daemon.Client(), nil
+	}
+	return auth.Authenticate()
+}
+
+// playerController returns a Controller for a command: the running daemon's,
+// whose state it already polls, or one of its own.
+func playerController() (*daemon.Controller, error) {
+	if daemon.Running() {
+		return daemon.Attach(), nil
+	}
+	client, err := auth.Authenticate()
+	if err != nil {
+		return nil, err
+	}
+	return daemon.NewController(client), nil
+}
+
+// runControl forwards a playback command to the daemon, so it can be bound
+// to desktop shortcuts without starting the TUI.
+func runControl(args []string) error {
+	resp, err := daemon.Send(daemon.Request{Cmd: args[0], Args: args[1:]})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+func runService(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: spotirice service install|uninstall")
+	}
+	switch args[0] {
+	case "install":
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		path, err := daemon.InstallService(exe)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Installed and started", path)
+	case "uninstall":
+		if err := daemon.UninstallService(); err != nil {
+			return err
+		}
+		fmt.Println("Service removed.")
+	default:
+		return fmt.Errorf("unknown service action %q", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/auth"
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/demo"
+	"github.com/metolius25/spotirice/internal/ui/root"
+)
+
+// startDemo replays the session recorded in path for --demo, isolated like
+// --mock.
+func startDemo(settings *config.Settings, path string) (authenticate func() (*spotify.Client, error), cleanup func(), err error) {
+	player, err := demo.Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup, err = isolate(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	root.SetTransport(player.Transport())
+	authenticate = func() (*spotify.Client, error) { return player.Client(), nil }
+	return authenticate, cleanup, nil
+}
+
+// startRecording sends the player's API calls through a recorder for
+// --record. save writes them to path once the player has quit.
+func startRecording(path string) (save func() error) {
+	rec := demo.NewRecorder(nil)
+	auth.SetTransport(rec)
+	root.SetTransport(rec)
+	return func() error { return rec.Save(path) }
+}
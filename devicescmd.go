@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/metolius25/spotirice/internal/devices"
+)
+
+// deviceJSON is the --json form of one device.
+type deviceJSON struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Active     bool   `json:"active"`
+	Restricted bool   `json:"restricted"`
+	Volume     int    `json:"volume"`
+}
+
+// runDevices lists the Connect devices, or moves playback to one of them.
+func runDevices(args []string) error {
+	fs := flag.NewFlagSet("devices", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the devices as JSON")
+	transfer := fs.String("transfer", "", "move playback to the device with this name or ID")
+	play := fs.Bool("play", false, "with --transfer, start playing on the new device")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := apiClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	devs, err := client.PlayerDevices(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *transfer != "" {
+		d := devices.Find(devs, *transfer)
+		if d == nil {
+			return fmt.Errorf("no available device matches %q", *transfer)
+		}
+		if err := client.TransferPlayback(ctx, d.ID, *play); err != nil {
+			return err
+		}
+		fmt.Println("Playback moved to", d.Name)
+		return nil
+	}
+
+	if *asJSON {
+		out := make([]deviceJSON, 0, len(devs))
+		for _, d := range devs {
+			out = append(out, deviceJSON{
+				ID:         string(d.ID),
+				Name:       d.Name,
+				Type:       d.Type,
+				Active:     d.Active,
+				Restricted: d.Restricted,
+				Volume:     int(d.Volume),
+			})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	if len(devs) == 0 {
+		fmt.Println("No devices found. Open Spotify on the device you want to control.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tTYPE\tVOLUME\tID")
+	for _, d := range devs {
+		mark := ""
+		if d.Active {
+			mark = "*"
+		}
+		volume := fmt.Sprintf("%d%%", d.Volume)
+		if d.Restricted {
+			volume = "restricted"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", mark, d.Name, d.Type, volume, d.ID)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/metolius25/spotirice/internal/daemon"
+)
+
+// eventsPollInterval is how often `spotirice events` checks for changes.
+const eventsPollInterval = 2 * time.Second
+
+// runEvents prints playback events as they happen until interrupted, either
+// as readable lines or, with --json, one JSON object per line.
+func runEvents(args []string) error {
+	fs := flag.NewFlagSet("events", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print events as JSON lines")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctrl, err := playerController()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enc := json.NewEncoder(os.Stdout)
+	ctrl.OnChange = daemon.Watch(func(ev daemon.Event) {
+		if *asJSON {
+			_ = enc.Encode(ev)
+		} else {
+			fmt.Println(describeEvent(ev.Type, ev.Status))
+		}
+	})
+	ctrl.Poll(ctx, eventsPollInterval)
+	return nil
+}
+
+func describeEvent(typ string, st daemon.Status) string {
+	switch typ {
+	case daemon.EventTrackChanged:
+		return "Now playing: " + st.Artist + " – " + st.Track
+	case daemon.EventPaused:
+		return "Paused"
+	case daemon.EventResumed:
+		return "Resumed"
+	case daemon.EventLiked:
+		return "Liked " + st.Track
+	case daemon.EventUnliked:
+		return "Unliked " + st.Track
+	case daemon.EventDeviceChanged:
+		return "Device: " + st.Device
+	case daemon.EventVolumeChanged:
+		return fmt.Sprintf("Volume: %d%%", st.Volume)
+	}
+	return typ
+}
@@ -1,16 +1,23 @@
 package auth
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"os/exec"
-	"runtime"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/platform"
 	spotify "github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
@@ -18,99 +25,323 @@ import (
 
 const redirectURI = "http://127.0.0.1:8000/callback"
 
-// openBrowser opens the specified URL in the default browser (cross-platform)
-func openBrowser(url string) error {
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
-	default: // linux, freebsd, etc.
-		cmd = exec.Command("xdg-open", url)
+// scopeUserReadPlaybackPosition is needed for episode resume points; the
+// spotify library doesn't define a constant for it.
+const scopeUserReadPlaybackPosition = "user-read-playback-position"
+
+// scopes are the permissions Spotirice asks for. A saved login granted fewer
+// (from before a feature needing another was added) is replaced; see
+// missingScopes.
+var scopes = []string{
+	spotifyauth.ScopeUserReadPrivate,
+	spotifyauth.ScopeUserReadPlaybackState,
+	spotifyauth.ScopeUserReadCurrentlyPlaying,
+	spotifyauth.ScopeUserModifyPlaybackState,
+	spotifyauth.ScopeUserLibraryRead,
+	spotifyauth.ScopeUserLibraryModify,
+	spotifyauth.ScopeUserTopRead,
+	spotifyauth.ScopePlaylistReadPrivate,
+	spotifyauth.ScopePlaylistReadCollaborative,
+	spotifyauth.ScopePlaylistModifyPublic,
+	spotifyauth.ScopePlaylistModifyPrivate,
+	scopeUserReadPlaybackPosition,
+}
+
+// missingScopes returns the scopes token wasn't granted. A token saved
+// without its scope can't be checked, so all of them count as missing.
+func missingScopes(token *oauth2.Token) []string {
+	granted, _ := token.Extra("scope").(string)
+	have := strings.Fields(granted)
+	var missing []string
+	for _, s := range scopes {
+		if !slices.Contains(have, s) {
+			missing = append(missing, s)
+		}
 	}
-	return cmd.Start()
+	return missing
 }
 
-func Authenticate() (*spotify.Client, error) {
+// newAuthenticator sets up the OAuth client from the credentials. pkce is
+// true when they have no client secret: a native app then proves it started
+// the login with a PKCE code verifier instead.
+func newAuthenticator() (auth *spotifyauth.Authenticator, pkce bool, err error) {
 	creds, err := config.LoadCredentials()
 	if err != nil {
-		return nil, fmt.Errorf("could not load credentials: %w", err)
+		return nil, false, fmt.Errorf("could not load credentials: %w", err)
+	}
+	if creds.ClientID == "" {
+		return nil, false, errors.New("no client_id in the credentials")
 	}
 
-	auth := spotifyauth.New(
+	return spotifyauth.New(
 		spotifyauth.WithRedirectURL(redirectURI),
-		spotifyauth.WithScopes(
-			spotifyauth.ScopeUserReadPrivate,
-			spotifyauth.ScopeUserReadPlaybackState,
-			spotifyauth.ScopeUserReadCurrentlyPlaying,
-			spotifyauth.ScopeUserModifyPlaybackState,
-			spotifyauth.ScopeUserLibraryRead,
-			spotifyauth.ScopeUserLibraryModify,
-		),
+		spotifyauth.WithScopes(scopes...),
 		spotifyauth.WithClientID(creds.ClientID),
 		spotifyauth.WithClientSecret(creds.ClientSecret),
-	)
+	), creds.ClientSecret == "", nil
+}
+
+// transport, if set, carries every API request; see SetTransport.
+var transport http.RoundTripper
+
+// SetTransport routes the API requests of clients created afterwards
+// through rt, for instance to count them.
+func SetTransport(rt http.RoundTripper) {
+	transport = rt
+}
+
+// clientContext hands transport to the oauth2 client, which takes its base
+// HTTP client from the context.
+func clientContext() context.Context {
+	ctx := context.Background()
+	if transport != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport})
+	}
+	return ctx
+}
+
+// newClient returns an API client using token, saving the token whenever it
+// is refreshed.
+func newClient(auth *spotifyauth.Authenticator, token *oauth2.Token) *spotify.Client {
+	ctx := clientContext()
+	src := &savingTokenSource{auth: auth, ctx: ctx, token: token}
+	return spotify.New(oauth2.NewClient(ctx, src))
+}
+
+// savingTokenSource refreshes the token when it expires and saves the new
+// one. Spotify may hand out a new refresh token with it, and with PKCE the
+// old one then stops working, so it has to be kept.
+type savingTokenSource struct {
+	auth *spotifyauth.Authenticator
+	ctx  context.Context
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	token, err := s.auth.RefreshToken(s.ctx, s.token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Extra("scope") == nil {
+		// Spotify normally repeats it, but it mustn't get lost
+		token = token.WithExtra(map[string]any{"scope": s.token.Extra("scope")})
+	}
+	s.token = token
+	if err := config.SaveToken(token); err != nil {
+		log.Printf("Could not save token: %v", err)
+	}
+	return token, nil
+}
+
+func Authenticate() (*spotify.Client, error) {
+	auth, pkce, err := newAuthenticator()
+	if err != nil {
+		return nil, err
+	}
 
 	if config.TokenExists() {
 		token, err := config.LoadToken()
-		if err == nil {
-			client := spotify.New(auth.Client(context.Background(), token))
-			return client, nil
+		if err != nil {
+			log.Printf("Could not load token, re-authenticating: %v", err)
+		} else if missing := missingScopes(token); len(missing) > 0 {
+			fmt.Println("Spotirice needs permissions the saved login doesn't have (" + strings.Join(missing, ", ") + "); log in again to grant them.")
+		} else {
+			return newClient(auth, token), nil
 		}
-		log.Printf("Could not load token, re-authenticating: %v", err)
 	}
 
-	return fullOAuthFlow(auth)
+	return fullOAuthFlow(auth, pkce)
+}
+
+func fullOAuthFlow(auth *spotifyauth.Authenticator, pkce bool) (*spotify.Client, error) {
+	if platform.Remote() {
+		// The callback server would listen on this machine, not the user's
+		return AuthenticateHeadless(os.Stdin, os.Stdout)
+	}
+	login, err := startLogin(auth, pkce)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Please log in to Spotify by visiting the following page in your browser:", login.URL)
+	platform.OpenURL(login.URL)
+
+	return login.Wait()
+}
+
+// Reauthenticate logs in afresh, replacing the saved token.
+func Reauthenticate() (*spotify.Client, error) {
+	auth, pkce, err := newAuthenticator()
+	if err != nil {
+		return nil, err
+	}
+	return fullOAuthFlow(auth, pkce)
+}
+
+// AuthenticateHeadless logs in without the callback server, for machines
+// with no browser: the user opens the printed URL anywhere and pastes back
+// the address Spotify redirected to (or just its code), read from in. The
+// redirect itself fails to load, which is expected.
+func AuthenticateHeadless(in io.Reader, out io.Writer) (*spotify.Client, error) {
+	auth, pkce, err := newAuthenticator()
+	if err != nil {
+		return nil, err
+	}
+	state, err := generateRandomState()
+	if err != nil {
+		return nil, err
+	}
+	authOpts, exchangeOpts := pkceOptions(pkce)
+
+	fmt.Fprintln(out, "Open this page in a browser on any device and log in to Spotify:")
+	fmt.Fprintln(out, auth.AuthURL(state, authOpts...))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "The browser then goes to a page on "+redirectURI+" that won't load.")
+	fmt.Fprint(out, "Paste that page's address here: ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("no address entered: %w", err)
+	}
+	code, err := callbackCode(strings.TrimSpace(line), state)
+	if err != nil {
+		return nil, err
+	}
+	token, err := auth.Exchange(clientContext(), code, exchangeOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get token: %w", err)
+	}
+	if err := config.SaveToken(token); err != nil {
+		log.Printf("Could not save token: %v", err)
+	}
+	return newClient(auth, token), nil
+}
+
+// callbackCode takes the authorization code from what the user pasted: the
+// whole redirect address, checked against state, or the bare code.
+func callbackCode(pasted, state string) (string, error) {
+	if pasted == "" {
+		return "", errors.New("no address entered")
+	}
+	if !strings.Contains(pasted, "code=") && !strings.Contains(pasted, "error=") {
+		return pasted, nil
+	}
+	query := pasted
+	if i := strings.Index(pasted, "?"); i >= 0 {
+		query = pasted[i+1:]
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read the address: %w", err)
+	}
+	if e := values.Get("error"); e != "" {
+		return "", errors.New("spotify: auth failed - " + e)
+	}
+	if values.Get("state") != state {
+		return "", errors.New("the address is from a different login; paste the one from this login's page")
+	}
+	return values.Get("code"), nil
+}
+
+// Login is an OAuth flow waiting for the browser to hit the callback.
+type Login struct {
+	// URL is the Spotify page the user has to visit.
+	URL string
+
+	auth   *spotifyauth.Authenticator
+	server *http.Server
+	ch     chan *oauth2.Token
+	errCh  chan error
+}
+
+// StartLogin begins a fresh OAuth flow, e.g. after the saved refresh token
+// was revoked. The caller shows or opens Login.URL, then calls Wait.
+func StartLogin() (*Login, error) {
+	auth, pkce, err := newAuthenticator()
+	if err != nil {
+		return nil, err
+	}
+	return startLogin(auth, pkce)
 }
 
-func fullOAuthFlow(auth *spotifyauth.Authenticator) (*spotify.Client, error) {
+func startLogin(auth *spotifyauth.Authenticator, pkce bool) (*Login, error) {
 	state, err := generateRandomState()
 	if err != nil {
 		return nil, err
 	}
 
-	ch := make(chan *oauth2.Token)
-	errCh := make(chan error)
+	authOpts, exchangeOpts := pkceOptions(pkce)
+
+	l := &Login{
+		URL:   auth.AuthURL(state, authOpts...),
+		auth:  auth,
+		ch:    make(chan *oauth2.Token, 1),
+		errCh: make(chan error, 2),
+	}
 
 	mux := http.NewServeMux()
-	server := &http.Server{Addr: "127.0.0.1:8000", Handler: mux}
+	l.server = &http.Server{Addr: "127.0.0.1:8000", Handler: mux}
 
 	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		token, err := auth.Token(r.Context(), state, r)
+		token, err := auth.Token(r.Context(), state, r, exchangeOpts...)
 		if err != nil {
 			log.Printf("Error getting token: %v", err)
 			http.Error(w, "Couldn't get token", http.StatusForbidden)
-			errCh <- fmt.Errorf("couldn't get token: %w", err)
+			l.errCh <- fmt.Errorf("couldn't get token: %w", err)
 			return
 		}
 		if err := config.SaveToken(token); err != nil {
 			log.Printf("Could not save token: %v", err)
 		}
 		fmt.Fprintln(w, "Authenticated! You can close this window.")
-		ch <- token
+		l.ch <- token
 	})
 
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errCh <- fmt.Errorf("server failed: %w", err)
+		if err := l.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			l.errCh <- fmt.Errorf("server failed: %w", err)
 		}
 	}()
-	defer server.Shutdown(context.Background())
+	return l, nil
+}
 
-	url := auth.AuthURL(state)
-	fmt.Println("Please log in to Spotify by visiting the following page in your browser:", url)
-	openBrowser(url)
+// Wait blocks until the user has logged in and returns the new client.
+func (l *Login) Wait() (*spotify.Client, error) {
+	defer l.server.Shutdown(context.Background())
 
 	select {
-	case token := <-ch:
-		client := spotify.New(auth.Client(context.Background(), token))
-		return client, nil
-	case err := <-errCh:
+	case token := <-l.ch:
+		return newClient(l.auth, token), nil
+	case err := <-l.errCh:
 		return nil, err
 	}
 }
 
+// pkceOptions returns the options a login passes to the authorization URL
+// and to the token exchange: with pkce, the challenge and its verifier.
+func pkceOptions(pkce bool) (authOpts, exchangeOpts []oauth2.AuthCodeOption) {
+	if !pkce {
+		return nil, nil
+	}
+	verifier := oauth2.GenerateVerifier()
+	return []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)},
+		[]oauth2.AuthCodeOption{oauth2.VerifierOption(verifier)}
+}
+
+// IsRevoked reports whether err means the saved refresh token is no longer
+// accepted (password change, app access removed), so only a new login helps.
+func IsRevoked(err error) bool {
+	var re *oauth2.RetrieveError
+	return errors.As(err, &re) && re.ErrorCode == "invalid_grant"
+}
+
 func generateRandomState() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCallbackCode(t *testing.T) {
+	tests := []struct {
+		pasted  string
+		want    string
+		wantErr string
+	}{
+		{"http://127.0.0.1:8000/callback?code=AQBx&state=s1", "AQBx", ""},
+		{"http://127.0.0.1:8000/callback?state=s1&code=AQBx", "AQBx", ""},
+		{"127.0.0.1:8000/callback?code=AQBx&state=s1", "AQBx", ""},
+		{"code=AQBx&state=s1", "AQBx", ""},
+		{"AQBx", "AQBx", ""},
+		{"", "", "no address entered"},
+		{"http://127.0.0.1:8000/callback?code=AQBx&state=s2", "", "different login"},
+		{"http://127.0.0.1:8000/callback?code=AQBx", "", "different login"},
+		{"http://127.0.0.1:8000/callback?error=access_denied&state=s1", "", "access_denied"},
+		{"http://127.0.0.1:8000/callback?code=%zz&state=s1", "", "couldn't read"},
+	}
+	for _, tt := range tests {
+		got, err := callbackCode(tt.pasted, "s1")
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("callbackCode(%q) error = %v, want %q", tt.pasted, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("callbackCode(%q) = %q, %v; want %q", tt.pasted, got, err, tt.want)
+		}
+	}
+}
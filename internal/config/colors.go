@@ -1,21 +1,31 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Colors defines the color scheme for the UI.
 type Colors struct {
-	Header        string `toml:"header"`
-	TrackPlaying  string `toml:"track_playing"`
-	TrackPaused   string `toml:"track_paused"`
-	Artist        string `toml:"artist"`
-	ProgressBar   string `toml:"progress_bar"`
-	Status        string `toml:"status"`
-	Error         string `toml:"error"`
+	Header       string `toml:"header"`
+	TrackPlaying string `toml:"track_playing"`
+	TrackPaused  string `toml:"track_paused"`
+	Artist       string `toml:"artist"`
+	ProgressBar  string `toml:"progress_bar"`
+	// ProgressBarEnding replaces ProgressBar for the last EndingSeconds of a
+	// track; empty disables the cue.
+	ProgressBarEnding string `toml:"progress_bar_ending"`
+	EndingSeconds     int    `toml:"progress_bar_ending_seconds"`
+	// EndingBlink alternates between the two colors instead.
+	EndingBlink bool   `toml:"progress_bar_ending_blink"`
+	Status      string `toml:"status"`
+	Error       string `toml:"error"`
 }
 
 // DefaultColors provides a fallback color scheme.
@@ -26,6 +36,7 @@ func DefaultColors() *Colors {
 		TrackPaused:   "#FFFF00", // yellow
 		Artist:        "#FFFFFF", // white
 		ProgressBar:   "#FFFFFF", // white
+		EndingSeconds: 10,
 		Status:        "#808080", // grey
 		Error:         "#FF0000", // red
 	}
@@ -33,13 +44,23 @@ func DefaultColors() *Colors {
 
 // LoadColors reads the config.toml file and returns a Colors struct.
 func LoadColors() (*Colors, error) {
-	path := filepath.Join(os.Getenv("HOME"), ".config", "spotirice", "config.toml")
-	
+	path := configFilePath()
+
 	// Start with default colors
 	colors := DefaultColors()
 
 	// If the config file exists, decode it and override defaults
 	if _, err := os.Stat(path); err == nil {
+		// The theme picks the base palette; colors set alongside it win
+		var base struct {
+			Theme string `toml:"theme"`
+		}
+		if _, err := toml.DecodeFile(path, &base); err != nil {
+			return nil, err
+		}
+		if colors, err = themeColors(base.Theme); err != nil {
+			return nil, err
+		}
 		if _, err := toml.DecodeFile(path, colors); err != nil {
 			return nil, err
 		}
@@ -47,3 +68,86 @@ func LoadColors() (*Colors, error) {
 
 	return colors, nil
 }
+
+// ColorKeys are the color entries of config.toml, in the order the theme
+// editor lists them.
+var ColorKeys = []string{"header", "track_playing", "track_paused", "artist", "progress_bar", "progress_bar_ending", "status", "error"}
+
+// Color returns the entry of c named by one of ColorKeys, or nil.
+func (c *Colors) Color(key string) *string {
+	switch key {
+	case "header":
+		return &c.Header
+	case "track_playing":
+		return &c.TrackPlaying
+	case "track_paused":
+		return &c.TrackPaused
+	case "artist":
+		return &c.Artist
+	case "progress_bar":
+		return &c.ProgressBar
+	case "progress_bar_ending":
+		return &c.ProgressBarEnding
+	case "status":
+		return &c.Status
+	case "error":
+		return &c.Error
+	}
+	return nil
+}
+
+// tableHeader matches the first [table] or [[array]] line, where top-level
+// keys end.
+var tableHeader = regexp.MustCompile(`(?m)^[ \t]*\[\[?[A-Za-z0-9_-]`)
+
+// SaveColors writes colors (ColorKeys to hex values) into config.toml,
+// replacing the keys' existing lines and adding the others above the first
+// table. The rest of the file, comments included, stays as it is.
+func SaveColors(colors map[string]string) error {
+	path := configFilePath()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	top, tables := data, []byte(nil)
+	if loc := tableHeader.FindIndex(data); loc != nil {
+		top, tables = data[:loc[0]], data[loc[0]:]
+	}
+	var added []byte
+	for _, key := range ColorKeys {
+		value, ok := colors[key]
+		if !ok {
+			continue
+		}
+		line := []byte(fmt.Sprintf("%s = %q", key, value))
+		re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `\s*=.*$`)
+		if re.Match(top) {
+			top = re.ReplaceAllLiteral(top, line)
+			continue
+		}
+		added = append(append(added, line...), '\n')
+	}
+
+	// New keys go after the last top-level line, ahead of any comments that
+	// introduce the first table
+	lines := strings.SplitAfter(string(top), "\n")
+	end := len(lines)
+	for end > 0 {
+		l := strings.TrimSpace(lines[end-1])
+		if l != "" && !strings.HasPrefix(l, "#") {
+			break
+		}
+		end--
+	}
+	body := strings.Join(lines[:end], "")
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	trail := strings.Join(lines[end:], "")
+	if trail == "" && len(tables) > 0 {
+		trail = "\n"
+	}
+	out := append(append([]byte(body), added...), trail...)
+	return writeConfig(path, append(out, tables...))
+}
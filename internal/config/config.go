@@ -1,29 +1,84 @@
 package config
 
 import (
-    "encoding/json"
-    "os"
-    "path/filepath"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
 )
 
+// Credentials is the [credentials] table of config.toml: the Spotify app
+// to log in with.
 type Credentials struct {
-    ClientID     string `json:"client_id"`
-    ClientSecret string `json:"client_secret"`
+	ClientID     string `json:"client_id" toml:"client_id"`
+	ClientSecret string `json:"client_secret" toml:"client_secret"`
+}
+
+// credentialsPath is where credentials were kept before config_version 1,
+// and still are with --config.
+func credentialsPath() string {
+	return filepath.Join(ConfigDir(), "credentials.json")
 }
 
+// LoadCredentials reads the [credentials] table, falling back to
+// credentials.json. A profile without either uses the default profile's
+// [credentials]. Without a client_secret, the one saved in the keyring is
+// used, if any.
 func LoadCredentials() (*Credentials, error) {
-    path := filepath.Join(os.Getenv("HOME"), ".config", "spotirice", "credentials.json")
+	// Checked here so a mistyped secret_store stops the login rather than
+	// quietly leaving the token in a file
+	if _, err := useKeyring(); err != nil {
+		return nil, err
+	}
+
+	var cfg struct {
+		Credentials *Credentials `toml:"credentials"`
+	}
+	_, err := toml.DecodeFile(configFilePath(), &cfg)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	creds := cfg.Credentials
+	if creds == nil && profile != "" && configFileOverride == "" {
+		// Profiles usually log in different accounts with the same app
+		var base struct {
+			Credentials *Credentials `toml:"credentials"`
+		}
+		_, err := toml.DecodeFile(filepath.Join(baseConfigDir(), "config.toml"), &base)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		creds = base.Credentials
+	}
+	if creds == nil {
+		creds, err = readCredentialsJSON()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("no [credentials] in %s", configFilePath())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if creds.ClientSecret == "" {
+		creds.ClientSecret, _ = keyringGet(keyringClientSecret)
+	}
+	return creds, nil
+}
 
-    data, err := os.ReadFile(path)
-    if err != nil {
-        return nil, err
-    }
+func readCredentialsJSON() (*Credentials, error) {
+	data, err := os.ReadFile(credentialsPath())
+	if err != nil {
+		return nil, err
+	}
 
-    var creds Credentials
-    err = json.Unmarshal(data, &creds)
-    if err != nil {
-        return nil, err
-    }
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
 
-    return &creds, nil
+	return &creds, nil
 }
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const historyFileName = "history.jsonl"
+
+// HistoryEntry is one play in the local listening history.
+type HistoryEntry struct {
+	Time       time.Time `json:"time"`
+	TrackID    string    `json:"track_id"`
+	Track      string    `json:"track"`
+	Artist     string    `json:"artist"`
+	DurationMs int       `json:"duration_ms"`
+}
+
+// AppendHistory adds e to the history file, one JSON object per line.
+func AppendHistory(e HistoryEntry) error {
+	path, err := appFilePath(historyFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("could not marshal history entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadHistory returns all recorded plays, oldest first. Lines that don't
+// parse (say, from an interrupted write) are skipped.
+func LoadHistory() ([]HistoryEntry, error) {
+	path, err := appFilePath(historyFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+const lastDeviceFileName = "last_device"
+
+// LoadLastDevice returns the ID of the device playback last ran on, or ""
+// if none has been recorded yet.
+func LoadLastDevice() (string, error) {
+	path, err := appFilePath(lastDeviceFileName)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SaveLastDevice records the ID of the device playback is running on.
+func SaveLastDevice(id string) error {
+	path, err := appFilePath(lastDeviceFileName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(id+"\n"), 0600)
+}
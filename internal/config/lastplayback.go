@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const lastPlaybackFileName = "last_playback.json"
+
+// LastPlayback is what was playing most recently. It is saved while
+// playback runs rather than on exit, so it survives crashes and playback
+// started from other apps while Spotirice was open.
+type LastPlayback struct {
+	ContextURI string    `json:"context_uri,omitempty"`
+	TrackURI   string    `json:"track_uri"`
+	ProgressMs int       `json:"progress_ms"`
+	SavedAt    time.Time `json:"saved_at"`
+}
+
+// LoadLastPlayback returns the recorded playback, or nil if there is none.
+func LoadLastPlayback() (*LastPlayback, error) {
+	path, err := appFilePath(lastPlaybackFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p LastPlayback
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("could not unmarshal last playback: %w", err)
+	}
+	return &p, nil
+}
+
+// SaveLastPlayback records p.
+func SaveLastPlayback(p LastPlayback) error {
+	path, err := appFilePath(lastPlaybackFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("could not marshal last playback: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
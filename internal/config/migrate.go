@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigVersion is the config_version of the layout this build reads. A
+// config.toml without the key predates versioning and counts as 0.
+const ConfigVersion = 1
+
+// migrations[i] upgrades a config from version i to i+1. They edit the
+// files as text so comments and formatting survive.
+var migrations = []func(configPath string) error{
+	mergeCredentials,
+}
+
+var versionLine = regexp.MustCompile(`(?m)^config_version\s*=.*$`)
+
+// Migrate brings the config files up to ConfigVersion, keeping a copy of
+// each file it changes as <name>.v<old version>.bak. It returns the backups
+// made, none when the config was already current.
+func Migrate() (backups []string, err error) {
+	path := configFilePath()
+	var cfg struct {
+		Version int `toml:"config_version"`
+	}
+	if _, err := toml.DecodeFile(path, &cfg); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if cfg.Version > ConfigVersion {
+		return nil, fmt.Errorf("%s has config_version %d, but this Spotirice only knows up to %d; please upgrade", path, cfg.Version, ConfigVersion)
+	}
+	if cfg.Version == ConfigVersion {
+		return nil, nil
+	}
+
+	files := []string{path}
+	if configFileOverride == "" {
+		files = append(files, credentialsPath())
+	}
+	for _, p := range files {
+		backup, err := backupFile(p, cfg.Version)
+		if err != nil {
+			return backups, err
+		}
+		if backup != "" {
+			backups = append(backups, backup)
+		}
+	}
+	if len(backups) == 0 {
+		// Nothing to upgrade yet: a new config.toml starts out current
+		return nil, nil
+	}
+
+	for v := cfg.Version; v < ConfigVersion; v++ {
+		if err := migrations[v](path); err != nil {
+			return backups, fmt.Errorf("migrating config to version %d: %w", v+1, err)
+		}
+	}
+	return backups, setConfigVersion(path)
+}
+
+// backupFile copies path next to itself, unless it doesn't exist. An
+// existing backup from an earlier, interrupted run is kept.
+func backupFile(path string, version int) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return backup, nil
+	}
+	return backup, os.WriteFile(backup, data, 0600)
+}
+
+// setConfigVersion records ConfigVersion in config.toml, on top so it stays
+// outside any table.
+func setConfigVersion(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	line := fmt.Sprintf("config_version = %d", ConfigVersion)
+	if versionLine.Match(data) {
+		data = versionLine.ReplaceAll(data, []byte(line))
+	} else {
+		data = append([]byte(line+"\n\n"), data...)
+	}
+	return writeConfig(path, data)
+}
+
+// mergeCredentials (version 0 to 1) moves credentials.json into a
+// [credentials] table of config.toml, so all settings live in one file. With
+// --config the file is left alone: the override may be one of several.
+func mergeCredentials(path string) error {
+	if configFileOverride != "" {
+		return nil
+	}
+	creds, err := readCredentialsJSON()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var existing struct {
+		Credentials *Credentials `toml:"credentials"`
+	}
+	if _, err := toml.Decode(string(data), &existing); err != nil {
+		return err
+	}
+	if existing.Credentials == nil {
+		var table bytes.Buffer
+		enc := toml.NewEncoder(&table)
+		enc.Indent = ""
+		if err := enc.Encode(struct {
+			Credentials *Credentials `toml:"credentials"`
+		}{creds}); err != nil {
+			return err
+		}
+		if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+			data = append(data, '\n')
+		}
+		data = append(data, "\n# Moved here from credentials.json\n"...)
+		data = append(data, table.Bytes()...)
+		if err := writeConfig(path, data); err != nil {
+			return err
+		}
+	}
+	// The backup keeps a copy
+	return os.Remove(credentialsPath())
+}
+
+// writeConfig replaces path with data, private since it may now hold the
+// client secret.
+func writeConfig(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestMigrate(t *testing.T) {
+	const credsJSON = `{"client_id": "json-id", "client_secret": "json-secret"}`
+
+	tests := []struct {
+		name    string
+		config  string // config.toml before; "" for none
+		creds   string // credentials.json before; "" for none
+		backups []string
+		// config.toml after: its credentials, and lines it must keep
+		wantCreds *Credentials
+		keep      []string
+		wantErr   string
+	}{
+		{
+			name: "nothing yet",
+		},
+		{
+			name:      "settings and credentials.json",
+			config:    "# my settings\ntheme = \"nord\"\n",
+			creds:     credsJSON,
+			backups:   []string{"config.toml.v0.bak", "credentials.json.v0.bak"},
+			wantCreds: &Credentials{"json-id", "json-secret"},
+			keep:      []string{"config_version = 1", "# my settings", `theme = "nord"`},
+		},
+		{
+			name:      "only credentials.json",
+			creds:     credsJSON,
+			backups:   []string{"credentials.json.v0.bak"},
+			wantCreds: &Credentials{"json-id", "json-secret"},
+			keep:      []string{"config_version = 1"},
+		},
+		{
+			name:      "credentials in both",
+			config:    "[credentials]\nclient_id = \"toml-id\"\n",
+			creds:     credsJSON,
+			backups:   []string{"config.toml.v0.bak", "credentials.json.v0.bak"},
+			wantCreds: &Credentials{ClientID: "toml-id"},
+		},
+		{
+			name:    "no credentials.json",
+			config:  "theme = \"nord\"\n",
+			backups: []string{"config.toml.v0.bak"},
+			keep:    []string{"config_version = 1", `theme = "nord"`},
+		},
+		{
+			name:   "current",
+			config: "config_version = 1\n",
+			creds:  credsJSON, // read as a fallback; left alone
+			keep:   []string{"config_version = 1"},
+		},
+		{
+			name:    "newer",
+			config:  "config_version = 2\n",
+			wantErr: "only knows up to 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("SPOTIRICE_CONFIG_DIR", dir)
+			configPath := filepath.Join(dir, "config.toml")
+			credsPath := filepath.Join(dir, "credentials.json")
+			write(t, configPath, tt.config)
+			write(t, credsPath, tt.creds)
+
+			backups, err := Migrate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Migrate error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, b := range backups {
+				names = append(names, filepath.Base(b))
+			}
+			if !slices.Equal(names, tt.backups) {
+				t.Errorf("backups = %v, want %v", names, tt.backups)
+			}
+			// Each backup is the file as it was
+			for _, b := range backups {
+				orig := map[string]string{"config.toml": tt.config, "credentials.json": tt.creds}
+				want := orig[strings.TrimSuffix(filepath.Base(b), ".v0.bak")]
+				if got := read(t, b); got != want {
+					t.Errorf("%s = %q, want %q", filepath.Base(b), got, want)
+				}
+			}
+
+			// Merged into config.toml, it is gone
+			moved := slices.Contains(names, "credentials.json.v0.bak")
+			if _, err := os.Stat(credsPath); tt.creds != "" && (err == nil) == moved {
+				t.Errorf("credentials.json kept: %v, want %v", err == nil, !moved)
+			}
+			if tt.config == "" && len(backups) == 0 {
+				if _, err := os.Stat(configPath); err == nil {
+					t.Error("config.toml created with nothing to migrate")
+				}
+				return
+			}
+
+			data := read(t, configPath)
+			var cfg struct {
+				Version     int          `toml:"config_version"`
+				Credentials *Credentials `toml:"credentials"`
+			}
+			if _, err := toml.Decode(data, &cfg); err != nil {
+				t.Fatalf("config.toml after: %v\n%s", err, data)
+			}
+			if cfg.Version != ConfigVersion {
+				t.Errorf("config_version = %d", cfg.Version)
+			}
+			if (cfg.Credentials == nil) != (tt.wantCreds == nil) ||
+				(cfg.Credentials != nil && *cfg.Credentials != *tt.wantCreds) {
+				t.Errorf("credentials = %+v, want %+v", cfg.Credentials, tt.wantCreds)
+			}
+			for _, line := range tt.keep {
+				if !strings.Contains(data, line) {
+					t.Errorf("config.toml lacks %q:\n%s", line, data)
+				}
+			}
+		})
+	}
+}
+
+// TestMigrateKeepsBackup runs a migration again after one was interrupted
+// once the backups were made: the backups still hold the original files.
+func TestMigrateKeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SPOTIRICE_CONFIG_DIR", dir)
+	configPath := filepath.Join(dir, "config.toml")
+	write(t, configPath+".v0.bak", "theme = \"original\"\n")
+	write(t, configPath, "theme = \"edited\"\n")
+
+	if _, err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, configPath+".v0.bak"); got != "theme = \"original\"\n" {
+		t.Errorf("backup = %q, overwritten", got)
+	}
+}
+
+func write(t *testing.T, path, data string) {
+	t.Helper()
+	if data == "" {
+		return
+	}
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// configFileOverride is the config.toml given with --config, if any.
+var configFileOverride string
+
+// portableDir keeps every file under one directory in portable mode.
+var portableDir string
+
+// PortableMarker is the file whose presence next to the binary turns on
+// portable mode.
+const PortableMarker = "portable"
+
+// SetPortable stores config, state and cache under dir instead of the home
+// directory. The SPOTIRICE_*_DIR variables still take precedence.
+func SetPortable(dir string) {
+	portableDir = dir
+}
+
+// SetConfigFile makes config.toml be read from path instead of the config
+// directory.
+func SetConfigFile(path string) {
+	configFileOverride = path
+}
+
+// ConfigDir holds config.toml and credentials.json. It can be moved with
+// SPOTIRICE_CONFIG_DIR.
+func ConfigDir() string {
+	return profileDir(baseConfigDir())
+}
+
+// baseConfigDir is the config directory of the default profile.
+func baseConfigDir() string {
+	if dir := os.Getenv("SPOTIRICE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if portableDir != "" {
+		return filepath.Join(portableDir, "config")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config", "spotirice")
+}
+
+// StateDir holds the token and other files Spotirice writes itself. It can be
+// moved with SPOTIRICE_STATE_DIR.
+func StateDir() (string, error) {
+	if dir := os.Getenv("SPOTIRICE_STATE_DIR"); dir != "" {
+		return profileDir(dir), nil
+	}
+	if portableDir != "" {
+		return profileDir(filepath.Join(portableDir, "state")), nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get config dir: %w", err)
+	}
+	return profileDir(filepath.Join(configDir, "spotirice")), nil
+}
+
+// CacheDir holds data that can be fetched again, such as images. It can be
+// moved with SPOTIRICE_CACHE_DIR.
+func CacheDir() (string, error) {
+	if dir := os.Getenv("SPOTIRICE_CACHE_DIR"); dir != "" {
+		return profileDir(dir), nil
+	}
+	if portableDir != "" {
+		return profileDir(filepath.Join(portableDir, "cache")), nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get cache dir: %w", err)
+	}
+	return profileDir(filepath.Join(cacheDir, "spotirice")), nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+const playedEpisodesFileName = "played_episodes.json"
+
+// LoadPlayedEpisodes returns the set of episode IDs the user marked as played.
+// The Web API offers no way to write an episode's resume point, so these
+// markers are kept locally and merged with Spotify's own fully_played flag.
+func LoadPlayedEpisodes() (map[string]bool, error) {
+	path, err := appFilePath(playedEpisodesFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	played := make(map[string]bool)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return played, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("could not unmarshal played episodes: %w", err)
+	}
+	for _, id := range ids {
+		played[id] = true
+	}
+	return played, nil
+}
+
+// SavePlayedEpisodes persists the set of locally played episode IDs.
+func SavePlayedEpisodes(played map[string]bool) error {
+	path, err := appFilePath(playedEpisodesFileName)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(played))
+	for id, ok := range played {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("could not marshal played episodes: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// profile is the account profile in use, set with --profile. The default
+// profile, "", keeps its files where they always were; any other gets its
+// own profiles/<name> under the config, state and cache directories.
+var profile string
+
+// profileName is what profile names may look like: they end up in paths,
+// socket and unit names.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// SetProfile switches to the profile called name; "" is the default one.
+func SetProfile(name string) error {
+	if name != "" && !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, - and _", name)
+	}
+	profile = name
+	return nil
+}
+
+// Profile returns the name of the profile in use, "" for the default one.
+func Profile() string {
+	return profile
+}
+
+// profileDir returns where the current profile keeps the files that dir
+// holds for the default profile.
+func profileDir(dir string) string {
+	if profile == "" {
+		return dir
+	}
+	return filepath.Join(dir, "profiles", profile)
+}
+
+// Profiles lists the profiles that have a directory under the config
+// directory, after the default profile "".
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(baseConfigDir(), "profiles"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	names := []string{""}
+	for _, e := range entries {
+		if e.IsDir() && profileName.MatchString(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names[1:])
+	if profile != "" && !slices.Contains(names, profile) {
+		// Nothing saved for it yet, but it's the one in use
+		names = append(names, profile)
+	}
+	return names, nil
+}
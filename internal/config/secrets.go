@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name secrets are filed under in the keyring.
+const keyringService = "spotirice"
+
+// keyringUser is the keyring entry holding the named secret of the current
+// profile; the default profile's entries are plain names.
+func keyringUser(name string) string {
+	if profile == "" {
+		return name
+	}
+	return profile + "/" + name
+}
+
+// Keyring entries.
+const (
+	keyringToken        = "token"
+	keyringClientSecret = "client_secret"
+)
+
+// useKeyring reports whether config.toml sets secret_store = "keyring". It
+// is read on its own, like the credentials, since the token is needed
+// before and apart from the rest of the settings.
+func useKeyring() (bool, error) {
+	var cfg struct {
+		SecretStore string `toml:"secret_store"`
+	}
+	if _, err := toml.DecodeFile(configFilePath(), &cfg); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	switch cfg.SecretStore {
+	case "", "file":
+		return false, nil
+	case "keyring":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown secret_store %q (want file or keyring)", cfg.SecretStore)
+}
+
+// keyringGet reads a secret. ok is false when the keyring isn't in use or
+// doesn't hold it; an unreachable keyring is logged and counts as empty,
+// so the files are used instead.
+func keyringGet(name string) (secret string, ok bool) {
+	if on, err := useKeyring(); err != nil || !on {
+		return "", false
+	}
+	secret, err := keyring.Get(keyringService, keyringUser(name))
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			log.Printf("Could not read %s from the keyring: %v", name, err)
+		}
+		return "", false
+	}
+	return secret, true
+}
+
+// keyringSet stores a secret. ok is false when the keyring isn't in use or
+// couldn't take it, and the caller should fall back to its file.
+func keyringSet(name, secret string) (ok bool) {
+	if on, err := useKeyring(); err != nil || !on {
+		return false
+	}
+	if err := keyring.Set(keyringService, keyringUser(name), secret); err != nil {
+		log.Printf("Could not save %s to the keyring, using a file instead: %v", name, err)
+		return false
+	}
+	return true
+}
+
+// SaveClientSecret stores the client secret in the keyring, for when
+// secret_store = "keyring" and client_secret is left out of config.toml.
+func SaveClientSecret(secret string) error {
+	on, err := useKeyring()
+	if err != nil {
+		return err
+	}
+	if !on {
+		return errors.New(`secret_store = "keyring" is not set in config.toml`)
+	}
+	return keyring.Set(keyringService, keyringUser(keyringClientSecret), secret)
+}
+
+// moveToKeyring puts the contents of a secret file into the keyring and
+// removes the file, once the keyring has it.
+func moveToKeyring(name, path string, data []byte) {
+	if !keyringSet(name, string(data)) {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Could not remove %s after moving it to the keyring: %v", path, err)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const sessionFileName = "session.json"
+
+// Session is the UI state saved on exit and restored at the next start.
+type Session struct {
+	// View is the screen that was open: "main", "tracklist", "episodes" or
+	// "devices".
+	View           string `json:"view"`
+	ContextURI     string `json:"context_uri,omitempty"`
+	TrackCursor    int    `json:"track_cursor,omitempty"`
+	EpisodesCursor int    `json:"episodes_cursor,omitempty"`
+	// Volume is only restored when playback is still on DeviceID.
+	Volume   int    `json:"volume"`
+	DeviceID string `json:"device_id,omitempty"`
+	// ShowRemaining is the timer mode; unlike the rest it always applies.
+	ShowRemaining bool `json:"show_remaining,omitempty"`
+	// SleepAt is when a running sleep timer goes off, and SleepFor the step
+	// it was set to; one that ran out while closed is dropped.
+	SleepAt  time.Time     `json:"sleep_at,omitzero"`
+	SleepFor time.Duration `json:"sleep_for,omitempty"`
+}
+
+// LoadSession returns the saved session, or nil if there is none.
+func LoadSession() (*Session, error) {
+	path, err := appFilePath(sessionFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("could not unmarshal session: %w", err)
+	}
+	return &s, nil
+}
+
+// SaveSession persists s for the next start.
+func SaveSession(s Session) error {
+	path, err := appFilePath(sessionFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("could not marshal session: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
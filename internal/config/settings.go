@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Settings holds the non-color options read from config.toml.
+type Settings struct {
+	// BeatSync fetches the track's audio analysis and draws bar ticks on the progress bar.
+	BeatSync bool `toml:"beat_sync"`
+	// PreferredDevice is a device name or ID to play on when available.
+	PreferredDevice string `toml:"preferred_device"`
+	// HeadlessPlayer is a spotifyd/librespot command line started when no
+	// Spotify client is installed, e.g. "spotifyd --no-daemon".
+	HeadlessPlayer string `toml:"headless_player"`
+	// QuitSpotifyOnExit closes a Spotify client that Spotirice launched when
+	// exiting with playback stopped.
+	QuitSpotifyOnExit bool `toml:"quit_spotify_on_exit"`
+	// TransferPlay keeps music playing when switching devices in the device
+	// picker instead of leaving playback paused on the new device.
+	TransferPlay bool `toml:"transfer_play"`
+	// LaunchMinimized starts the Spotify client hidden/minimized.
+	LaunchMinimized bool `toml:"launch_minimized"`
+	// TerminalTitle shows the playing track in the terminal window title.
+	TerminalTitle bool `toml:"terminal_title"`
+	// Notify shows a desktop notification when the track changes.
+	Notify bool `toml:"notify"`
+	// DeviceIcons overrides the icon shown per device type ("Computer",
+	// "Smartphone", "Speaker", ...).
+	DeviceIcons map[string]string `toml:"device_icons"`
+	// ExplicitFilter is "hide" to leave explicit tracks out of lists and skip
+	// them when they play, "warn" to ask before playing one, or empty to
+	// treat them like any other.
+	ExplicitFilter string `toml:"explicit_filter"`
+	// ColorMode overrides the detected color support: "truecolor", "256",
+	// "16" or "none". Empty or "auto" keeps detection.
+	ColorMode string `toml:"color_mode"`
+	// ReducedMotion turns off progress smoothing, beat pulses, blinking and
+	// the fast redraws after a key press.
+	ReducedMotion bool `toml:"reduced_motion"`
+	// ScreenReader renders plain labelled lines, without box drawing or
+	// icons, and prints state changes as they happen. Implies ReducedMotion.
+	ScreenReader bool `toml:"screen_reader"`
+	// GlobalHotkeys makes the daemon register system-wide media keys, or
+	// serve MPRIS for the desktop's media keys outside Windows.
+	GlobalHotkeys bool `toml:"global_hotkeys"`
+	// ScreensaverMinutes of no input switch to the full-screen clock view;
+	// 0 disables it.
+	ScreensaverMinutes int `toml:"screensaver_minutes"`
+	// Visualizer is the source of the visualizer bars: "beats" (default)
+	// synthesizes them from the track's audio analysis, "cava" runs cava on
+	// the local audio output.
+	Visualizer string `toml:"visualizer"`
+	// Transitions animates track changes briefly (off with ReducedMotion).
+	Transitions bool `toml:"transitions"`
+	// EnhancedKeyboard turns on the kitty keyboard protocol in terminals
+	// that support it, so Shift+Space and friends can be told apart.
+	EnhancedKeyboard bool `toml:"enhanced_keyboard"`
+	// ControlFIFO creates a named pipe ("ctl" in the cache directory) that
+	// takes playback commands, one per line.
+	ControlFIFO bool `toml:"control_fifo"`
+	// MPRIS makes the TUI an MPRIS2 player on the D-Bus session bus, for
+	// playerctl and desktop media controls (on by default).
+	MPRIS bool `toml:"mpris"`
+	// SecretStore is where the token and client secret are kept: "file"
+	// (token.json and config.toml, the default) or "keyring" for the system
+	// keyring, falling back to the file when the keyring can't be reached.
+	SecretStore string `toml:"secret_store"`
+	// MetricsAddr, if set, makes the daemon serve Prometheus metrics on
+	// this address under /metrics, e.g. "127.0.0.1:9464".
+	MetricsAddr string `toml:"metrics_addr"`
+	// Webhooks are posted to by the daemon when playback changes.
+	Webhooks []Webhook `toml:"webhooks"`
+	// Scripts are run by the daemon when playback changes.
+	Scripts []Script `toml:"scripts"`
+	// ScriptKeys binds keys in the player to script command lines; a
+	// binding takes precedence over the built-in key.
+	ScriptKeys map[string]string `toml:"script_keys"`
+	// Keymap is a preset of keybindings: "default", or "vim" for hjkl,
+	// gg/G in lists and :q. Keybindings apply on top of it.
+	Keymap string `toml:"keymap"`
+	// Keybindings maps player actions ("play_pause", "next", ...) to the
+	// keys that trigger them, replacing that action's built-in keys.
+	Keybindings map[string]KeyList `toml:"keybindings"`
+	// Panels are extra screens filled in by external programs.
+	Panels []Panel `toml:"panels"`
+	// ResumeLastSession restarts the last played context at the saved
+	// position when Spotirice starts with nothing playing.
+	ResumeLastSession bool `toml:"resume_last_session"`
+	// PauseOn lists the system events that pause playback: "suspend" and
+	// "lock" (Linux only).
+	PauseOn []string `toml:"pause_on"`
+	// ResumeOn lists the events after which playback paused by PauseOn
+	// starts again: "wake" and "unlock".
+	ResumeOn []string `toml:"resume_on"`
+	// FadeMs fades the volume out before pausing and in after resuming
+	// (and when the sleep timer runs out) over this many milliseconds.
+	FadeMs int `toml:"fade_ms"`
+	// Focus configures focus mode (the F key).
+	Focus Focus `toml:"focus"`
+	// Party configures the daemon's guest request page.
+	Party Party `toml:"party"`
+	// RecordHistory keeps a local log of played tracks (history.jsonl in
+	// the state directory) for `spotirice wrapped`.
+	RecordHistory bool `toml:"record_history"`
+	// AlbumArt shows the cover of the playing album or show under the
+	// player: "auto" picks the terminal's image protocol, "kitty", "iterm2",
+	// "sixel" or "blocks" force one; empty or "off" leaves it out.
+	AlbumArt string `toml:"album_art"`
+	// UpdateCheck looks for a newer release on GitHub at startup and shows
+	// it in the header.
+	UpdateCheck bool `toml:"update_check"`
+	// UpdateCheckHours is how long a check is trusted before asking GitHub
+	// again.
+	UpdateCheckHours int `toml:"update_check_hours"`
+}
+
+// KeyList is one or more key names, written as a string or an array.
+type KeyList []string
+
+// UnmarshalTOML accepts `next = "j"` as well as `next = ["j", "n"]`.
+func (k *KeyList) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*k = KeyList{v}
+	case []any:
+		*k = nil
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("keybindings: %v is not a key name", item)
+			}
+			*k = append(*k, s)
+		}
+	default:
+		return fmt.Errorf("keybindings: %v is not a key name or list of them", v)
+	}
+	return nil
+}
+
+// Panel is one [[panels]] table: a screen opened with Key that shows what
+// Run prints, given the playback state like a script.
+type Panel struct {
+	Name string `toml:"name"`
+	Key  string `toml:"key"`
+	Run  string `toml:"run"`
+	// Refresh reruns the program this many seconds apart while the panel is
+	// open; with 0 it only reruns when the track changes.
+	Refresh int `toml:"refresh"`
+}
+
+// Focus is the [focus] table: focus periods on one playlist, with breaks
+// silent or on another.
+type Focus struct {
+	// Playlist is a spotify: URI; empty keeps whatever is playing.
+	Playlist string `toml:"playlist"`
+	Minutes  int    `toml:"minutes"`
+	// BreakPlaylist plays during breaks; empty pauses instead.
+	BreakPlaylist string `toml:"break_playlist"`
+	BreakMinutes  int    `toml:"break_minutes"`
+}
+
+// Party is the [party] table.
+type Party struct {
+	// Addr is where the request page listens, e.g. "0.0.0.0:8090" to
+	// reach it from the LAN; empty turns party mode off.
+	Addr string `toml:"addr"`
+	// PIN, if set, must be entered on the page before requesting.
+	PIN string `toml:"pin"`
+	// AutoAccept queues requests straight away instead of holding them
+	// for the player to accept.
+	AutoAccept bool `toml:"auto_accept"`
+}
+
+// Script is one [[scripts]] table.
+type Script struct {
+	// Run is the command line, without shell quoting, or the path of a
+	// .lua script for the embedded interpreter.
+	Run string `toml:"run"`
+	// Events limits the script to these event types; empty means all of them.
+	Events []string `toml:"events"`
+}
+
+// Webhook is one [[webhooks]] table.
+type Webhook struct {
+	URL string `toml:"url"`
+	// Events limits the hook to these event types; empty means all of them.
+	Events []string `toml:"events"`
+	// Template is a Go template over the event that renders the request
+	// body; empty sends the event as JSON.
+	Template string `toml:"template"`
+	// ContentType defaults to application/json.
+	ContentType string `toml:"content_type"`
+	// Retries is how often a failed delivery is repeated, waiting a second
+	// before the first retry and twice as long before each one after.
+	Retries int `toml:"retries"`
+}
+
+// DefaultSettings provides the settings used when config.toml omits a key.
+func DefaultSettings() *Settings {
+	return &Settings{EnhancedKeyboard: true, UpdateCheckHours: 24, MPRIS: true}
+}
+
+// configFilePath returns the location of config.toml.
+func configFilePath() string {
+	if configFileOverride != "" {
+		return configFileOverride
+	}
+	return filepath.Join(ConfigDir(), "config.toml")
+}
+
+// LoadSettings reads the config.toml file and returns a Settings struct.
+func LoadSettings() (*Settings, error) {
+	settings := DefaultSettings()
+
+	path := configFilePath()
+	if _, err := os.Stat(path); err == nil {
+		if _, err := toml.DecodeFile(path, settings); err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// themes are the built-in palettes selectable with `theme = "<name>"`.
+// Colors set in config.toml still override single entries.
+var themes = map[string]Colors{
+	"gruvbox": {
+		Header:       "#fabd2f",
+		TrackPlaying: "#b8bb26",
+		TrackPaused:  "#fe8019",
+		Artist:       "#ebdbb2",
+		ProgressBar:  "#83a598",
+		Status:       "#928374",
+		Error:        "#fb4934",
+	},
+	"nord": {
+		Header:       "#88c0d0",
+		TrackPlaying: "#a3be8c",
+		TrackPaused:  "#ebcb8b",
+		Artist:       "#eceff4",
+		ProgressBar:  "#81a1c1",
+		Status:       "#4c566a",
+		Error:        "#bf616a",
+	},
+	"catppuccin-mocha": {
+		Header:       "#cba6f7",
+		TrackPlaying: "#a6e3a1",
+		TrackPaused:  "#f9e2af",
+		Artist:       "#cdd6f4",
+		ProgressBar:  "#89b4fa",
+		Status:       "#6c7086",
+		Error:        "#f38ba8",
+	},
+	"dracula": {
+		Header:       "#bd93f9",
+		TrackPlaying: "#50fa7b",
+		TrackPaused:  "#f1fa8c",
+		Artist:       "#f8f8f2",
+		ProgressBar:  "#ff79c6",
+		Status:       "#6272a4",
+		Error:        "#ff5555",
+	},
+	"tokyonight": {
+		Header:       "#7aa2f7",
+		TrackPlaying: "#9ece6a",
+		TrackPaused:  "#e0af68",
+		Artist:       "#c0caf5",
+		ProgressBar:  "#bb9af7",
+		Status:       "#565f89",
+		Error:        "#f7768e",
+	},
+	"solarized": {
+		Header:       "#268bd2",
+		TrackPlaying: "#859900",
+		TrackPaused:  "#b58900",
+		Artist:       "#93a1a1",
+		ProgressBar:  "#2aa198",
+		Status:       "#586e75",
+		Error:        "#dc322f",
+	},
+}
+
+// themeColors returns the palette for name, on top of the defaults.
+func themeColors(name string) (*Colors, error) {
+	colors := DefaultColors()
+	if name == "" || name == "default" {
+		return colors, nil
+	}
+	t, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	t.EndingSeconds = colors.EndingSeconds
+	return &t, nil
+}
+
+// ThemeNames lists the built-in themes in alphabetical order.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Palette lists the distinct colors of the built-in themes, theme by theme,
+// as ready-made choices for the theme editor.
+func Palette() []string {
+	var colors []string
+	seen := make(map[string]bool)
+	for _, name := range ThemeNames() {
+		t := themes[name]
+		for _, key := range ColorKeys {
+			c := strings.ToLower(*t.Color(key))
+			if c != "" && !seen[c] {
+				seen[c] = true
+				colors = append(colors, c)
+			}
+		}
+	}
+	return colors
+}
@@ -11,35 +11,63 @@ import (
 
 const tokenFileName = "token.json"
 
+// storedToken is how a token is saved: with the scope it was granted, which
+// oauth2.Token only holds until it is marshalled.
+type storedToken struct {
+	*oauth2.Token
+	Scope string `json:"scope,omitempty"`
+}
+
 func tokenFilePath() (string, error) {
-	configDir, err := os.UserConfigDir()
+	return appFilePath(tokenFileName)
+}
+
+// appFilePath returns the path of a file in the state dir, creating the
+// directory if needed.
+func appFilePath(name string) (string, error) {
+	spotiriceDir, err := StateDir()
 	if err != nil {
-		return "", fmt.Errorf("could not get config dir: %w", err)
+		return "", err
 	}
 
-	spotiriceDir := filepath.Join(configDir, "spotirice")
 	if err := os.MkdirAll(spotiriceDir, 0700); err != nil {
 		return "", fmt.Errorf("could not create config dir: %w", err)
 	}
 
-	return filepath.Join(spotiriceDir, tokenFileName), nil
+	return filepath.Join(spotiriceDir, name), nil
 }
 
+// SaveToken stores tok in the keyring with secret_store = "keyring", and in
+// token.json otherwise or when the keyring can't be reached.
 func SaveToken(tok *oauth2.Token) error {
 	path, err := tokenFilePath()
 	if err != nil {
 		return err
 	}
 
-	data, err := json.Marshal(tok)
+	scope, _ := tok.Extra("scope").(string)
+	data, err := json.Marshal(storedToken{Token: tok, Scope: scope})
 	if err != nil {
 		return fmt.Errorf("could not marshal token: %w", err)
 	}
 
+	if keyringSet(keyringToken, string(data)) {
+		// A token.json left from before would be stale from now on
+		_ = os.Remove(path)
+		return nil
+	}
 	return os.WriteFile(path, data, 0600)
 }
 
+// LoadToken reads the token from the keyring or token.json. With the
+// keyring in use, a token.json found is moved into it. The scope it was
+// granted is in its "scope" extra; tokens saved before that was kept have
+// none.
 func LoadToken() (*oauth2.Token, error) {
+	if secret, ok := keyringGet(keyringToken); ok {
+		return unmarshalToken([]byte(secret))
+	}
+
 	path, err := tokenFilePath()
 	if err != nil {
 		return nil, err
@@ -50,15 +78,33 @@ func LoadToken() (*oauth2.Token, error) {
 		return nil, err
 	}
 
-	var tok oauth2.Token
-	if err := json.Unmarshal(data, &tok); err != nil {
-		return nil, fmt.Errorf("could not unmarshal token: %w", err)
+	tok, err := unmarshalToken(data)
+	if err != nil {
+		return nil, err
 	}
+	moveToKeyring(keyringToken, path, data)
+	return tok, nil
+}
 
-	return &tok, nil
+func unmarshalToken(data []byte) (*oauth2.Token, error) {
+	var st storedToken
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("could not unmarshal token: %w", err)
+	}
+	if st.Token == nil {
+		return nil, fmt.Errorf("could not unmarshal token: no token in it")
+	}
+	if st.Scope == "" {
+		return st.Token, nil
+	}
+	return st.Token.WithExtra(map[string]any{"scope": st.Scope}), nil
 }
 
 func TokenExists() bool {
+	if _, ok := keyringGet(keyringToken); ok {
+		return true
+	}
+
 	path, err := tokenFilePath()
 	if err != nil {
 		return false
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+const tourFileName = "tour_done"
+
+// TourDone reports whether the onboarding tour has been finished or
+// skipped before.
+func TourDone() bool {
+	path, err := appFilePath(tourFileName)
+	if err != nil {
+		// Without a state dir the tour couldn't be remembered either
+		return true
+	}
+	_, err = os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// SaveTourDone records that the onboarding tour shouldn't be shown again.
+func SaveTourDone() error {
+	path, err := appFilePath(tourFileName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, nil, 0600)
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const updateCheckFileName = "update_check.json"
+
+// UpdateCheck is the outcome of the last look for a new release, so the
+// next start within update_check_hours can skip asking again.
+type UpdateCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Version   string    `json:"version"`
+	Notes     string    `json:"notes,omitempty"`
+	URL       string    `json:"url,omitempty"`
+}
+
+// LoadUpdateCheck returns the last check, or nil if there was none.
+func LoadUpdateCheck() (*UpdateCheck, error) {
+	path, err := appFilePath(updateCheckFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c UpdateCheck
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("could not unmarshal update check: %w", err)
+	}
+	return &c, nil
+}
+
+// SaveUpdateCheck records c.
+func SaveUpdateCheck(c UpdateCheck) error {
+	path, err := appFilePath(updateCheckFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("could not marshal update check: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
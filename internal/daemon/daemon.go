@@ -0,0 +1,614 @@
+// Package daemon keeps an authenticated Spotify client and its polling loop
+// running in the background, controlled over a Unix socket.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/metrics"
+	"github.com/metolius25/spotirice/internal/scripts"
+)
+
+// pollInterval is how often the daemon refreshes its cached player state.
+const pollInterval = 5 * time.Second
+
+// Request is one command sent to the daemon, encoded as a JSON line.
+type Request struct {
+	Cmd  string   `json:"cmd"`
+	Args []string `json:"args,omitempty"`
+}
+
+// Response answers a Request.
+type Response struct {
+	OK     bool         `json:"ok"`
+	Error  string       `json:"error,omitempty"`
+	Status *Status      `json:"status,omitempty"`
+	Party  *PartyState  `json:"party,omitempty"`
+	API    *APIResponse `json:"api,omitempty"`
+	Player *Player      `json:"player,omitempty"`
+}
+
+// Player is the GET /me/player reply a Status was made from, as Spotify
+// sent it, for front ends that show more than Status has (see Subscribe).
+type Player struct {
+	State     json.RawMessage `json:"state,omitempty"` // empty when nothing is playing
+	LatencyMs int             `json:"latency_ms"`
+}
+
+// Status is the player state the daemon last saw.
+type Status struct {
+	ID         string `json:"id"`
+	Track      string `json:"track"`
+	Artist     string `json:"artist"`
+	ArtistID   string `json:"artist_id"`
+	Album      string `json:"album"`
+	AlbumID    string `json:"album_id"`
+	Liked      bool   `json:"liked"`
+	Playing    bool   `json:"playing"`
+	ProgressMs int    `json:"progress_ms"`
+	DurationMs int    `json:"duration_ms"`
+	Device     string `json:"device"`
+	Volume     int    `json:"volume"`
+	Shuffle    bool   `json:"shuffle"`
+	Repeat     string `json:"repeat"` // "off", "track" or "context"
+	ArtURL     string `json:"art_url"`
+}
+
+// SocketPath is where the daemon listens; each profile has a daemon of its
+// own.
+func SocketPath() string {
+	name := "spotirice"
+	if p := config.Profile(); p != "" {
+		name += "-" + p
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d.sock", name, os.Getuid()))
+	}
+	return filepath.Join(dir, name+".sock")
+}
+
+// Running reports whether a daemon is accepting connections.
+func Running() bool {
+	conn, err := net.DialTimeout("unix", SocketPath(), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Send delivers req to the running daemon and waits for its response.
+func Send(req Request) (Response, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(), time.Second)
+	if err != nil {
+		return Response{}, fmt.Errorf("daemon not running: %w", err)
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return Response{}, err
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("could not read daemon response: %w", err)
+	}
+	return resp, nil
+}
+
+// Controller runs commands against the player and remembers the state it
+// last saw. The daemon serves one over its socket; other front ends (the
+// JSON-RPC mode) use one directly, or one from Attach while a daemon runs.
+type Controller struct {
+	client *spotify.Client
+	http   *http.Client // for "api" requests
+	remote bool         // forwards to the daemon; see Attach
+	// OnChange, if set, is called after a refresh that changed anything but
+	// the playback position. Calls never overlap, and come in the order
+	// the states were stored.
+	OnChange func(Status)
+
+	mu      sync.Mutex
+	status  Status
+	player  Player
+	trackID spotify.ID
+	liked   map[spotify.ID]bool // like status per track, looked up once
+	subs    map[chan Response]bool
+
+	// changeMu is held from storing a state until OnChange returns, as
+	// refreshes run concurrently: polling, commands, remote changes
+	changeMu sync.Mutex
+	reported bool   // OnChange has seen a state
+	notified Status // the state OnChange last saw
+
+	party   *party    // nil unless party mode is on
+	scripts []*script // set before serving, for "run"
+}
+
+// NewController wraps an authenticated client.
+func NewController(c *spotify.Client) *Controller {
+	return &Controller{client: c, http: http.DefaultClient, liked: make(map[spotify.ID]bool)}
+}
+
+// Client returns the Spotify client the Controller runs commands with.
+func (s *Controller) Client() *spotify.Client {
+	return s.client
+}
+
+// Options configures Serve.
+type Options struct {
+	// GlobalHotkeys registers system-wide media keys: directly on Windows,
+	// through MPRIS elsewhere.
+	GlobalHotkeys bool
+	// FIFO, if set, is the path of a named pipe taking commands as lines.
+	FIFO string
+	// Metrics, if set, is served in the Prometheus format on MetricsAddr.
+	Metrics     *metrics.Registry
+	MetricsAddr string
+	// Webhooks are posted to on playback events.
+	Webhooks []config.Webhook
+	// Scripts are run on playback events; the commands they print are
+	// carried out like socket requests.
+	Scripts []config.Script
+	// PauseOn and ResumeOn are the pause_on and resume_on settings.
+	PauseOn  []string
+	ResumeOn []string
+	// Party serves the guest request page when its Addr is set.
+	Party config.Party
+	// History records each new track with config.AppendHistory.
+	History bool
+}
+
+// FIFOPath is where the control pipe goes: ctl in the cache directory.
+func FIFOPath() (string, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ctl"), nil
+}
+
+// Serve polls the player and answers requests on SocketPath until ctx is
+// cancelled.
+func Serve(ctx context.Context, c *spotify.Client, opts Options) error {
+	hooks, err := newWebhooks(opts.Webhooks)
+	if err != nil {
+		return err
+	}
+	userScripts, err := newScripts(opts.Scripts)
+	if err != nil {
+		return err
+	}
+	path := SocketPath()
+	if Running() {
+		return errors.New("a spotirice daemon is already running")
+	}
+	// A stale socket from a crashed daemon blocks Listen
+	_ = os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	s := NewController(c)
+	for _, sc := range userScripts {
+		if err := sc.loadLua(ctx, s); err != nil {
+			return err
+		}
+	}
+	s.scripts = userScripts
+	var listeners []func(Status)
+	if opts.Metrics != nil {
+		// Calls made for attached clients count too
+		s.http = &http.Client{Transport: opts.Metrics.Transport(nil)}
+	}
+
+	if opts.GlobalHotkeys {
+		update, err := registerHotkeys(ctx, func(req Request) {
+			if resp := s.Do(ctx, req); resp.Error != "" {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", req.Cmd, resp.Error)
+			}
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Global hotkeys:", err)
+		}
+		if update != nil {
+			listeners = append(listeners, update)
+		}
+	}
+
+	if opts.Metrics != nil {
+		listeners = append(listeners, func(st Status) {
+			opts.Metrics.SetTrack(st.ID)
+			opts.Metrics.SetPlayback(st.Playing, st.Volume)
+		})
+		go func() {
+			if err := opts.Metrics.ListenAndServe(ctx, opts.MetricsAddr); err != nil {
+				fmt.Fprintln(os.Stderr, "Metrics:", err)
+			}
+		}()
+	}
+
+	if len(hooks) > 0 {
+		listeners = append(listeners, Watch(func(ev Event) {
+			for _, h := range hooks {
+				if !h.wants(ev.Type) {
+					continue
+				}
+				// Retries must not hold up polling or the other hooks
+				go func() {
+					if err := h.deliver(ctx, ev); err != nil {
+						fmt.Fprintf(os.Stderr, "Webhook %s: %s\n", h.cfg.URL, err)
+					}
+				}()
+			}
+		}))
+	}
+	if opts.History {
+		listeners = append(listeners, Watch(func(ev Event) {
+			if ev.Type != EventTrackChanged || ev.Status.ID == "" {
+				return
+			}
+			err := config.AppendHistory(config.HistoryEntry{
+				Time:       ev.Time,
+				TrackID:    ev.Status.ID,
+				Track:      ev.Status.Track,
+				Artist:     ev.Status.Artist,
+				DurationMs: ev.Status.DurationMs,
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "History:", err)
+			}
+		}))
+	}
+	if len(userScripts) > 0 {
+		listeners = append(listeners, Watch(func(ev Event) {
+			for _, sc := range userScripts {
+				if !sc.wants(ev.Type) {
+					continue
+				}
+				if sc.lua != nil {
+					sc.handOver(ev)
+					continue
+				}
+				go func() {
+					cmds, err := scripts.Run(ctx, sc.cfg.Run, scriptState(ev))
+					if err != nil {
+						fmt.Fprintln(os.Stderr, "Script", err)
+						return
+					}
+					for _, cmd := range cmds {
+						if resp := s.Do(ctx, Request(cmd)); resp.Error != "" {
+							fmt.Fprintf(os.Stderr, "Script %s: %s: %s\n", sc.cfg.Run, cmd.Cmd, resp.Error)
+						}
+					}
+				}()
+			}
+		}))
+	}
+	s.OnChange = func(st Status) {
+		for _, fn := range listeners {
+			fn(st)
+		}
+	}
+	go s.Poll(ctx, pollInterval)
+	if len(opts.PauseOn) > 0 {
+		go s.watchPower(ctx, opts)
+	}
+
+	if opts.Party.Addr != "" {
+		s.enableParty(opts.Party)
+		go func() {
+			if err := s.servePartyPage(ctx, opts.Party.Addr); err != nil {
+				fmt.Fprintln(os.Stderr, "Party page:", err)
+			}
+		}()
+	}
+
+	if opts.FIFO != "" {
+		go func() {
+			err := ServeFIFO(ctx, opts.FIFO, func(req Request) {
+				if resp := s.Do(ctx, req); resp.Error != "" {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", req.Cmd, resp.Error)
+				}
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Control pipe:", err)
+			}
+		}()
+	}
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// Poll refreshes the state every interval until ctx is cancelled.
+func (s *Controller) Poll(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		s.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Refresh fetches the player state now and returns it.
+func (s *Controller) Refresh(ctx context.Context) (Status, error) {
+	if err := s.refresh(ctx); err != nil {
+		return Status{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status, nil
+}
+
+// refresh fetches the player state, sends it to subscribers and tells
+// OnChange about any change. The state is kept when it can't be had;
+// polling tries again later.
+func (s *Controller) refresh(ctx context.Context) error {
+	st, id, player, err := s.fetch(ctx)
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	if err != nil {
+		s.publish(Response{Error: err.Error()})
+		return err
+	}
+	s.mu.Lock()
+	s.status = st
+	s.player = player
+	s.trackID = id
+	s.mu.Unlock()
+	s.publish(Response{OK: true, Status: &st, Player: &player})
+
+	prev := s.notified
+	prev.ProgressMs = st.ProgressMs
+	// The first state goes out even when it is the zero one (nothing
+	// playing), so listeners have their baseline before the first change
+	if s.OnChange != nil && (!s.reported || prev != st) {
+		s.reported = true
+		s.notified = st
+		s.OnChange(st)
+	}
+	return nil
+}
+
+// fetch gets the player state: from Spotify, or the daemon's for a remote
+// Controller, which has no Player.
+func (s *Controller) fetch(ctx context.Context) (st Status, id spotify.ID, player Player, err error) {
+	if s.remote {
+		resp, err := Send(Request{Cmd: "status"})
+		if err != nil {
+			return Status{}, "", Player{}, err
+		}
+		if resp.Status == nil {
+			return Status{}, "", Player{}, errors.New("the daemon sent no status")
+		}
+		return *resp.Status, spotify.ID(resp.Status.ID), Player{}, nil
+	}
+	if player, err = s.fetchPlayer(ctx); err != nil {
+		return Status{}, "", Player{}, err
+	}
+	var state spotify.PlayerState
+	if len(player.State) > 0 {
+		if err := json.Unmarshal(player.State, &state); err != nil {
+			return Status{}, "", Player{}, err
+		}
+	}
+	st = Status{
+		Playing:    state.Playing,
+		ProgressMs: int(state.Progress),
+		Device:     state.Device.Name,
+		Volume:     int(state.Device.Volume),
+		Shuffle:    state.ShuffleState,
+		Repeat:     state.RepeatState,
+	}
+	if item := state.Item; item != nil {
+		id = item.ID
+		st.ID = string(id)
+		st.Track = item.Name
+		st.DurationMs = int(item.Duration)
+		st.Album = item.Album.Name
+		st.AlbumID = string(item.Album.ID)
+		if len(item.Album.Images) > 0 {
+			st.ArtURL = item.Album.Images[0].URL
+		}
+		if len(item.Artists) > 0 {
+			st.Artist = item.Artists[0].Name
+			st.ArtistID = string(item.Artists[0].ID)
+		}
+	}
+	// Episodes aren't in the liked songs library
+	if id != "" && state.Item.Type != "episode" {
+		st.Liked = s.isLiked(ctx, id)
+	}
+	return st, id, player, nil
+}
+
+// fetchPlayer makes the state request as an "api" request, so the reply
+// can be handed on as it came. Without episodes in additional_types, a
+// podcast playing looks like nothing playing.
+func (s *Controller) fetchPlayer(ctx context.Context) (Player, error) {
+	started := time.Now()
+	resp := s.doAPI(ctx, []string{http.MethodGet, apiBase + "v1/me/player?additional_types=episode", ""})
+	if resp.Error != "" {
+		return Player{}, errors.New(resp.Error)
+	}
+	player := Player{LatencyMs: int(time.Since(started).Milliseconds())}
+	switch api := resp.API; {
+	case api.StatusCode >= 300:
+		var e struct {
+			Error spotify.Error `json:"error"`
+		}
+		if json.Unmarshal(api.Body, &e) == nil && e.Error.Message != "" {
+			return Player{}, e.Error
+		}
+		return Player{}, fmt.Errorf("spotify: HTTP %d: %s", api.StatusCode, http.StatusText(api.StatusCode))
+	case api.StatusCode != http.StatusNoContent:
+		player.State = api.Body
+	}
+	return player, nil
+}
+
+func (s *Controller) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	sc := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for sc.Scan() {
+		var req Request
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			_ = enc.Encode(Response{Error: "invalid request: " + err.Error()})
+			continue
+		}
+		if req.Cmd == "subscribe" {
+			s.serveSubscription(ctx, conn, enc)
+			return
+		}
+		_ = enc.Encode(s.Do(ctx, req))
+	}
+}
+
+// Do runs one command: status, play, pause, toggle, next, prev, like,
+// volume N, seek MS, run NAME (a Lua script's command), api (see
+// Transport), or one of the party commands.
+func (s *Controller) Do(ctx context.Context, req Request) Response {
+	if s.remote {
+		resp, err := Send(req)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		if req.Cmd != "status" {
+			s.refresh(ctx)
+		}
+		return resp
+	}
+	var err error
+	switch req.Cmd {
+	case "api":
+		return s.doAPI(ctx, req.Args)
+	case "party", "party-accept", "party-reject", "party-auto":
+		return s.doParty(ctx, req)
+	case "status":
+		s.mu.Lock()
+		st := s.status
+		s.mu.Unlock()
+		return Response{OK: true, Status: &st}
+	case "play":
+		err = s.client.Play(ctx)
+	case "pause":
+		err = s.client.Pause(ctx)
+	case "toggle":
+		s.mu.Lock()
+		playing := s.status.Playing
+		s.mu.Unlock()
+		if playing {
+			err = s.client.Pause(ctx)
+		} else {
+			err = s.client.Play(ctx)
+		}
+	case "next":
+		err = s.client.Next(ctx)
+	case "prev":
+		err = s.client.Previous(ctx)
+	case "like":
+		err = s.toggleLike(ctx)
+	case "run":
+		err = s.runScriptCommand(ctx, req.Args)
+	case "volume":
+		var v int
+		if len(req.Args) != 1 {
+			err = errors.New("usage: volume <0-100>")
+		} else if v, err = strconv.Atoi(req.Args[0]); err == nil {
+			err = s.client.Volume(ctx, v)
+		}
+	case "seek":
+		var ms int
+		if len(req.Args) != 1 {
+			err = errors.New("usage: seek <milliseconds>")
+		} else if ms, err = strconv.Atoi(req.Args[0]); err == nil {
+			err = s.client.Seek(ctx, ms)
+		}
+	default:
+		err = fmt.Errorf("unknown command %q", req.Cmd)
+	}
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	// Reflect the change in the next status request right away
+	s.refresh(ctx)
+	return Response{OK: true}
+}
+
+// isLiked looks up whether id is in Liked Songs, asking Spotify only the
+// first time a track comes up; toggleLike keeps the answer current.
+func (s *Controller) isLiked(ctx context.Context, id spotify.ID) bool {
+	s.mu.Lock()
+	liked, ok := s.liked[id]
+	s.mu.Unlock()
+	if ok {
+		return liked
+	}
+	has, err := s.client.UserHasTracks(ctx, id)
+	if err != nil || len(has) == 0 {
+		return false
+	}
+	s.mu.Lock()
+	s.liked[id] = has[0]
+	s.mu.Unlock()
+	return has[0]
+}
+
+// toggleLike adds the playing track to liked songs, or removes it if it is
+// already there.
+func (s *Controller) toggleLike(ctx context.Context) error {
+	s.mu.Lock()
+	id := s.trackID
+	s.mu.Unlock()
+	if id == "" {
+		return errors.New("nothing likeable is playing")
+	}
+	liked := s.isLiked(ctx, id)
+	var err error
+	if liked {
+		err = s.client.RemoveTracksFromLibrary(ctx, id)
+	} else {
+		err = s.client.AddTracksToLibrary(ctx, id)
+	}
+	if err == nil {
+		s.mu.Lock()
+		s.liked[id] = !liked
+		s.mu.Unlock()
+	}
+	return err
+}
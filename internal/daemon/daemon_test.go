@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/mock"
+)
+
+// TestConcurrentDo runs commands side by side, as the socket, the control
+// pipe and hotkeys do, and checks OnChange is never called twice at once.
+// Run with -race.
+func TestConcurrentDo(t *testing.T) {
+	ctx := context.Background()
+	s := testController(mock.New().Transport())
+
+	var inside, overlaps atomic.Int32
+	var changes int
+	s.OnChange = Watch(func(Event) {
+		if inside.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		changes++
+		inside.Add(-1)
+	})
+	s.Refresh(ctx)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := Request{Cmd: "next"}
+			if i%2 == 0 {
+				req = Request{Cmd: "volume", Args: []string{strconv.Itoa(10 * i)}}
+			}
+			if resp := s.Do(ctx, req); resp.Error != "" {
+				t.Errorf("%s: %s", req.Cmd, resp.Error)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := overlaps.Load(); n > 0 {
+		t.Errorf("OnChange calls overlapped %d times", n)
+	}
+	if changes == 0 {
+		t.Error("no events for the changes made")
+	}
+}
+
+// TestLuaRunsRun has a Lua command start a script command, which would wait
+// on the interpreter it is running in.
+func TestLuaRunsRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loop.lua")
+	src := `spotirice.command("loop", function() spotirice.run("run", "loop") end)`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := testController(mock.New().Transport())
+	list, err := newScripts([]config.Script{{Run: path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := list[0].loadLua(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	s.scripts = list
+
+	done := make(chan Response)
+	go func() { done <- s.Do(ctx, Request{Cmd: "run", Args: []string{"loop"}}) }()
+	select {
+	case resp := <-done:
+		if !strings.Contains(resp.Error, "can't run script commands") {
+			t.Errorf("run loop = %+v, want the nested run refused", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run loop deadlocked")
+	}
+}
+
+// TestEpisodeStatus plays a podcast episode, which the API only reports
+// to clients that ask for episodes.
+func TestEpisodeStatus(t *testing.T) {
+	s := testController(handler(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/me/player" {
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.Contains(r.URL.Query().Get("additional_types"), "episode") {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		io.WriteString(w, `{
+			"is_playing": true, "progress_ms": 1000, "currently_playing_type": "episode",
+			"device": {"name": "Laptop", "volume_percent": 40},
+			"item": {"id": "ep1", "type": "episode", "name": "Episode One", "duration_ms": 60000}
+		}`)
+	}))
+	st, err := s.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Playing || st.ID != "ep1" || st.Track != "Episode One" || st.DurationMs != 60000 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+// testController runs a Controller against rt, with its client as well as
+// for the requests it makes itself (the state, "api").
+func testController(rt http.RoundTripper) *Controller {
+	s := NewController(spotify.New(&http.Client{Transport: &oauth2.Transport{
+		Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test", TokenType: "Bearer"}),
+		Base:   rt,
+	}}))
+	s.http = &http.Client{Transport: rt}
+	return s
+}
+
+// handler answers requests itself, as a RoundTripper.
+type handler func(http.ResponseWriter, *http.Request)
+
+func (h handler) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import "time"
+
+// Event types, as named in the event stream.
+const (
+	EventTrackChanged  = "track_changed"
+	EventPaused        = "paused"
+	EventResumed       = "resumed"
+	EventLiked         = "liked"
+	EventUnliked       = "unliked"
+	EventDeviceChanged = "device_changed"
+	EventVolumeChanged = "volume_changed"
+)
+
+// knownEvents holds the event types above, for checking filters in the
+// configuration.
+var knownEvents = map[string]bool{
+	EventTrackChanged: true, EventPaused: true, EventResumed: true,
+	EventLiked: true, EventUnliked: true,
+	EventDeviceChanged: true, EventVolumeChanged: true,
+}
+
+// Event is one change in playback, with the state after it.
+type Event struct {
+	Type   string    `json:"event"`
+	Time   time.Time `json:"time"`
+	Status Status    `json:"status"`
+}
+
+// Diff lists the event types that lead from prev to cur, in a fixed order.
+// A new track only reports track_changed, not the like state it came with.
+func Diff(prev, cur Status) []string {
+	var events []string
+	trackChanged := cur.ID != prev.ID || cur.Track != prev.Track
+	if trackChanged {
+		events = append(events, EventTrackChanged)
+	}
+	if cur.Playing != prev.Playing {
+		if cur.Playing {
+			events = append(events, EventResumed)
+		} else {
+			events = append(events, EventPaused)
+		}
+	}
+	if !trackChanged && cur.Liked != prev.Liked {
+		if cur.Liked {
+			events = append(events, EventLiked)
+		} else {
+			events = append(events, EventUnliked)
+		}
+	}
+	if cur.Device != prev.Device {
+		events = append(events, EventDeviceChanged)
+	}
+	if cur.Volume != prev.Volume && cur.Device == prev.Device {
+		events = append(events, EventVolumeChanged)
+	}
+	return events
+}
+
+// Watch turns a Controller.OnChange callback into events: fn is called for
+// each change between consecutive states. The first state is the baseline
+// and produces none.
+func Watch(fn func(Event)) func(Status) {
+	var prev Status
+	first := true
+	return func(st Status) {
+		if first {
+			first = false
+			prev = st
+			return
+		}
+		now := time.Now()
+		for _, typ := range Diff(prev, st) {
+			fn(Event{Type: typ, Time: now, Status: st})
+		}
+		prev = st
+	}
+}
@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	base := Status{ID: "a", Track: "A", Playing: true, Device: "Laptop", Volume: 50}
+	with := func(edit func(*Status)) Status {
+		st := base
+		edit(&st)
+		return st
+	}
+
+	tests := []struct {
+		name string
+		cur  Status
+		want []string
+	}{
+		{"nothing", base, nil},
+		{"position only", with(func(s *Status) { s.ProgressMs = 30000 }), nil},
+		{"new track", with(func(s *Status) { s.ID, s.Track = "b", "B" }), []string{EventTrackChanged}},
+		// Local files have no ID
+		{"new track without ID", with(func(s *Status) { s.ID, s.Track = "", "Local" }), []string{EventTrackChanged}},
+		{"paused", with(func(s *Status) { s.Playing = false }), []string{EventPaused}},
+		{"liked", with(func(s *Status) { s.Liked = true }), []string{EventLiked}},
+		// The new track's like state comes with it
+		{"new liked track", with(func(s *Status) { s.ID, s.Liked = "b", true }), []string{EventTrackChanged}},
+		{"volume", with(func(s *Status) { s.Volume = 70 }), []string{EventVolumeChanged}},
+		// A new device's volume is its own, not a change
+		{"device", with(func(s *Status) { s.Device, s.Volume = "Phone", 100 }), []string{EventDeviceChanged}},
+		{"everything", Status{ID: "b", Liked: true, Device: "Phone", Volume: 10},
+			[]string{EventTrackChanged, EventPaused, EventDeviceChanged}},
+	}
+	for _, tt := range tests {
+		if got := Diff(base, tt.cur); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Diff = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Resuming is the reverse of pausing
+	if got := Diff(with(func(s *Status) { s.Playing = false }), base); !slices.Equal(got, []string{EventResumed}) {
+		t.Errorf("resumed: Diff = %v", got)
+	}
+	if got := Diff(with(func(s *Status) { s.Liked = true }), base); !slices.Equal(got, []string{EventUnliked}) {
+		t.Errorf("unliked: Diff = %v", got)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	var got []string
+	fn := Watch(func(ev Event) {
+		got = append(got, ev.Type+" "+ev.Status.Track)
+	})
+
+	// The first state is the baseline, even with nothing playing
+	fn(Status{})
+	fn(Status{ID: "a", Track: "A", Playing: true})
+	fn(Status{ID: "a", Track: "A", Playing: true})
+	fn(Status{ID: "a", Track: "A", Playing: false})
+	fn(Status{ID: "b", Track: "B", Playing: true, Volume: 20})
+
+	want := []string{
+		"track_changed A", "resumed A",
+		"paused A",
+		"track_changed B", "resumed B", "volume_changed B",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+
+	// Each Watch has a baseline of its own
+	var first []Event
+	Watch(func(ev Event) { first = append(first, ev) })(Status{ID: "a", Playing: true})
+	if len(first) != 0 {
+		t.Errorf("first state produced %v", first)
+	}
+}
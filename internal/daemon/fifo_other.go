@@ -0,0 +1,13 @@
+//go:build !unix
+
+package daemon
+
+import (
+	"context"
+	"errors"
+)
+
+// ServeFIFO needs named pipes, which only Unix-like systems have.
+func ServeFIFO(ctx context.Context, path string, handle func(Request)) error {
+	return errors.New("the control pipe is not supported on this platform")
+}
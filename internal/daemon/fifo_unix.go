@@ -0,0 +1,59 @@
+//go:build unix
+
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// ServeFIFO creates a named pipe at path and passes each line written to
+// it to handle as a Request ("next", "volume 40", ...) until ctx is
+// cancelled. The pipe is removed again on return.
+func ServeFIFO(ctx context.Context, path string, handle func(Request)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// Replace a pipe left behind by a crash (or still used by another
+	// instance; the newest one takes over)
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&fs.ModeNamedPipe == 0 {
+			return errors.New(path + " exists and is not a named pipe")
+		}
+		_ = os.Remove(path)
+	}
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	// Opening read-write keeps the pipe from reporting EOF each time a
+	// writer closes it, and doesn't block until the first writer appears
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		handle(Request{Cmd: fields[0], Args: fields[1:]})
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return sc.Err()
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"context"
+
+	"github.com/metolius25/spotirice/internal/mpris"
+)
+
+// registerHotkeys offers the daemon as an MPRIS player until ctx is
+// cancelled. Desktops hand the media keys to MPRIS players, so they control
+// the daemon without a terminal; other shortcuts can run `spotirice toggle`,
+// `next`, `prev` or `like`. The returned listener keeps the player's state
+// current.
+func registerHotkeys(ctx context.Context, run func(Request)) (func(Status), error) {
+	server, err := mpris.Serve(func(cmd string, args ...string) {
+		run(Request{Cmd: cmd, Args: args})
+	})
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	return func(st Status) { server.Update(mprisState(st)) }, nil
+}
+
+// mprisState is st as MPRIS clients see it.
+func mprisState(st Status) mpris.State {
+	ms := mpris.State{
+		Playing:    st.Playing,
+		Title:      st.Track,
+		Album:      st.Album,
+		ArtURL:     st.ArtURL,
+		LengthMs:   st.DurationMs,
+		PositionMs: st.ProgressMs,
+		Volume:     st.Volume,
+		Shuffle:    st.Shuffle,
+		Repeat:     st.Repeat,
+		CanControl: st.Device != "",
+	}
+	if st.Artist != "" {
+		ms.Artists = []string{st.Artist}
+	}
+	if st.ID != "" {
+		ms.URI = "spotify:track:" + st.ID
+		ms.URL = "https://open.spotify.com/track/" + st.ID
+	}
+	return ms
+}
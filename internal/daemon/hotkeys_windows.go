@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32                 = windows.NewLazySystemDLL("user32.dll")
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+)
+
+const (
+	wmHotkey = 0x0312
+	wmQuit   = 0x0012
+
+	modAlt      = 0x0001
+	modControl  = 0x0002
+	modNoRepeat = 0x4000
+
+	vkMediaNextTrack = 0xB0
+	vkMediaPrevTrack = 0xB1
+	vkMediaPlayPause = 0xB3
+)
+
+// hotkeys are registered with their index+1 as the hotkey ID.
+var hotkeys = []struct {
+	mods, vk uintptr
+	cmd      string
+}{
+	{0, vkMediaPlayPause, "toggle"},
+	{0, vkMediaNextTrack, "next"},
+	{0, vkMediaPrevTrack, "prev"},
+	{modControl | modAlt, 'L', "like"},
+}
+
+// winMsg is the Win32 MSG structure.
+type winMsg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+}
+
+// registerHotkeys binds the media keys and Ctrl+Alt+L system-wide and calls
+// run with the matching command until ctx is cancelled. Keys another program
+// already owns (often the Spotify client itself) are reported but don't stop
+// the others from working. No state needs passing on, so the listener
+// returned is nil.
+func registerHotkeys(ctx context.Context, run func(Request)) (func(Status), error) {
+	errc := make(chan error, 1)
+	go func() {
+		// Hotkey messages go to the registering thread's queue
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		var errs []error
+		registered := 0
+		for i, hk := range hotkeys {
+			if r, _, err := procRegisterHotKey.Call(0, uintptr(i+1), hk.mods|modNoRepeat, hk.vk); r == 0 {
+				errs = append(errs, fmt.Errorf("%s hotkey: %w", hk.cmd, err))
+				continue
+			}
+			registered++
+		}
+		errc <- errors.Join(errs...)
+		if registered == 0 {
+			return
+		}
+		defer func() {
+			for i := range hotkeys {
+				procUnregisterHotKey.Call(0, uintptr(i+1))
+			}
+		}()
+
+		tid := windows.GetCurrentThreadId()
+		go func() {
+			<-ctx.Done()
+			procPostThreadMessageW.Call(uintptr(tid), wmQuit, 0, 0)
+		}()
+
+		var m winMsg
+		for {
+			r, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+			if int32(r) <= 0 {
+				return
+			}
+			if id := int(m.wParam) - 1; m.message == wmHotkey && id >= 0 && id < len(hotkeys) {
+				go run(Request{Cmd: hotkeys[id].cmd})
+			}
+		}
+	}()
+	return nil, <-errc
+}
@@ -0,0 +1,395 @@
+package daemon
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// Limits on what guests can send.
+const (
+	partyMaxPending = 50
+	partyMaxGuest   = 40
+	partySearchSize = 10
+)
+
+// After a wrong PIN an address waits partyPINDelay before its next try,
+// twice as long after each further one, up to partyPINMaxDelay.
+const (
+	partyPINDelay    = time.Second
+	partyPINMaxDelay = 5 * time.Minute
+)
+
+// PartyRequest is a track a guest asked for on the party page.
+type PartyRequest struct {
+	ID     int       `json:"id"`
+	URI    string    `json:"uri"`
+	Track  string    `json:"track"`
+	Artist string    `json:"artist"`
+	Guest  string    `json:"guest,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// PartyState is what the "party" command reports.
+type PartyState struct {
+	AutoAccept bool           `json:"auto_accept"`
+	Pending    []PartyRequest `json:"pending"`
+}
+
+type party struct {
+	pin string
+
+	mu         sync.Mutex
+	autoAccept bool
+	nextID     int
+	pending    []PartyRequest
+	failures   map[string]*pinFailures // by guest address
+}
+
+// pinFailures counts the wrong PINs from one address.
+type pinFailures struct {
+	count int
+	until time.Time // no tries before this
+}
+
+// checkPIN checks the PIN a guest at addr sent. While addr is waiting out
+// its last wrong one the PIN isn't looked at, and wait says for how long.
+func (p *party) checkPIN(addr, pin string, now time.Time) (ok bool, wait time.Duration) {
+	if p.pin == "" {
+		return true, 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := p.failures[addr]
+	if f != nil && now.Before(f.until) {
+		return false, f.until.Sub(now)
+	}
+	if subtle.ConstantTimeCompare([]byte(pin), []byte(p.pin)) == 1 {
+		delete(p.failures, addr)
+		return true, 0
+	}
+	if f == nil {
+		// Addresses that have long stopped trying are forgotten
+		for a, old := range p.failures {
+			if now.Sub(old.until) > partyPINMaxDelay {
+				delete(p.failures, a)
+			}
+		}
+		f = &pinFailures{}
+		p.failures[addr] = f
+	}
+	delay := partyPINMaxDelay
+	if f.count < 16 {
+		delay = min(partyPINDelay<<f.count, partyPINMaxDelay)
+	}
+	f.count++
+	f.until = now.Add(delay)
+	return false, 0
+}
+
+func (p *party) state() PartyState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PartyState{AutoAccept: p.autoAccept, Pending: slices.Clone(p.pending)}
+}
+
+// take removes and returns the pending request with the given ID.
+func (p *party) take(arg string) (PartyRequest, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return PartyRequest{}, fmt.Errorf("invalid request id %q", arg)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, r := range p.pending {
+		if r.ID == id {
+			p.pending = slices.Delete(p.pending, i, i+1)
+			return r, nil
+		}
+	}
+	return PartyRequest{}, fmt.Errorf("no pending request %d", id)
+}
+
+// doParty runs the party commands: party (list), party-accept ID,
+// party-reject ID and party-auto on|off.
+func (s *Controller) doParty(ctx context.Context, req Request) Response {
+	p := s.party
+	if p == nil {
+		return Response{Error: "party mode is off; set [party] addr in config.toml"}
+	}
+	var err error
+	switch req.Cmd {
+	case "party":
+		st := p.state()
+		return Response{OK: true, Party: &st}
+	case "party-accept", "party-reject":
+		if len(req.Args) != 1 {
+			return Response{Error: "usage: " + req.Cmd + " <id>"}
+		}
+		var r PartyRequest
+		if r, err = p.take(req.Args[0]); err == nil && req.Cmd == "party-accept" {
+			err = s.client.QueueSong(ctx, partyTrackID(r.URI))
+		}
+	case "party-auto":
+		if len(req.Args) != 1 || (req.Args[0] != "on" && req.Args[0] != "off") {
+			return Response{Error: "usage: party-auto on|off"}
+		}
+		p.mu.Lock()
+		p.autoAccept = req.Args[0] == "on"
+		p.mu.Unlock()
+	}
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	return Response{OK: true}
+}
+
+// servePartyPage serves the guest page on addr until ctx is cancelled.
+func (s *Controller) servePartyPage(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, partyPage)
+	})
+	mux.HandleFunc("POST /search", s.partySearch)
+	mux.HandleFunc("POST /request", s.partySubmit)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.Serve(ln); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// partyPINOK checks the PIN r came with, answering r when it won't do.
+func (s *Controller) partyPINOK(w http.ResponseWriter, r *http.Request, pin string) bool {
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+	ok, wait := s.party.checkPIN(addr, pin, time.Now())
+	switch {
+	case ok:
+		return true
+	case wait > 0:
+		secs := int((wait + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+		partyError(w, http.StatusTooManyRequests, fmt.Sprintf("too many wrong PINs; try again in %ds", secs))
+	default:
+		partyError(w, http.StatusForbidden, "wrong PIN")
+	}
+	return false
+}
+
+func partyError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func (s *Controller) partySearch(w http.ResponseWriter, r *http.Request) {
+	// The PIN goes in the body, where it stays out of logs and history
+	var body struct {
+		Q   string `json:"q"`
+		PIN string `json:"pin"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		partyError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if !s.partyPINOK(w, r, body.PIN) {
+		return
+	}
+	q := strings.TrimSpace(body.Q)
+	if q == "" {
+		partyError(w, http.StatusBadRequest, "nothing to search for")
+		return
+	}
+	res, err := s.client.Search(r.Context(), q, spotify.SearchTypeTrack, spotify.Limit(partySearchSize))
+	if err != nil {
+		partyError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	type result struct {
+		URI    string `json:"uri"`
+		Track  string `json:"track"`
+		Artist string `json:"artist"`
+	}
+	results := []result{}
+	if res.Tracks != nil {
+		for _, t := range res.Tracks.Tracks {
+			results = append(results, result{URI: string(t.URI), Track: t.Name, Artist: firstArtist(t.Artists)})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
+func (s *Controller) partySubmit(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		URI   string `json:"uri"`
+		Guest string `json:"guest"`
+		PIN   string `json:"pin"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		partyError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if !s.partyPINOK(w, r, body.PIN) {
+		return
+	}
+	msg, err := s.addPartyRequest(r.Context(), body.URI, body.Guest)
+	if err != nil {
+		partyError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": msg})
+}
+
+// addPartyRequest queues the guest's track right away with auto-accept on,
+// or holds it for the host.
+func (s *Controller) addPartyRequest(ctx context.Context, uri, guest string) (string, error) {
+	if partyTrackID(uri) == "" {
+		return "", errors.New("only tracks can be requested")
+	}
+	// Look the track up rather than trusting the names the page sent
+	t, err := s.client.GetTrack(ctx, partyTrackID(uri))
+	if err != nil {
+		return "", err
+	}
+	if guest = strings.TrimSpace(guest); len([]rune(guest)) > partyMaxGuest {
+		guest = string([]rune(guest)[:partyMaxGuest])
+	}
+
+	p := s.party
+	p.mu.Lock()
+	auto := p.autoAccept
+	if !auto {
+		if len(p.pending) >= partyMaxPending {
+			p.mu.Unlock()
+			return "", errors.New("too many requests waiting; try again later")
+		}
+		p.nextID++
+		p.pending = append(p.pending, PartyRequest{
+			ID: p.nextID, URI: uri, Track: t.Name, Artist: firstArtist(t.Artists), Guest: guest, At: time.Now(),
+		})
+	}
+	p.mu.Unlock()
+
+	if auto {
+		if err := s.client.QueueSong(ctx, t.ID); err != nil {
+			return "", err
+		}
+		return t.Name + " is in the queue.", nil
+	}
+	return "Requested " + t.Name + "; the host will have a look.", nil
+}
+
+// partyTrackID returns the ID of a spotify:track: URI, or "" for anything
+// else.
+func partyTrackID(uri string) spotify.ID {
+	id, ok := strings.CutPrefix(uri, "spotify:track:")
+	if !ok {
+		return ""
+	}
+	return spotify.ID(id)
+}
+
+func firstArtist(artists []spotify.SimpleArtist) string {
+	if len(artists) == 0 {
+		return ""
+	}
+	return artists[0].Name
+}
+
+// enableParty turns on party mode with the [party] settings.
+func (s *Controller) enableParty(cfg config.Party) {
+	s.party = &party{pin: cfg.PIN, autoAccept: cfg.AutoAccept, failures: make(map[string]*pinFailures)}
+}
+
+const partyPage = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Spotirice party</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 32rem; margin: 2rem auto; padding: 0 1rem; background: #121212; color: #eee; }
+input, button { font: inherit; padding: .5rem; border-radius: .3rem; border: 1px solid #555; background: #222; color: #eee; }
+input { width: 100%; box-sizing: border-box; margin-bottom: .5rem; }
+li { list-style: none; display: flex; justify-content: space-between; align-items: center; gap: .5rem; padding: .4rem 0; border-bottom: 1px solid #333; }
+ul { padding: 0; }
+small { color: #999; }
+#msg { color: #1db954; min-height: 1.5em; }
+</style>
+</head>
+<body>
+<h1>🎉 Request a song</h1>
+<input id="guest" placeholder="Your name (optional)">
+<input id="pin" placeholder="PIN" inputmode="numeric">
+<form id="search"><input id="q" placeholder="Search for a track" autofocus></form>
+<p id="msg"></p>
+<ul id="results"></ul>
+<script>
+const $ = id => document.getElementById(id);
+const say = text => { $("msg").textContent = text; };
+$("search").onsubmit = async e => {
+  e.preventDefault();
+  const res = await fetch("/search", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ q: $("q").value, pin: $("pin").value }),
+  });
+  const data = await res.json();
+  if (!res.ok) { say(data.error); return; }
+  const list = $("results");
+  list.replaceChildren();
+  for (const t of data) {
+    const li = document.createElement("li");
+    const label = document.createElement("span");
+    label.append(t.track, document.createElement("br"));
+    const artist = document.createElement("small");
+    artist.textContent = t.artist;
+    label.append(artist);
+    const button = document.createElement("button");
+    button.textContent = "Request";
+    button.onclick = async () => {
+      const res = await fetch("/request", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ uri: t.uri, guest: $("guest").value, pin: $("pin").value }),
+      });
+      const data = await res.json();
+      say(res.ok ? data.message : data.error);
+    };
+    li.append(label, button);
+    list.append(li);
+  }
+  if (data.length === 0) say("Nothing found.");
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartyCheckPIN(t *testing.T) {
+	p := &party{pin: "4321", failures: make(map[string]*pinFailures)}
+	now := time.Now()
+
+	steps := []struct {
+		after    time.Duration // since the step before
+		addr     string
+		pin      string
+		ok       bool
+		waitMore bool // turned away without a look at the PIN
+	}{
+		{0, "a", "4321", true, false},
+		{0, "a", "0000", false, false},
+		// A second's wait after the first wrong one, even for the right PIN
+		{0, "a", "4321", false, true},
+		{500 * time.Millisecond, "a", "1111", false, true},
+		// Another address isn't held up
+		{0, "b", "4321", true, false},
+		{500 * time.Millisecond, "a", "1111", false, false},
+		// Two seconds after the second
+		{1500 * time.Millisecond, "a", "4321", false, true},
+		{500 * time.Millisecond, "a", "4321", true, false},
+		// The right PIN starts the count afresh
+		{0, "a", "0000", false, false},
+		{time.Second, "a", "4321", true, false},
+	}
+	for i, st := range steps {
+		now = now.Add(st.after)
+		ok, wait := p.checkPIN(st.addr, st.pin, now)
+		if ok != st.ok || (wait > 0) != st.waitMore {
+			t.Errorf("step %d: checkPIN(%q, %q) = %v, %v; want %v, waiting %v", i, st.addr, st.pin, ok, wait, st.ok, st.waitMore)
+		}
+	}
+}
+
+func TestPartyCheckPINBackoffLimit(t *testing.T) {
+	p := &party{pin: "4321", failures: make(map[string]*pinFailures)}
+	now := time.Now()
+	for range 40 {
+		p.checkPIN("a", "0000", now)
+		now = p.failures["a"].until
+	}
+	if _, wait := p.checkPIN("a", "4321", now.Add(-time.Nanosecond)); wait > partyPINMaxDelay {
+		t.Errorf("wait %v exceeds %v", wait, partyPINMaxDelay)
+	}
+
+	// Without a PIN anyone may request
+	open := &party{}
+	if ok, _ := open.checkPIN("a", "", now); !ok {
+		t.Error("checkPIN refused a guest with no PIN set")
+	}
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/metolius25/spotirice/internal/platform"
+)
+
+// wakeRetries and wakeRetryDelay give the network time to come back after
+// a wake before resuming gives up.
+const (
+	wakeRetries    = 5
+	wakeRetryDelay = 3 * time.Second
+)
+
+// watchPower pauses and resumes playback on suspend, wake, lock and unlock
+// as opts.PauseOn and opts.ResumeOn say.
+func (s *Controller) watchPower(ctx context.Context, opts Options) {
+	pausedByUs := false
+	err := platform.WatchPower(ctx, func(ev string) {
+		s.mu.Lock()
+		playing := s.status.Playing
+		s.mu.Unlock()
+
+		switch platform.PowerAction(ev, opts.PauseOn, opts.ResumeOn, playing, pausedByUs) {
+		case "pause":
+			if resp := s.Do(ctx, Request{Cmd: "pause"}); resp.Error != "" {
+				fmt.Fprintf(os.Stderr, "Pause on %s: %s\n", ev, resp.Error)
+				return
+			}
+			pausedByUs = true
+		case "play":
+			pausedByUs = false
+			for attempt := 1; ; attempt++ {
+				resp := s.Do(ctx, Request{Cmd: "play"})
+				if resp.Error == "" {
+					return
+				}
+				if attempt == wakeRetries {
+					fmt.Fprintf(os.Stderr, "Resume on %s: %s\n", ev, resp.Error)
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wakeRetryDelay):
+				}
+			}
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Suspend/lock:", err)
+	}
+}
@@ -0,0 +1,156 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+)
+
+// apiBase is the only place the daemon forwards API calls to; its token
+// mustn't go anywhere else.
+const apiBase = "https://api.spotify.com/"
+
+// APIResponse is the answer to an "api" request: what Spotify replied to
+// the call the daemon made on the client's behalf.
+type APIResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Client returns a Spotify client whose calls the running daemon makes with
+// its own login, so front ends attached to it needn't log in themselves.
+// Its token is a stand-in, for code that sets the header itself (the TUI's
+// raw API calls) and then uses Transport too.
+func Client() *spotify.Client {
+	return spotify.New(&http.Client{Transport: &oauth2.Transport{
+		Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "daemon", TokenType: "Bearer"}),
+		Base:   Transport{},
+	}})
+}
+
+// Transport carries HTTP requests to the Spotify API over the daemon's
+// socket as "api" requests: method, URL and body.
+type Transport struct{}
+
+func (Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp, err := Send(Request{Cmd: "api", Args: []string{req.Method, req.URL.String(), string(body)}})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if resp.API == nil {
+		return nil, errors.New("the daemon sent no API response")
+	}
+	header := make(http.Header)
+	if resp.API.ContentType != "" {
+		header.Set("Content-Type", resp.API.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.API.StatusCode, http.StatusText(resp.API.StatusCode)),
+		StatusCode:    resp.API.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.API.Body)),
+		ContentLength: int64(len(resp.API.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Attach returns a Controller for the running daemon: commands are sent to
+// it and the state is the one it last polled, so using it costs no login and
+// no API calls of its own.
+func Attach() *Controller {
+	return &Controller{client: Client(), remote: true, liked: make(map[spotify.ID]bool)}
+}
+
+// doAPI makes an API call for a client attached over the socket; args are
+// the method, URL and body Transport sends.
+func (s *Controller) doAPI(ctx context.Context, args []string) Response {
+	if len(args) != 3 {
+		return Response{Error: "usage: api <method> <url> <body>"}
+	}
+	method, url, body := args[0], args[1], args[2]
+	if !strings.HasPrefix(url, apiBase) {
+		return Response{Error: "api calls only go to " + apiBase}
+	}
+	tok, err := s.client.Token()
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	tok.SetAuthHeader(req)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	if method != http.MethodGet {
+		s.changedRemotely(ctx, req.URL.Path)
+	}
+	return Response{OK: true, API: &APIResponse{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        data,
+	}}
+}
+
+// catchUpDelays are when the state is refreshed after a remote change:
+// Spotify takes a moment to report some, like a skip.
+var catchUpDelays = []time.Duration{0, 500 * time.Millisecond, 1500 * time.Millisecond}
+
+// changedRemotely catches up with a change an attached client made through
+// the daemon: likes are looked up again, and the state is refreshed so the
+// daemon's listeners, status and subscribers see a new track or pause
+// right away.
+func (s *Controller) changedRemotely(ctx context.Context, path string) {
+	switch {
+	case strings.HasPrefix(path, "/v1/me/tracks"):
+		s.mu.Lock()
+		clear(s.liked)
+		s.mu.Unlock()
+		go s.refresh(ctx)
+	case strings.HasPrefix(path, "/v1/me/player"):
+		go func() {
+			for _, d := range catchUpDelays {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(d):
+				}
+				s.refresh(ctx)
+			}
+		}()
+	}
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/scripts"
+)
+
+// luaQueue is how many events may wait for a busy Lua script before
+// further ones are dropped.
+const luaQueue = 16
+
+type script struct {
+	cfg    config.Script
+	events map[string]bool
+	// lua is set for a .lua script once loaded; its events are handed over
+	// in order through queue.
+	lua   *scripts.Lua
+	queue chan Event
+}
+
+// newScripts checks the configured scripts' event filters.
+func newScripts(cfgs []config.Script) ([]*script, error) {
+	var list []*script
+	for i, cfg := range cfgs {
+		if cfg.Run == "" {
+			return nil, fmt.Errorf("script %d: run is missing", i+1)
+		}
+		sc := &script{cfg: cfg}
+		if len(cfg.Events) > 0 {
+			sc.events = make(map[string]bool)
+			for _, e := range cfg.Events {
+				if !knownEvents[e] {
+					return nil, fmt.Errorf("script %s: unknown event %q", cfg.Run, e)
+				}
+				sc.events[e] = true
+			}
+		}
+		list = append(list, sc)
+	}
+	return list, nil
+}
+
+func (sc *script) wants(typ string) bool {
+	return sc.events == nil || sc.events[typ]
+}
+
+// loadLua loads a .lua script into the interpreter, driving s, and handles
+// its events until ctx is cancelled. Other scripts are left alone.
+func (sc *script) loadLua(ctx context.Context, s *Controller) error {
+	if !scripts.IsLua(sc.cfg.Run) {
+		return nil
+	}
+	l, err := scripts.LoadLua(ctx, sc.cfg.Run, scripts.Host{
+		Do: func(cmd scripts.Command) error {
+			// A script's command runs with its interpreter locked, so one
+			// started from a script could wait on itself
+			if cmd.Cmd == "run" {
+				return errors.New("scripts can't run script commands")
+			}
+			if resp := s.Do(ctx, Request(cmd)); resp.Error != "" {
+				return errors.New(resp.Error)
+			}
+			return nil
+		},
+		Status: func() scripts.State {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return scriptState(Event{Status: s.status})
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range l.Events() {
+		if !knownEvents[e] {
+			l.Close()
+			return fmt.Errorf("script %s: unknown event %q", sc.cfg.Run, e)
+		}
+	}
+	sc.lua = l
+	sc.queue = make(chan Event, luaQueue)
+	go func() {
+		defer l.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev := <-sc.queue:
+				if err := l.Handle(ctx, scriptState(ev)); err != nil {
+					fmt.Fprintln(os.Stderr, "Script", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// handOver queues ev for a Lua script. It never blocks: the Lua handler
+// may itself be running a command whose refresh produced ev.
+func (sc *script) handOver(ev Event) {
+	select {
+	case sc.queue <- ev:
+	default:
+		fmt.Fprintf(os.Stderr, "Script %s: busy, dropped a %s event\n", sc.cfg.Run, ev.Type)
+	}
+}
+
+// runScriptCommand runs a command a Lua script defined, for `spotirice run`.
+func (s *Controller) runScriptCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: run <command> [args...]")
+	}
+	for _, sc := range s.scripts {
+		if sc.lua == nil {
+			continue
+		}
+		if ok, err := sc.lua.RunCommand(ctx, args[0], args[1:]); ok {
+			return err
+		}
+	}
+	return fmt.Errorf("no Lua script defines the command %q", args[0])
+}
+
+// scriptState is what scripts are told about ev.
+func scriptState(ev Event) scripts.State {
+	st := ev.Status
+	return scripts.State{
+		Event:      ev.Type,
+		ID:         st.ID,
+		Track:      st.Track,
+		Artist:     st.Artist,
+		Liked:      st.Liked,
+		Playing:    st.Playing,
+		ProgressMs: st.ProgressMs,
+		DurationMs: st.DurationMs,
+		Device:     st.Device,
+		Volume:     st.Volume,
+	}
+}
@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// unitName is the service's unit; each profile gets one of its own.
+func unitName() string {
+	if p := config.Profile(); p != "" {
+		return "spotirice-" + p + ".service"
+	}
+	return "spotirice.service"
+}
+
+const unitTemplate = `[Unit]
+Description=Spotirice daemon
+After=network-online.target
+
+[Service]
+ExecStart=%s daemon
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`
+
+func unitPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "systemd", "user", unitName()), nil
+}
+
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %v: %v: %s", args, err, out)
+	}
+	return nil
+}
+
+// InstallService writes a systemd user unit running exe as a daemon for the
+// current profile, then enables and starts it.
+func InstallService(exe string) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", errors.New("systemd services are only supported on Linux")
+	}
+	path, err := unitPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	command := exe
+	if p := config.Profile(); p != "" {
+		command += " --profile " + p
+	}
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(unitTemplate, command)), 0644); err != nil {
+		return "", err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return path, err
+	}
+	return path, systemctl("enable", "--now", unitName())
+}
+
+// UninstallService stops and removes the unit written by InstallService.
+func UninstallService() error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	// The unit may already be stopped or disabled
+	_ = systemctl("disable", "--now", unitName())
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
@@ -0,0 +1,109 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// A "subscribe" request turns its connection into a stream of state
+// notifications: Responses with the Status and Player of every refresh, the
+// daemon's polls as well as those after commands, or the Error of one that
+// failed. The first is the state the daemon has now.
+
+// Subscription is a connection to the running daemon taking its state
+// notifications.
+type Subscription struct {
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+// Subscribe connects to the running daemon for its state notifications.
+func Subscribe() (*Subscription, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(), time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("daemon not running: %w", err)
+	}
+	return subscribe(conn)
+}
+
+func subscribe(conn net.Conn) (*Subscription, error) {
+	if err := json.NewEncoder(conn).Encode(Request{Cmd: "subscribe"}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Subscription{conn: conn, dec: json.NewDecoder(conn)}, nil
+}
+
+// Next waits for the next notification. It fails once the daemon goes away
+// or the Subscription is closed.
+func (sub *Subscription) Next() (Response, error) {
+	var resp Response
+	if err := sub.dec.Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("lost the daemon: %w", err)
+	}
+	return resp, nil
+}
+
+// Close ends the subscription.
+func (sub *Subscription) Close() error {
+	return sub.conn.Close()
+}
+
+// serveSubscription sends notifications on conn until the client hangs up
+// or ctx is cancelled.
+func (s *Controller) serveSubscription(ctx context.Context, conn net.Conn, enc *json.Encoder) {
+	// Only the latest state matters to a client that fell behind
+	ch := make(chan Response, 1)
+	s.mu.Lock()
+	if s.subs == nil {
+		s.subs = make(map[chan Response]bool)
+	}
+	s.subs[ch] = true
+	st, player := s.status, s.player
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, ch)
+		s.mu.Unlock()
+	}()
+
+	// Clients send nothing more; reading only notices them leaving
+	gone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		close(gone)
+	}()
+
+	resp := Response{OK: true, Status: &st, Player: &player}
+	for {
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+		select {
+		case resp = <-ch:
+		case <-gone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// publish hands resp to every subscriber, replacing a notification one
+// hasn't taken yet. Callers hold changeMu, so notifications go out in the
+// order the states were stored.
+func (s *Controller) publish(resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- resp
+	}
+}
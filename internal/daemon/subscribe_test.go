@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/metolius25/spotirice/internal/mock"
+)
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	s := testController(mock.New().Transport())
+	if _, err := s.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	client, server := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		s.handle(ctx, server)
+		close(done)
+	}()
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	sub, err := subscribe(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The state the daemon has comes first, with the reply it came from
+	first, err := sub.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Status == nil || first.Status.Track == "" || first.Player == nil || len(first.Player.State) == 0 {
+		t.Fatalf("first notification = %+v", first)
+	}
+
+	// Then the state a command brings
+	if resp := s.Do(ctx, Request{Cmd: "next"}); resp.Error != "" {
+		t.Fatal(resp.Error)
+	}
+	second, err := sub.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Status == nil || second.Status.Track == first.Status.Track {
+		t.Errorf("after next: %+v, still %q", second.Status, first.Status.Track)
+	}
+
+	sub.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the subscription outlived its client")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) != 0 {
+		t.Errorf("%d subscribers left", len(s.subs))
+	}
+}
@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// webhookTimeout bounds a single delivery attempt.
+const webhookTimeout = 10 * time.Second
+
+// webhookFuncs are available in payload templates. json quotes a value, so
+// track names with quotes or backslashes can't break a JSON body.
+var webhookFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+type webhook struct {
+	cfg    config.Webhook
+	events map[string]bool
+	tmpl   *template.Template
+}
+
+// newWebhooks checks the configured hooks and parses their templates, so
+// mistakes show up when the daemon starts rather than on the first event.
+func newWebhooks(cfgs []config.Webhook) ([]*webhook, error) {
+	var hooks []*webhook
+	for i, cfg := range cfgs {
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook %d: url is missing", i+1)
+		}
+		h := &webhook{cfg: cfg}
+		if len(cfg.Events) > 0 {
+			h.events = make(map[string]bool)
+			for _, e := range cfg.Events {
+				if !knownEvents[e] {
+					return nil, fmt.Errorf("webhook %s: unknown event %q", cfg.URL, e)
+				}
+				h.events[e] = true
+			}
+		}
+		if cfg.Template != "" {
+			t, err := template.New(cfg.URL).Funcs(webhookFuncs).Parse(cfg.Template)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %s: %w", cfg.URL, err)
+			}
+			h.tmpl = t
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+func (h *webhook) wants(typ string) bool {
+	return h.events == nil || h.events[typ]
+}
+
+func (h *webhook) body(ev Event) ([]byte, error) {
+	if h.tmpl == nil {
+		return json.Marshal(ev)
+	}
+	var buf bytes.Buffer
+	if err := h.tmpl.Execute(&buf, ev); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver posts ev, retrying network errors, 429s and 5xx responses.
+func (h *webhook) deliver(ctx context.Context, ev Event) error {
+	body, err := h.body(ev)
+	if err != nil {
+		return err
+	}
+	contentType := h.cfg.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	delay := time.Second
+	for attempt := 0; ; attempt++ {
+		retry, err := h.post(ctx, contentType, body)
+		if err == nil || !retry || attempt >= h.cfg.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post makes one attempt and reports whether a failure is worth retrying.
+func (h *webhook) post(ctx context.Context, contentType string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("%s", resp.Status)
+	}
+	return false, nil
+}
@@ -0,0 +1,285 @@
+// Package demo records the Web API traffic of a session to a file and
+// replays it later (spotirice --demo), so screenshots, recordings and bug
+// reports come out the same every time and need no Spotify account.
+package demo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+)
+
+// apiHost is the only host recorded: token requests go to the accounts
+// service and are never written out.
+const apiHost = "api.spotify.com"
+
+// formatVersion is bumped when Session changes incompatibly.
+const formatVersion = 1
+
+// Session is the file format: the API calls of one run, in the order they
+// were answered.
+type Session struct {
+	Version   int        `json:"version"`
+	Recorded  time.Time  `json:"recorded"`
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// Exchange is one request and its response. Headers aren't kept, so neither
+// is the access token.
+type Exchange struct {
+	At     int64           `json:"at_ms"` // since the start of the session
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Recorder is a RoundTripper that passes requests on to its base transport
+// and keeps a copy of the API calls. It is safe for concurrent use.
+type Recorder struct {
+	base  http.RoundTripper
+	start time.Time
+
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecorder records the requests sent through base, or through
+// http.DefaultTransport when base is nil.
+func NewRecorder(base http.RoundTripper) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{base: base, start: time.Now()}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil || req.URL.Host != apiHost {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	ex := Exchange{
+		At:     time.Since(r.start).Milliseconds(),
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Status: resp.StatusCode,
+	}
+	if json.Valid(body) {
+		ex.Body = body
+	}
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, ex)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded session to path with the account's identity
+// replaced by placeholders.
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	s := Session{Version: formatVersion, Recorded: r.start, Exchanges: append([]Exchange(nil), r.exchanges...)}
+	r.mu.Unlock()
+
+	sanitize(&s)
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// sanitize replaces the user's ID, name and email wherever they appear
+// (owner fields, user URIs and links, paths) and drops the profile picture
+// and country from the profile itself. Only whole values are replaced, so a
+// short name can't garble other text.
+func sanitize(s *Session) {
+	placeholders := make(map[string]string)
+	for i, ex := range s.Exchanges {
+		if ex.Path != "/v1/me" || ex.Body == nil {
+			continue
+		}
+		var me map[string]interface{}
+		if json.Unmarshal(ex.Body, &me) != nil {
+			continue
+		}
+		for key, placeholder := range map[string]string{"id": "demo", "display_name": "Demo", "email": "demo@example.com"} {
+			if v, _ := me[key].(string); v != "" {
+				placeholders[v] = placeholder
+			}
+		}
+		me["images"] = []interface{}{}
+		delete(me, "country")
+		if body, err := json.Marshal(me); err == nil {
+			s.Exchanges[i].Body = body
+		}
+	}
+	if len(placeholders) == 0 {
+		return
+	}
+
+	replace := func(v string) string {
+		if p, ok := placeholders[v]; ok {
+			return p
+		}
+		for id, p := range placeholders {
+			for _, prefix := range []string{"spotify:user:", "/users/", "/user/"} {
+				if i := strings.Index(v, prefix+id); i >= 0 && (i+len(prefix+id) == len(v) || v[i+len(prefix+id)] == '/') {
+					return v[:i] + prefix + p + v[i+len(prefix+id):]
+				}
+			}
+		}
+		return v
+	}
+	for i := range s.Exchanges {
+		ex := &s.Exchanges[i]
+		ex.Path = replace(ex.Path)
+		if ex.Body == nil {
+			continue
+		}
+		// Numbers stay as written rather than going through float64
+		dec := json.NewDecoder(bytes.NewReader(ex.Body))
+		dec.UseNumber()
+		var body interface{}
+		if dec.Decode(&body) != nil {
+			continue
+		}
+		if data, err := json.Marshal(replaceStrings(body, replace)); err == nil {
+			ex.Body = data
+		}
+	}
+}
+
+// replaceStrings applies replace to every string in a decoded JSON value.
+func replaceStrings(v interface{}, replace func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return replace(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = replaceStrings(v[i], replace)
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = replaceStrings(v[k], replace)
+		}
+	}
+	return v
+}
+
+// Player answers requests from a recorded session, following its timeline:
+// a call gets the latest response recorded for it up to the same point in
+// the session, so playback progresses as it did while recording.
+type Player struct {
+	session Session
+	once    sync.Once
+	start   time.Time
+}
+
+// Load reads a session written by Recorder.Save. Replay starts with the first
+// request.
+func Load(path string) (*Player, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Version != formatVersion {
+		return nil, fmt.Errorf("%s: unsupported session version %d", path, s.Version)
+	}
+	return &Player{session: s}, nil
+}
+
+// Transport is a RoundTripper that answers every request from the session,
+// whatever its host, without touching the network.
+func (p *Player) Transport() http.RoundTripper {
+	return p
+}
+
+// Client is a spotify client replaying the session. Its token never
+// expires, so it is never refreshed.
+func (p *Player) Client() *spotify.Client {
+	return spotify.New(&http.Client{Transport: &oauth2.Transport{
+		Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "demo", TokenType: "Bearer"}),
+		Base:   p,
+	}})
+}
+
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	status, body := p.answer(req)
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// answer picks the response for req. An exact match on the query wins over
+// one on the path alone, since queries can carry things like timestamps that
+// never repeat. Commands that weren't recorded succeed without effect;
+// anything else unknown is a 404.
+func (p *Player) answer(req *http.Request) (int, []byte) {
+	p.once.Do(func() { p.start = time.Now() })
+	now := time.Since(p.start).Milliseconds()
+	query := req.URL.Query().Encode()
+
+	var exact, loose *Exchange
+	for i := range p.session.Exchanges {
+		ex := &p.session.Exchanges[i]
+		if ex.Method != req.Method || ex.Path != req.URL.Path {
+			continue
+		}
+		// The first recorded answer stands in until the session catches up
+		if ex.At > now && (exact != nil || loose != nil) {
+			break
+		}
+		if ex.Query == query {
+			exact = ex
+		}
+		loose = ex
+	}
+	if exact == nil {
+		exact = loose
+	}
+	switch {
+	case exact != nil:
+		return exact.Status, exact.Body
+	case req.Method != http.MethodGet:
+		return http.StatusNoContent, nil
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]interface{}{"status": http.StatusNotFound, "message": "Not in the demo session"},
+	})
+	return http.StatusNotFound, body
+}
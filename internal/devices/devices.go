@@ -0,0 +1,51 @@
+package devices
+
+import (
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// Matches reports whether d is the device named by pref, which may be either
+// a device ID or a (case-insensitive) device name.
+func Matches(d spotify.PlayerDevice, pref string) bool {
+	if pref == "" {
+		return false
+	}
+	return string(d.ID) == pref || strings.EqualFold(d.Name, pref)
+}
+
+// Find returns the unrestricted device matching pref, if present.
+func Find(devs []spotify.PlayerDevice, pref string) *spotify.PlayerDevice {
+	for i := range devs {
+		if !devs[i].Restricted && Matches(devs[i], pref) {
+			return &devs[i]
+		}
+	}
+	return nil
+}
+
+// Choose picks the device to play on: the preferred device when available,
+// then the last used device, otherwise the first unrestricted device of one
+// of the given types. preferredFound is false when a preference was set but
+// no such device is available, so callers can warn about the fallback.
+func Choose(devs []spotify.PlayerDevice, pref, last string, types ...string) (chosen *spotify.PlayerDevice, preferredFound bool) {
+	if d := Find(devs, pref); d != nil {
+		return d, true
+	}
+	if d := Find(devs, last); d != nil {
+		return d, pref == ""
+	}
+	for i := range devs {
+		d := &devs[i]
+		if d.Restricted {
+			continue
+		}
+		for _, t := range types {
+			if d.Type == t {
+				return d, pref == ""
+			}
+		}
+	}
+	return nil, pref == ""
+}
@@ -0,0 +1,49 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func TestChoose(t *testing.T) {
+	devs := []spotify.PlayerDevice{
+		{ID: "tv", Name: "Living Room TV", Type: "TV"},
+		{ID: "locked", Name: "Car", Type: "Smartphone", Restricted: true},
+		{ID: "phone", Name: "Pixel", Type: "Smartphone"},
+		{ID: "laptop", Name: "Laptop", Type: "Computer"},
+	}
+	types := []string{"Computer", "Smartphone"}
+
+	tests := []struct {
+		name      string
+		devs      []spotify.PlayerDevice
+		pref      string
+		last      string
+		want      spotify.ID // "" for none
+		prefFound bool
+	}{
+		{"preferred by ID", devs, "tv", "phone", "tv", true},
+		{"preferred by name, any case", devs, "living room tv", "", "tv", true},
+		{"preferred missing, last used", devs, "Kitchen", "phone", "phone", false},
+		{"preferred restricted", devs, "Car", "", "phone", false},
+		{"no preference, last used", devs, "", "Pixel", "phone", true},
+		{"last used restricted", devs, "", "locked", "phone", true},
+		// The first listed, not the first type
+		{"first of the types", devs, "", "", "phone", true},
+		{"only a later type", devs[3:], "", "", "laptop", true},
+		{"none of the types", devs[:2], "", "", "", true},
+		{"none of the types, preferred missing", devs[:2], "Kitchen", "", "", false},
+		{"no devices", nil, "", "", "", true},
+	}
+	for _, tt := range tests {
+		got, found := Choose(tt.devs, tt.pref, tt.last, types...)
+		var id spotify.ID
+		if got != nil {
+			id = got.ID
+		}
+		if id != tt.want || found != tt.prefFound {
+			t.Errorf("%s: Choose = %q, %v; want %q, %v", tt.name, id, found, tt.want, tt.prefFound)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package graphics
+
+// CellSize returns a common cell size in pixels; the console doesn't
+// report its own.
+func CellSize() (width, height int) {
+	return 10, 20
+}
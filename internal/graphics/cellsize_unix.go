@@ -0,0 +1,19 @@
+//go:build unix
+
+package graphics
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// CellSize returns the size of a terminal cell in pixels, or a common
+// 10x20 when the terminal doesn't report its pixel size.
+func CellSize() (width, height int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Xpixel == 0 || ws.Ypixel == 0 || ws.Col == 0 || ws.Row == 0 {
+		return 10, 20
+	}
+	return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+}
@@ -0,0 +1,119 @@
+// Package graphics detects which inline image protocol the terminal can
+// display, taking terminal multiplexers into account.
+package graphics
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/metolius25/spotirice/internal/platform"
+)
+
+// Protocol is a way of drawing images in the terminal.
+type Protocol int
+
+const (
+	// Blocks draws images with colored half-block characters, which works
+	// everywhere true color does.
+	Blocks Protocol = iota
+	// Kitty is the kitty graphics protocol (kitty, WezTerm, Ghostty).
+	Kitty
+	// ITerm2 is iTerm2's inline image protocol (iTerm2, WezTerm).
+	ITerm2
+	// Sixel is the DEC sixel format (foot, mlterm, contour, xterm -ti vt340).
+	Sixel
+)
+
+// Parse turns a protocol name from the config ("kitty", "iterm2", "sixel",
+// "blocks") into a Protocol; "auto" and "" detect it.
+func Parse(name string) (Protocol, error) {
+	switch name {
+	case "", "auto":
+		return Detect(), nil
+	case "kitty":
+		return Kitty, nil
+	case "iterm2":
+		return ITerm2, nil
+	case "sixel":
+		return Sixel, nil
+	case "blocks":
+		return Blocks, nil
+	}
+	return Blocks, fmt.Errorf("unknown image protocol %q", name)
+}
+
+// Multiplexer names the terminal multiplexer we run inside: "tmux",
+// "screen" or "".
+func Multiplexer() string {
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "tmux"
+	case os.Getenv("STY") != "", strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		return "screen"
+	}
+	return ""
+}
+
+// Detect picks the best protocol for the current terminal. Inside tmux image
+// protocols are only used when passthrough is enabled; screen's passthrough
+// is too limited for image data, so it always gets block art. Over SSH,
+// image data is too slow and unreliable to be worth it.
+func Detect() Protocol {
+	if platform.Remote() {
+		return Blocks
+	}
+	p := outerProtocol()
+	if p == Blocks {
+		return p
+	}
+	switch Multiplexer() {
+	case "tmux":
+		if !tmuxPassthrough() {
+			return Blocks
+		}
+	case "screen":
+		return Blocks
+	}
+	return p
+}
+
+// outerProtocol guesses the protocol of the terminal emulator itself. The
+// variables used are inherited by multiplexer sessions started from it.
+func outerProtocol() Protocol {
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", os.Getenv("TERM") == "xterm-kitty",
+		os.Getenv("GHOSTTY_RESOURCES_DIR") != "":
+		return Kitty
+	case os.Getenv("LC_TERMINAL") == "iTerm2", os.Getenv("TERM_PROGRAM") == "iTerm.app",
+		os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return ITerm2
+	case os.Getenv("TERM") == "foot", strings.HasPrefix(os.Getenv("TERM"), "foot-"),
+		os.Getenv("TERM") == "mlterm", strings.HasPrefix(os.Getenv("TERM"), "contour"):
+		return Sixel
+	}
+	return Blocks
+}
+
+// tmuxPassthrough reports whether tmux forwards escape sequences wrapped
+// with Wrap (tmux 3.3+ needs "set -g allow-passthrough on").
+func tmuxPassthrough() bool {
+	out, err := exec.Command("tmux", "show", "-gv", "allow-passthrough").Output()
+	if err != nil {
+		// Older tmux versions pass everything through
+		return true
+	}
+	v := strings.TrimSpace(string(out))
+	return v == "on" || v == "all"
+}
+
+// Wrap prepares an image escape sequence for output, wrapping it in tmux's
+// passthrough sequence when running inside tmux.
+func Wrap(seq string) string {
+	if Multiplexer() != "tmux" {
+		return seq
+	}
+	// Every ESC inside the payload has to be doubled
+	return "\033Ptmux;" + strings.ReplaceAll(seq, "\033", "\033\033") + "\033\\"
+}
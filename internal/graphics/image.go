@@ -0,0 +1,214 @@
+package graphics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// kittyImageID is the ID of the one image Spotirice shows with the kitty
+// protocol; sending a new one under it replaces the old one.
+const kittyImageID = 7411
+
+// Render draws img into a box of cols x rows cells and returns its lines,
+// each cols cells wide. With an image protocol the lines are blank and the
+// last one carries the image: it moves the cursor up to the box's first line
+// and back, so the image is drawn after the blank lines above it have been
+// written rather than being overwritten by them.
+func Render(p Protocol, img image.Image, cols, rows int) []string {
+	if cols <= 0 || rows <= 0 {
+		return nil
+	}
+	if p == Blocks {
+		return halfBlocks(img, cols, rows)
+	}
+
+	cw, ch := CellSize()
+	scaled := scale(img, cols*cw, rows*ch)
+	var seq string
+	switch p {
+	case Kitty:
+		seq = kitty(scaled, cols, rows)
+	case ITerm2:
+		seq = iterm2(scaled, cols, rows)
+	case Sixel:
+		seq = Wrap(sixel(scaled))
+	}
+
+	blank := strings.Repeat(" ", cols)
+	lines := make([]string, rows)
+	for i := range lines {
+		lines[i] = blank
+	}
+	up := ""
+	if rows > 1 {
+		up = fmt.Sprintf("\033[%dA", rows-1)
+	}
+	lines[rows-1] = "\0337" + up + seq + "\0338" + blank
+	return lines
+}
+
+// ClearKitty removes the image drawn by Render with the kitty protocol,
+// which would otherwise stay on screen under whatever is drawn next.
+func ClearKitty() string {
+	return Wrap(fmt.Sprintf("\033_Ga=d,d=I,i=%d,q=2\033\\", kittyImageID))
+}
+
+// scale resizes img to w x h pixels, averaging the source pixels that fall
+// into each target pixel.
+func scale(img image.Image, w, h int) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := img.Bounds()
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(b.Min.X+(x+1)*b.Dx()/w, x0+1)
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, _ := img.At(sx, sy).RGBA()
+					r, g, bl, n = r+cr, g+cg, bl+cb, n+1
+				}
+			}
+			out.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(bl / n >> 8), 0xff})
+		}
+	}
+	return out
+}
+
+// halfBlocks draws two pixels per cell: the upper one as the foreground of
+// "▀", the lower one as its background.
+func halfBlocks(img image.Image, cols, rows int) []string {
+	px := scale(img, cols, rows*2)
+	hex := func(c color.RGBA) lipgloss.Color {
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+	lines := make([]string, rows)
+	for y := 0; y < rows; y++ {
+		var sb strings.Builder
+		for x := 0; x < cols; x++ {
+			sb.WriteString(lipgloss.NewStyle().
+				Foreground(hex(px.RGBAAt(x, 2*y))).
+				Background(hex(px.RGBAAt(x, 2*y+1))).
+				Render("▀"))
+		}
+		lines[y] = sb.String()
+	}
+	return lines
+}
+
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// kitty transmits and places the image in one go, in chunks of at most
+// 4096 bytes of base64 as the protocol requires. C=1 leaves the cursor
+// where it was.
+func kitty(img image.Image, cols, rows int) string {
+	data := base64.StdEncoding.EncodeToString(encodePNG(img))
+	var sb strings.Builder
+	for first := true; first || data != ""; first = false {
+		chunk := data[:min(len(data), 4096)]
+		data = data[len(chunk):]
+		more := 0
+		if data != "" {
+			more = 1
+		}
+		if first {
+			sb.WriteString(Wrap(fmt.Sprintf("\033_Ga=T,f=100,i=%d,c=%d,r=%d,C=1,q=2,m=%d;%s\033\\", kittyImageID, cols, rows, more, chunk)))
+		} else {
+			sb.WriteString(Wrap(fmt.Sprintf("\033_Gm=%d;%s\033\\", more, chunk)))
+		}
+	}
+	return sb.String()
+}
+
+func iterm2(img image.Image, cols, rows int) string {
+	data := encodePNG(img)
+	return Wrap(fmt.Sprintf("\033]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:%s\a",
+		len(data), cols, rows, base64.StdEncoding.EncodeToString(data)))
+}
+
+// sixel encodes img with a 6x6x6 color cube, which is plenty for cover art
+// at terminal sizes.
+func sixel(img *image.RGBA) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	level := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	index := make([]int, w*h)
+	used := make([]bool, 216)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			i := level(c.R)*36 + level(c.G)*6 + level(c.B)
+			index[y*w+x] = i
+			used[i] = true
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\033P0;1q\"1;1;%d;%d", w, h)
+	for i, ok := range used {
+		if ok {
+			fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+		}
+	}
+	row := make([]byte, w)
+	for band := 0; band < h; band += 6 {
+		first := true
+		for c := range used {
+			if !used[c] {
+				continue
+			}
+			present := false
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < h; dy++ {
+					if index[(band+dy)*w+x] == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = 63 + bits
+				present = present || bits != 0
+			}
+			if !present {
+				continue
+			}
+			if !first {
+				sb.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&sb, "#%d", c)
+			writeRuns(&sb, row)
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\033\\")
+	return sb.String()
+}
+
+// writeRuns writes sixel data with runs of the same character compressed.
+func writeRuns(sb *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, row[i])
+		} else {
+			sb.Write(row[i:j])
+		}
+		i = j
+	}
+}
@@ -0,0 +1,156 @@
+// Package keyboard turns on the kitty keyboard protocol where the terminal
+// supports it and translates its key events for bubbletea, which only
+// parses legacy sequences.
+package keyboard
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// push asks for "disambiguate escape codes" (flag 1): keys that legacy
+	// encoding merges, like Shift+Space and Space, arrive as CSI u. With
+	// "report alternate keys" (flag 4) they carry the shifted key too, so
+	// Ctrl+Shift+= can be told from Ctrl+=.
+	// Terminals without the protocol ignore it and keep sending legacy keys.
+	push = "\x1b[>5u"
+	// Disable pops the mode again; print it once the program has exited.
+	Disable = "\x1b[<u"
+)
+
+// Enable is a tea.Cmd that turns the protocol on. It has to run after the
+// program has entered the alternate screen, which has its own mode stack.
+func Enable() tea.Msg {
+	_, _ = os.Stdout.WriteString(push)
+	return nil
+}
+
+// ShiftSpaceMsg is Shift+Space, which legacy terminals send as a plain space.
+type ShiftSpaceMsg struct{}
+
+// KeyMsg is a key with modifiers tea.KeyMsg has no room for, like
+// Shift+Enter or Ctrl+Backspace. Legacy is the key a legacy terminal would
+// have sent, for whatever doesn't tell the two apart.
+type KeyMsg struct {
+	Legacy tea.KeyMsg
+	name   string
+}
+
+// String names the key as bubbletea would, with the modifiers in the order
+// alt, ctrl, shift: "shift+enter", "alt+ctrl+backspace".
+func (k KeyMsg) String() string {
+	return k.name
+}
+
+// Translate converts a kitty CSI u key event into the tea.KeyMsg a legacy
+// terminal would have produced, or into ShiftSpaceMsg or KeyMsg where that
+// would lose a modifier. ok is false for any other message.
+func Translate(msg tea.Msg) (tea.Msg, bool) {
+	// bubbletea passes sequences it doesn't know on as an unexported type
+	// that prints as ?CSI[<bytes after ESC [>]?
+	s, isStringer := msg.(fmt.Stringer)
+	if !isStringer {
+		return nil, false
+	}
+	seq, found := strings.CutPrefix(s.String(), "?CSI[")
+	if !found || !strings.HasSuffix(seq, "]?") {
+		return nil, false
+	}
+	var b []byte
+	for _, f := range strings.Fields(strings.TrimSuffix(seq, "]?")) {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, false
+		}
+		b = append(b, byte(n))
+	}
+	body, isKey := strings.CutSuffix(string(b), "u")
+	if !isKey {
+		return nil, false
+	}
+	return parseKey(body)
+}
+
+// parseKey decodes "code[:shifted[:base]][;mods[:event][;text]]".
+func parseKey(body string) (tea.Msg, bool) {
+	fields := strings.Split(body, ";")
+	codes := strings.Split(fields[0], ":")
+	code, err := strconv.Atoi(codes[0])
+	if err != nil {
+		return nil, false
+	}
+	mods := 1
+	if len(fields) > 1 {
+		if mods, err = strconv.Atoi(strings.Split(fields[1], ":")[0]); err != nil {
+			return nil, false
+		}
+	}
+	bits := mods - 1
+	shift, alt, ctrl := bits&1 != 0, bits&2 != 0, bits&4 != 0
+
+	var legacy tea.KeyMsg
+	var base string
+	switch code {
+	case 27:
+		legacy, base = tea.KeyMsg{Type: tea.KeyEsc}, "esc"
+	case 13:
+		legacy, base = tea.KeyMsg{Type: tea.KeyEnter}, "enter"
+	case 9:
+		legacy, base = tea.KeyMsg{Type: tea.KeyTab}, "tab"
+		if shift {
+			legacy.Type = tea.KeyShiftTab
+		}
+	case 127:
+		legacy, base = tea.KeyMsg{Type: tea.KeyBackspace}, "backspace"
+	case 32:
+		switch {
+		case ctrl:
+			return tea.KeyMsg{Type: tea.KeyCtrlAt, Alt: alt}, true
+		case shift && !alt:
+			return ShiftSpaceMsg{}, true
+		}
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}, Alt: alt}, true
+	default:
+		if code < 32 || code > unicode.MaxRune {
+			return nil, false
+		}
+		r := rune(code)
+		if ctrl && r >= 'a' && r <= 'z' {
+			legacy, base = tea.KeyMsg{Type: tea.KeyCtrlA + tea.KeyType(r-'a')}, string(r)
+			break
+		}
+		// Shift goes into the character it types: "+" rather than
+		// "shift+="
+		if shift && len(codes) > 1 {
+			if shifted, err := strconv.Atoi(codes[1]); err == nil && shifted >= 32 && shifted <= unicode.MaxRune {
+				r, shift = rune(shifted), false
+			}
+		}
+		if up := unicode.ToUpper(r); shift && up != r {
+			r, shift = up, false
+		}
+		legacy, base = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}, string(r)
+	}
+	legacy.Alt = alt
+
+	name := base
+	if shift {
+		name = "shift+" + name
+	}
+	if ctrl {
+		name = "ctrl+" + name
+	}
+	if alt {
+		name = "alt+" + name
+	}
+	if name == legacy.String() {
+		return legacy, true
+	}
+	return KeyMsg{Legacy: legacy, name: name}, true
+}
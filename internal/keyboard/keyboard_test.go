@@ -0,0 +1,119 @@
+package keyboard
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		body string
+		want string // the message's String, or its type for the others
+	}{
+		{"97", "a"},
+		{"97;2", "A"},
+		{"97:65;2", "A"},
+		{"97;3", "alt+a"},
+		{"97;5", "ctrl+a"},
+		{"97;7", "alt+ctrl+a"},
+		{"97:65;6", "ctrl+shift+a"},
+		{"61", "="},
+		{"61:43;2", "+"},
+		{"61;2", "shift+="},
+		{"61;5", "ctrl+="},
+		{"61:43;6", "ctrl++"},
+		{"61:43;4", "alt++"},
+		{"13", "enter"},
+		{"13;2", "shift+enter"},
+		{"13;3", "alt+enter"},
+		{"13;5", "ctrl+enter"},
+		{"13;6", "ctrl+shift+enter"},
+		{"13;8", "alt+ctrl+shift+enter"},
+		{"27", "esc"},
+		{"27;2", "shift+esc"},
+		{"27;3", "alt+esc"},
+		{"27;5", "ctrl+esc"},
+		{"9", "tab"},
+		{"9;2", "shift+tab"},
+		{"9;4", "alt+shift+tab"},
+		{"9;5", "ctrl+tab"},
+		{"9;6", "ctrl+shift+tab"},
+		{"127", "backspace"},
+		{"127;3", "alt+backspace"},
+		{"127;5", "ctrl+backspace"},
+		{"32", " "},
+		{"32;2", "keyboard.ShiftSpaceMsg"},
+		{"32;4", "alt+ "},
+		{"32;5", "ctrl+@"},
+		{"13;2:1", "shift+enter"}, // press event
+		{"97;1;97", "a"},          // with the text field
+	}
+	for _, tt := range tests {
+		msg, ok := parseKey(tt.body)
+		if !ok {
+			t.Errorf("parseKey(%q) failed", tt.body)
+			continue
+		}
+		got := fmt.Sprintf("%T", msg)
+		if s, isStringer := msg.(fmt.Stringer); isStringer {
+			got = s.String()
+		}
+		if got != tt.want {
+			t.Errorf("parseKey(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeyLegacy(t *testing.T) {
+	// What doesn't know the modifier gets the key without it
+	tests := []struct {
+		body string
+		want tea.KeyMsg
+	}{
+		{"13;2", tea.KeyMsg{Type: tea.KeyEnter}},
+		{"13;6", tea.KeyMsg{Type: tea.KeyEnter}},
+		{"13;4", tea.KeyMsg{Type: tea.KeyEnter, Alt: true}},
+		{"9;6", tea.KeyMsg{Type: tea.KeyShiftTab}},
+		{"127;5", tea.KeyMsg{Type: tea.KeyBackspace}},
+		{"97:65;6", tea.KeyMsg{Type: tea.KeyCtrlA}},
+	}
+	for _, tt := range tests {
+		msg, _ := parseKey(tt.body)
+		key, ok := msg.(KeyMsg)
+		if !ok {
+			t.Errorf("parseKey(%q) = %T, want a KeyMsg", tt.body, msg)
+			continue
+		}
+		if key.Legacy.String() != tt.want.String() {
+			t.Errorf("parseKey(%q).Legacy = %q, want %q", tt.body, key.Legacy, tt.want)
+		}
+	}
+}
+
+func TestParseKeyInvalid(t *testing.T) {
+	for _, body := range []string{"", "x", "13;x", "5"} {
+		if msg, ok := parseKey(body); ok {
+			t.Errorf("parseKey(%q) = %v, want failure", body, msg)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	// bubbletea's unknownCSISequenceMsg for ESC [ 1 3 ; 2 u
+	msg, ok := Translate(unknownCSI("?CSI[49 51 59 50 117]?"))
+	if !ok || msg.(fmt.Stringer).String() != "shift+enter" {
+		t.Errorf("Translate = %v, %v; want shift+enter", msg, ok)
+	}
+	if _, ok := Translate(unknownCSI("?CSI[49 59 50 65]?")); ok {
+		t.Error("Translate took a sequence that isn't a CSI u key")
+	}
+	if _, ok := Translate(tea.KeyMsg{Type: tea.KeyEnter}); ok {
+		t.Error("Translate took a tea.KeyMsg")
+	}
+}
+
+type unknownCSI string
+
+func (s unknownCSI) String() string { return string(s) }
@@ -0,0 +1,142 @@
+// Package lyrics fetches song lyrics from lrclib.net, time-synced where
+// the site has them.
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const baseURL = "https://lrclib.net/api/"
+
+// userAgent identifies Spotirice, as lrclib asks clients to.
+const userAgent = "Spotirice (https://github.com/metolius25/spotirice)"
+
+// Line is one line of synced lyrics.
+type Line struct {
+	AtMs int // when the line starts
+	Text string
+}
+
+// Lyrics are a song's words, Synced when lrclib has timings and Plain
+// otherwise.
+type Lyrics struct {
+	Synced       []Line
+	Plain        []string
+	Instrumental bool
+}
+
+// Song identifies what to look up. lrclib matches on all of it, duration to
+// within a couple of seconds.
+type Song struct {
+	Track      string
+	Artist     string
+	Album      string
+	DurationMs int
+}
+
+type record struct {
+	Instrumental bool   `json:"instrumental"`
+	PlainLyrics  string `json:"plainLyrics"`
+	SyncedLyrics string `json:"syncedLyrics"`
+}
+
+// Fetch looks the song up, first exactly and then by a search on title and
+// artist. It returns nil when lrclib has no lyrics for it.
+func Fetch(ctx context.Context, s Song) (*Lyrics, error) {
+	var exact record
+	found, err := get(ctx, "get", url.Values{
+		"track_name":  {s.Track},
+		"artist_name": {s.Artist},
+		"album_name":  {s.Album},
+		"duration":    {strconv.Itoa(s.DurationMs / 1000)},
+	}, &exact)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return parse(exact), nil
+	}
+
+	var results []record
+	if _, err := get(ctx, "search", url.Values{"track_name": {s.Track}, "artist_name": {s.Artist}}, &results); err != nil {
+		return nil, err
+	}
+	// Prefer a synced match
+	for _, r := range results {
+		if r.SyncedLyrics != "" {
+			return parse(r), nil
+		}
+	}
+	if len(results) > 0 {
+		return parse(results[0]), nil
+	}
+	return nil, nil
+}
+
+// get calls an lrclib endpoint; found is false on a 404.
+func get(ctx context.Context, endpoint string, query url.Values, out interface{}) (found bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("lrclib: %s", resp.Status)
+	}
+	return true, json.NewDecoder(resp.Body).Decode(out)
+}
+
+func parse(r record) *Lyrics {
+	l := &Lyrics{Instrumental: r.Instrumental, Synced: ParseLRC(r.SyncedLyrics)}
+	if plain := strings.TrimSpace(r.PlainLyrics); plain != "" {
+		l.Plain = strings.Split(plain, "\n")
+	}
+	return l
+}
+
+// timeTag matches an LRC time tag such as [01:23.45].
+var timeTag = regexp.MustCompile(`\[(\d+):(\d+(?:\.\d+)?)\]`)
+
+// ParseLRC reads LRC text into lines ordered by time. A line may carry
+// several time tags when it repeats; tags that aren't times ([ar:...]) are
+// skipped.
+func ParseLRC(text string) []Line {
+	var lines []Line
+	for _, raw := range strings.Split(text, "\n") {
+		tags := timeTag.FindAllStringSubmatchIndex(raw, -1)
+		if len(tags) == 0 {
+			continue
+		}
+		words := strings.TrimSpace(raw[tags[len(tags)-1][1]:])
+		for _, t := range tags {
+			mins, _ := strconv.Atoi(raw[t[2]:t[3]])
+			secs, _ := strconv.ParseFloat(raw[t[4]:t[5]], 64)
+			lines = append(lines, Line{AtMs: mins*60000 + int(secs*1000), Text: words})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].AtMs < lines[j].AtMs })
+	return lines
+}
+
+// Current returns the index of the line being sung at progressMs, or -1
+// before the first one.
+func Current(lines []Line, progressMs int) int {
+	return sort.Search(len(lines), func(i int) bool { return lines[i].AtMs > progressMs }) - 1
+}
@@ -0,0 +1,148 @@
+// Package metrics counts what the daemon does and serves it in the
+// Prometheus text format.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// pollPath is the Web API endpoint the player state is polled from.
+const pollPath = "/v1/me/player"
+
+// Registry holds the current values. The zero value is not usable; call New.
+type Registry struct {
+	mu           sync.Mutex
+	requests     map[string]int64 // by HTTP status code, "error" for transport failures
+	rateLimited  int64
+	tracksPlayed int64
+	trackID      string // the last track seen by SetTrack
+	pollLatency  time.Duration
+	playing      bool
+	volume       int
+}
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{requests: make(map[string]int64)}
+}
+
+// Transport wraps base (http.DefaultTransport if nil) so every API request
+// is counted, and polls of the player state are timed.
+func (r *Registry) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{r: r, base: base}
+}
+
+type roundTripper struct {
+	r    *Registry
+	base http.RoundTripper
+}
+
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			t.r.rateLimited++
+		}
+	}
+	t.r.requests[code]++
+	if req.URL.Path == pollPath {
+		t.r.pollLatency = elapsed
+	}
+	return resp, err
+}
+
+// SetTrack records the track playing, counting a track change when id is
+// not the last one seen. An empty id (nothing playing) is not counted.
+func (r *Registry) SetTrack(id string) {
+	r.mu.Lock()
+	if id != "" && id != r.trackID {
+		r.tracksPlayed++
+	}
+	r.trackID = id
+	r.mu.Unlock()
+}
+
+// SetPlayback records the current playback state.
+func (r *Registry) SetPlayback(playing bool, volume int) {
+	r.mu.Lock()
+	r.playing = playing
+	r.volume = volume
+	r.mu.Unlock()
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	metric("spotirice_api_requests_total", "counter", "Web API requests by HTTP status code.")
+	codes := make([]string, 0, len(r.requests))
+	for code := range r.requests {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "spotirice_api_requests_total{code=%q} %d\n", code, r.requests[code])
+	}
+
+	metric("spotirice_api_rate_limited_total", "counter", "Web API requests answered with 429 Too Many Requests.")
+	fmt.Fprintf(w, "spotirice_api_rate_limited_total %d\n", r.rateLimited)
+
+	metric("spotirice_tracks_played_total", "counter", "Track changes seen while running.")
+	fmt.Fprintf(w, "spotirice_tracks_played_total %d\n", r.tracksPlayed)
+
+	metric("spotirice_poll_latency_seconds", "gauge", "Duration of the last player state poll.")
+	fmt.Fprintf(w, "spotirice_poll_latency_seconds %g\n", r.pollLatency.Seconds())
+
+	playing := 0
+	if r.playing {
+		playing = 1
+	}
+	metric("spotirice_playing", "gauge", "1 while playback is running.")
+	fmt.Fprintf(w, "spotirice_playing %d\n", playing)
+
+	metric("spotirice_volume_percent", "gauge", "Volume of the active device.")
+	fmt.Fprintf(w, "spotirice_volume_percent %d\n", r.volume)
+}
+
+// ListenAndServe serves /metrics on addr until ctx is cancelled.
+func (r *Registry) ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.Serve(ln); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
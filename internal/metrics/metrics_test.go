@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetTrack(t *testing.T) {
+	r := New()
+	// Repeats are the same track polled again; "" is nothing playing
+	for _, id := range []string{"a", "a", "b", "", "b", "b", "c"} {
+		r.SetTrack(id)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if want := "spotirice_tracks_played_total 4\n"; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("output lacks %q:\n%s", want, rec.Body.String())
+	}
+}
@@ -0,0 +1,161 @@
+package mock
+
+import (
+	"fmt"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// The canned library: a few made-up artists with an album each, and a
+// playlist mixing them.
+
+type mockArtist struct {
+	id     spotify.ID
+	name   string
+	genres []string
+}
+
+type mockAlbum struct {
+	id       spotify.ID
+	name     string
+	artist   int // index into artists
+	released string
+	tracks   []mockTrack
+}
+
+type mockTrack struct {
+	name       string
+	durationMs int
+	explicit   bool
+}
+
+var artists = []mockArtist{
+	{"mockartist0neonharbor", "Neon Harbor", []string{"synthwave", "retrowave", "chillwave"}},
+	{"mockartist1lanterns", "The Paper Lanterns", []string{"indie folk", "chamber pop"}},
+	{"mockartist2kettledrum", "Kettle Drum Collective", []string{"jazz fusion"}},
+}
+
+var albums = []mockAlbum{
+	{"mockalbum0nightdrive", "Night Drive", 0, "2019-06-14", []mockTrack{
+		{"Coastline at 3 AM", 224000, false},
+		{"Chrome Horizon", 198000, false},
+		{"Arcade Heart", 241000, false},
+		{"Tunnel Lights", 187000, false},
+		{"Last Exit Before Dawn", 305000, false},
+	}},
+	{"mockalbum1lanterns", "Lanterns", 1, "2021-10-01", []mockTrack{
+		{"Paper Boats", 176000, false},
+		{"The Attic Window", 213000, false},
+		{"Sparrows in November", 199000, true},
+		{"Slow Lantern", 258000, false},
+	}},
+	{"mockalbum2steam", "Steam", 2, "2016", []mockTrack{
+		{"Pressure Valve", 332000, false},
+		{"Copper Kettle Blues", 287000, false},
+		{"Whistle Stop", 244000, false},
+		{"Simmer", 401000, false},
+	}},
+}
+
+const (
+	playlistID   spotify.ID = "mockplaylist0mix"
+	playlistName            = "Mock Mix"
+)
+
+// playlistTracks index the catalog's tracks, numbered across albums in order.
+var playlistTracks = []int{0, 5, 9, 2, 7, 11, 4}
+
+// library holds the canned catalog as the API types, built once.
+type library struct {
+	tracks  []spotify.FullTrack
+	byID    map[spotify.ID]int
+	artists []spotify.FullArtist
+	albums  []spotify.FullAlbum
+}
+
+func trackURI(id spotify.ID) spotify.URI  { return spotify.URI("spotify:track:" + id) }
+func artistURI(id spotify.ID) spotify.URI { return spotify.URI("spotify:artist:" + id) }
+func albumURI(id spotify.ID) spotify.URI  { return spotify.URI("spotify:album:" + id) }
+
+func newLibrary() *library {
+	lib := &library{byID: make(map[spotify.ID]int)}
+	for _, a := range artists {
+		full := spotify.FullArtist{Genres: a.genres}
+		full.ID = a.id
+		full.Name = a.name
+		full.URI = artistURI(a.id)
+		lib.artists = append(lib.artists, full)
+	}
+	for _, al := range albums {
+		simple := spotify.SimpleAlbum{
+			Name:        al.name,
+			ID:          al.id,
+			URI:         albumURI(al.id),
+			AlbumType:   "album",
+			ReleaseDate: al.released,
+			Artists:     []spotify.SimpleArtist{lib.artists[al.artist].SimpleArtist},
+		}
+		full := spotify.FullAlbum{SimpleAlbum: simple}
+		for n, t := range al.tracks {
+			id := spotify.ID(fmt.Sprintf("mocktrack%02d", len(lib.tracks)))
+			track := spotify.FullTrack{Album: simple, Popularity: spotify.Numeric(60 - len(lib.tracks))}
+			track.ID = id
+			track.Name = t.name
+			track.URI = trackURI(id)
+			track.Type = "track"
+			track.Duration = spotify.Numeric(t.durationMs)
+			track.Explicit = t.explicit
+			track.TrackNumber = spotify.Numeric(n + 1)
+			track.DiscNumber = 1
+			track.Artists = simple.Artists
+			track.Album = simple
+			lib.byID[id] = len(lib.tracks)
+			lib.tracks = append(lib.tracks, track)
+			full.Tracks.Tracks = append(full.Tracks.Tracks, track.SimpleTrack)
+		}
+		full.Tracks.Total = spotify.Numeric(len(full.Tracks.Tracks))
+		lib.albums = append(lib.albums, full)
+	}
+	return lib
+}
+
+func (lib *library) track(id spotify.ID) (spotify.FullTrack, bool) {
+	i, ok := lib.byID[id]
+	if !ok {
+		return spotify.FullTrack{}, false
+	}
+	return lib.tracks[i], true
+}
+
+// contextTracks lists the track IDs of an album or artist in play order.
+func (lib *library) contextTracks(uri spotify.URI) []spotify.ID {
+	var ids []spotify.ID
+	for _, t := range lib.tracks {
+		if t.Album.URI == uri || t.Artists[0].URI == uri {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids
+}
+
+// features derives stable, plausible audio features from a track's place in
+// the catalog.
+func (lib *library) features(id spotify.ID) *spotify.AudioFeatures {
+	i, ok := lib.byID[id]
+	if !ok {
+		return nil
+	}
+	t := lib.tracks[i]
+	return &spotify.AudioFeatures{
+		ID:            id,
+		URI:           t.URI,
+		Tempo:         float32(84 + (i*23)%80),
+		Key:           spotify.Numeric((i * 5) % 12),
+		Mode:          spotify.Numeric(i % 2),
+		TimeSignature: 4,
+		Energy:        float32(30+(i*37)%65) / 100,
+		Danceability:  float32(35+(i*29)%60) / 100,
+		Valence:       float32(20+(i*41)%75) / 100,
+		Duration:      t.Duration,
+	}
+}
@@ -0,0 +1,133 @@
+// Package mock is a stand-in for the Spotify Web API: a small canned
+// library and a simulated player, served in-process so the whole interface
+// can run without credentials or network (spotirice --mock) and be driven
+// end to end in tests.
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+)
+
+// Server answers the Web API requests Spotirice makes from canned data. It
+// is safe for concurrent use.
+type Server struct {
+	mu        sync.Mutex
+	lib       *library
+	player    player
+	liked     map[spotify.ID]bool
+	playlists []*playlist
+	mux       *http.ServeMux
+	now       func() time.Time
+}
+
+// playlist is one of the user's playlists; the canned one can be edited and
+// more can be created.
+type playlist struct {
+	id          spotify.ID
+	name        string
+	description string
+	public      bool
+	tracks      []spotify.ID
+}
+
+// New returns a server with the canned library loaded, a track from it
+// playing on the first of two devices.
+func New() *Server {
+	s := &Server{
+		lib:   newLibrary(),
+		liked: make(map[spotify.ID]bool),
+		now:   time.Now,
+	}
+	mix := &playlist{id: playlistID, name: playlistName, description: "Canned tracks for mock mode"}
+	for _, i := range playlistTracks {
+		mix.tracks = append(mix.tracks, s.lib.tracks[i].ID)
+	}
+	s.playlists = []*playlist{mix}
+	s.liked[s.lib.tracks[1].ID] = true
+	s.liked[s.lib.tracks[6].ID] = true
+
+	s.player = newPlayer(s.lib)
+	s.player.play(s.contextTracks(albumURI(albums[0].id)), albumURI(albums[0].id), 0, 42000, s.now())
+
+	s.routes()
+	return s
+}
+
+// Transport is a RoundTripper that hands every request to the server,
+// whatever its host, without touching the network.
+func (s *Server) Transport() http.RoundTripper {
+	return roundTripper{s}
+}
+
+type roundTripper struct{ h http.Handler }
+
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	rt.h.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// Client is a spotify client talking to the server. Its token never
+// expires, so it is never refreshed.
+func (s *Server) Client() *spotify.Client {
+	return spotify.New(&http.Client{Transport: &oauth2.Transport{
+		Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "mock", TokenType: "Bearer"}),
+		Base:   s.Transport(),
+	}})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.player.sync(s.now())
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers in the Web API's error format, which the spotify
+// client turns into a spotify.Error.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"status": status, "message": message},
+	})
+}
+
+// page wraps items like the API's paging objects. There is never a next
+// page: the canned lists are short.
+func page(items interface{}, total int) map[string]interface{} {
+	return map[string]interface{}{"items": items, "total": total, "limit": 50, "offset": 0}
+}
+
+// contextTracks lists the tracks of an album, artist or playlist URI in play
+// order.
+func (s *Server) contextTracks(uri spotify.URI) []spotify.ID {
+	for _, pl := range s.playlists {
+		if uri == spotify.URI("spotify:playlist:"+pl.id) {
+			return append([]spotify.ID(nil), pl.tracks...)
+		}
+	}
+	return s.lib.contextTracks(uri)
+}
+
+func (s *Server) findPlaylist(id spotify.ID) *playlist {
+	for _, pl := range s.playlists {
+		if pl.id == id {
+			return pl
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package mock
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+type device struct {
+	id             spotify.ID
+	name           string
+	kind           string
+	volume         int
+	supportsVolume bool
+}
+
+// player simulates playback: progress advances with the clock and tracks
+// run into each other through the user queue and the context.
+type player struct {
+	lib     *library
+	devices []device
+	active  int // index into devices, -1 when none is active
+
+	context spotify.URI
+	order   []spotify.ID // tracks of the context, or the URIs asked for
+	index   int          // position in order of the last context track
+	current spotify.ID   // may come from the queue instead of order
+	queue   []spotify.ID
+
+	playing  bool
+	progress int // ms, as of since
+	since    time.Time
+	shuffle  bool
+	repeat   string
+}
+
+func newPlayer(lib *library) player {
+	return player{
+		lib: lib,
+		devices: []device{
+			{"mockdevice0speaker", "Mock Speaker", "Computer", 60, true},
+			{"mockdevice1phone", "Mock Phone", "Smartphone", 80, false},
+		},
+		active: -1,
+		repeat: "off",
+	}
+}
+
+func (p *player) duration() int {
+	t, _ := p.lib.track(p.current)
+	return int(t.Duration)
+}
+
+func (p *player) position(now time.Time) int {
+	if !p.playing {
+		return p.progress
+	}
+	return p.progress + int(now.Sub(p.since).Milliseconds())
+}
+
+// setPosition pins progress to ms as of now.
+func (p *player) setPosition(ms int, now time.Time) {
+	p.progress = ms
+	p.since = now
+}
+
+// sync plays through the tracks that have ended since the last request.
+func (p *player) sync(now time.Time) {
+	for p.playing && p.current != "" {
+		over := p.position(now) - p.duration()
+		if over < 0 {
+			return
+		}
+		ended := now.Add(-time.Duration(over) * time.Millisecond)
+		if p.repeat == "track" {
+			p.setPosition(0, ended)
+			continue
+		}
+		p.advance(ended)
+	}
+}
+
+// play starts order at index, position ms in.
+func (p *player) play(order []spotify.ID, context spotify.URI, index, ms int, now time.Time) {
+	if p.active < 0 {
+		p.active = 0
+	}
+	p.order, p.context, p.index = order, context, index
+	p.current = order[index]
+	p.playing = true
+	p.setPosition(ms, now)
+}
+
+// advance moves on to the next track as of now. At the end of the context
+// without repeat, playback stops on its first track like Spotify does.
+func (p *player) advance(now time.Time) {
+	p.setPosition(0, now)
+	if len(p.queue) > 0 {
+		p.current, p.queue = p.queue[0], p.queue[1:]
+		return
+	}
+	switch {
+	case p.shuffle && len(p.order) > 1:
+		next := rand.IntN(len(p.order) - 1)
+		if next >= p.index {
+			next++
+		}
+		p.index = next
+	case p.index+1 < len(p.order):
+		p.index++
+	default:
+		p.index = 0
+		if p.repeat != "context" {
+			p.playing = false
+		}
+	}
+	p.current = p.order[p.index]
+}
+
+// back restarts the track, or goes to the previous one near its start.
+func (p *player) back(now time.Time) {
+	if p.position(now) < 3000 && p.index > 0 {
+		p.index--
+		p.current = p.order[p.index]
+	}
+	p.setPosition(0, now)
+}
+
+// upNext is what the queue endpoint lists after the current track.
+func (p *player) upNext() []spotify.ID {
+	next := append([]spotify.ID(nil), p.queue...)
+	if p.index+1 < len(p.order) {
+		next = append(next, p.order[p.index+1:]...)
+	}
+	return next
+}
@@ -0,0 +1,601 @@
+package mock
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	mockUserID     = "mockuser"
+	noActiveDevice = "Player command failed: No active device found"
+	restrictedVol  = "Player command failed: Cannot control device volume"
+	unsupported    = "Not available in mock mode"
+)
+
+func (s *Server) routes() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/me", s.handleMe)
+
+	mux.HandleFunc("GET /v1/me/player", s.handlePlayerState)
+	mux.HandleFunc("GET /v1/me/player/devices", s.handleDevices)
+	mux.HandleFunc("PUT /v1/me/player", s.handleTransfer)
+	mux.HandleFunc("PUT /v1/me/player/play", s.handlePlay)
+	mux.HandleFunc("PUT /v1/me/player/pause", s.playerCommand(func(r *http.Request) (int, string) {
+		s.player.setPosition(s.player.position(s.now()), s.now())
+		s.player.playing = false
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("POST /v1/me/player/next", s.playerCommand(func(r *http.Request) (int, string) {
+		s.player.advance(s.now())
+		s.player.playing = true
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("POST /v1/me/player/previous", s.playerCommand(func(r *http.Request) (int, string) {
+		s.player.back(s.now())
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("PUT /v1/me/player/seek", s.playerCommand(func(r *http.Request) (int, string) {
+		ms, err := strconv.Atoi(r.URL.Query().Get("position_ms"))
+		if err != nil || ms < 0 {
+			return http.StatusBadRequest, "Invalid position_ms"
+		}
+		s.player.setPosition(min(ms, s.player.duration()), s.now())
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("PUT /v1/me/player/volume", s.playerCommand(func(r *http.Request) (int, string) {
+		vol, err := strconv.Atoi(r.URL.Query().Get("volume_percent"))
+		if err != nil || vol < 0 || vol > 100 {
+			return http.StatusBadRequest, "Invalid volume_percent"
+		}
+		d := &s.player.devices[s.player.active]
+		if !d.supportsVolume {
+			return http.StatusForbidden, restrictedVol
+		}
+		d.volume = vol
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("PUT /v1/me/player/shuffle", s.playerCommand(func(r *http.Request) (int, string) {
+		s.player.shuffle = r.URL.Query().Get("state") == "true"
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("PUT /v1/me/player/repeat", s.playerCommand(func(r *http.Request) (int, string) {
+		state := r.URL.Query().Get("state")
+		if state != "off" && state != "track" && state != "context" {
+			return http.StatusBadRequest, "Invalid state"
+		}
+		s.player.repeat = state
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("POST /v1/me/player/queue", s.playerCommand(func(r *http.Request) (int, string) {
+		id, ok := s.trackFromURI(spotify.URI(r.URL.Query().Get("uri")))
+		if !ok {
+			return http.StatusBadRequest, "Invalid track uri"
+		}
+		s.player.queue = append(s.player.queue, id)
+		return http.StatusNoContent, ""
+	}))
+	mux.HandleFunc("GET /v1/me/player/queue", s.handleQueue)
+
+	mux.HandleFunc("GET /v1/me/tracks", s.handleSavedTracks)
+	mux.HandleFunc("GET /v1/me/tracks/contains", s.handleTracksContain)
+	mux.HandleFunc("PUT /v1/me/tracks", s.handleSaveTracks(true))
+	mux.HandleFunc("DELETE /v1/me/tracks", s.handleSaveTracks(false))
+	mux.HandleFunc("GET /v1/me/top/tracks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, page(s.lib.tracks, len(s.lib.tracks)))
+	})
+	mux.HandleFunc("GET /v1/me/top/artists", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, page(s.lib.artists, len(s.lib.artists)))
+	})
+	mux.HandleFunc("GET /v1/me/episodes", emptyPage)
+	mux.HandleFunc("GET /v1/me/audiobooks", emptyPage)
+
+	mux.HandleFunc("GET /v1/me/playlists", s.handlePlaylists)
+	mux.HandleFunc("POST /v1/users/{user}/playlists", s.handleCreatePlaylist)
+	mux.HandleFunc("GET /v1/playlists/{id}", s.handlePlaylist)
+	mux.HandleFunc("PUT /v1/playlists/{id}", s.handleEditPlaylist)
+	mux.HandleFunc("GET /v1/playlists/{id}/tracks", s.handlePlaylistTracks)
+	mux.HandleFunc("POST /v1/playlists/{id}/tracks", s.handleAddToPlaylist)
+
+	mux.HandleFunc("GET /v1/search", s.handleSearch)
+	mux.HandleFunc("GET /v1/tracks", s.handleTracks)
+	mux.HandleFunc("GET /v1/tracks/{id}", s.handleTrack)
+	mux.HandleFunc("GET /v1/albums/{id}", s.handleAlbum)
+	mux.HandleFunc("GET /v1/artists/{id}", s.handleArtist)
+	mux.HandleFunc("GET /v1/artists/{id}/top-tracks", s.handleArtistTopTracks)
+	mux.HandleFunc("GET /v1/audio-features", s.handleAudioFeatures)
+	mux.HandleFunc("GET /v1/audio-analysis/{id}", s.handleAudioAnalysis)
+	mux.HandleFunc("GET /v1/recommendations", s.handleRecommendations)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, unsupported)
+	})
+	s.mux = mux
+}
+
+func emptyPage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, page([]struct{}{}, 0))
+}
+
+// playerCommand runs fn against the active device, honoring device_id like
+// the real endpoints. fn returns the status to answer with and, for errors,
+// a message.
+func (s *Server) playerCommand(fn func(r *http.Request) (int, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.selectDevice(r) {
+			writeError(w, http.StatusNotFound, noActiveDevice)
+			return
+		}
+		status, msg := fn(r)
+		if msg != "" {
+			writeError(w, status, msg)
+			return
+		}
+		w.WriteHeader(status)
+	}
+}
+
+// selectDevice makes the device_id parameter's device active, if given, and
+// reports whether a device is active afterwards.
+func (s *Server) selectDevice(r *http.Request) bool {
+	if id := r.URL.Query().Get("device_id"); id != "" {
+		for i, d := range s.player.devices {
+			if string(d.id) == id {
+				s.player.active = i
+			}
+		}
+	}
+	return s.player.active >= 0 && s.player.current != ""
+}
+
+func (s *Server) trackFromURI(uri spotify.URI) (spotify.ID, bool) {
+	id, ok := strings.CutPrefix(string(uri), "spotify:track:")
+	if !ok {
+		return "", false
+	}
+	_, known := s.lib.track(spotify.ID(id))
+	return spotify.ID(id), known
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":           mockUserID,
+		"display_name": "Mock User",
+		"product":      "premium",
+		"country":      "US",
+		"uri":          "spotify:user:" + mockUserID,
+	})
+}
+
+func deviceJSON(d device, active bool) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              d.id,
+		"name":            d.name,
+		"type":            d.kind,
+		"is_active":       active,
+		"is_restricted":   false,
+		"volume_percent":  d.volume,
+		"supports_volume": d.supportsVolume,
+	}
+}
+
+func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
+	var devs []map[string]interface{}
+	for i, d := range s.player.devices {
+		devs = append(devs, deviceJSON(d, i == s.player.active))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"devices": devs})
+}
+
+func (s *Server) handlePlayerState(w http.ResponseWriter, r *http.Request) {
+	p := &s.player
+	if p.active < 0 || p.current == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	track, _ := s.lib.track(p.current)
+	var context interface{}
+	if p.context != "" {
+		kind := strings.Split(string(p.context), ":")[1]
+		context = map[string]interface{}{"uri": p.context, "type": kind}
+	}
+	now := s.now()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"device":                 deviceJSON(p.devices[p.active], true),
+		"shuffle_state":          p.shuffle,
+		"repeat_state":           p.repeat,
+		"timestamp":              now.UnixMilli(),
+		"context":                context,
+		"progress_ms":            p.position(now),
+		"is_playing":             p.playing,
+		"item":                   track,
+		"currently_playing_type": "track",
+	})
+}
+
+func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		DeviceIDs []spotify.ID `json:"device_ids"`
+		Play      bool         `json:"play"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.DeviceIDs) != 1 {
+		writeError(w, http.StatusBadRequest, "Exactly one device_id is required")
+		return
+	}
+	for i, d := range s.player.devices {
+		if d.id == body.DeviceIDs[0] {
+			s.player.active = i
+			if body.Play && s.player.current != "" && !s.player.playing {
+				s.player.playing = true
+				s.player.since = s.now()
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Device not found")
+}
+
+func (s *Server) handlePlay(w http.ResponseWriter, r *http.Request) {
+	p := &s.player
+	var body struct {
+		ContextURI spotify.URI   `json:"context_uri"`
+		URIs       []spotify.URI `json:"uris"`
+		Offset     *struct {
+			Position *int        `json:"position"`
+			URI      spotify.URI `json:"uri"`
+		} `json:"offset"`
+		PositionMs int `json:"position_ms"`
+	}
+	// A missing body means resume
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	s.selectDevice(r)
+	if p.active < 0 {
+		writeError(w, http.StatusNotFound, noActiveDevice)
+		return
+	}
+
+	now := s.now()
+	var order []spotify.ID
+	switch {
+	case body.ContextURI != "":
+		order = s.contextTracks(body.ContextURI)
+	case len(body.URIs) > 0:
+		for _, uri := range body.URIs {
+			if id, ok := s.trackFromURI(uri); ok {
+				order = append(order, id)
+			}
+		}
+	default:
+		if p.current == "" {
+			writeError(w, http.StatusNotFound, noActiveDevice)
+			return
+		}
+		if !p.playing {
+			p.playing = true
+			p.since = now
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if len(order) == 0 {
+		writeError(w, http.StatusBadRequest, unsupported)
+		return
+	}
+
+	index := 0
+	if o := body.Offset; o != nil {
+		if o.Position != nil && *o.Position < len(order) {
+			index = *o.Position
+		}
+		for i, id := range order {
+			if trackURI(id) == o.URI {
+				index = i
+			}
+		}
+	}
+	p.play(order, body.ContextURI, index, body.PositionMs, now)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	var current interface{}
+	if t, ok := s.lib.track(s.player.current); ok {
+		current = t
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"currently_playing": current,
+		"queue":             s.tracks(s.player.upNext()),
+	})
+}
+
+// tracks looks up known IDs as full tracks, in order.
+func (s *Server) tracks(ids []spotify.ID) []spotify.FullTrack {
+	out := []spotify.FullTrack{}
+	for _, id := range ids {
+		if t, ok := s.lib.track(id); ok {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// savedItems wraps tracks the way library and playlist pages list them.
+func (s *Server) savedItems(ids []spotify.ID) []map[string]interface{} {
+	items := []map[string]interface{}{}
+	for _, t := range s.tracks(ids) {
+		items = append(items, map[string]interface{}{"added_at": "2024-01-01T00:00:00Z", "track": t})
+	}
+	return items
+}
+
+func (s *Server) handleSavedTracks(w http.ResponseWriter, r *http.Request) {
+	var ids []spotify.ID
+	for _, t := range s.lib.tracks {
+		if s.liked[t.ID] {
+			ids = append(ids, t.ID)
+		}
+	}
+	items := s.savedItems(ids)
+	writeJSON(w, http.StatusOK, page(items, len(items)))
+}
+
+func queryIDs(r *http.Request) []spotify.ID {
+	var ids []spotify.ID
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id != "" {
+			ids = append(ids, spotify.ID(id))
+		}
+	}
+	return ids
+}
+
+func (s *Server) handleTracksContain(w http.ResponseWriter, r *http.Request) {
+	contains := []bool{}
+	for _, id := range queryIDs(r) {
+		contains = append(contains, s.liked[id])
+	}
+	writeJSON(w, http.StatusOK, contains)
+}
+
+func (s *Server) handleSaveTracks(save bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, id := range queryIDs(r) {
+			if save {
+				s.liked[id] = true
+			} else {
+				delete(s.liked, id)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func playlistJSON(pl *playlist, full bool, items interface{}) map[string]interface{} {
+	tracks := map[string]interface{}{"total": len(pl.tracks)}
+	if full {
+		tracks = page(items, len(pl.tracks))
+	}
+	return map[string]interface{}{
+		"id":            pl.id,
+		"name":          pl.name,
+		"description":   pl.description,
+		"public":        pl.public,
+		"collaborative": false,
+		"uri":           "spotify:playlist:" + pl.id,
+		"snapshot_id":   "mock",
+		"owner":         map[string]interface{}{"id": mockUserID, "display_name": "Mock User"},
+		"tracks":        tracks,
+	}
+}
+
+func (s *Server) handlePlaylists(w http.ResponseWriter, r *http.Request) {
+	var items []map[string]interface{}
+	for _, pl := range s.playlists {
+		items = append(items, playlistJSON(pl, false, nil))
+	}
+	writeJSON(w, http.StatusOK, page(items, len(items)))
+}
+
+func (s *Server) handlePlaylist(w http.ResponseWriter, r *http.Request) {
+	pl := s.findPlaylist(spotify.ID(r.PathValue("id")))
+	if pl == nil {
+		writeError(w, http.StatusNotFound, "Playlist not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, playlistJSON(pl, true, s.savedItems(pl.tracks)))
+}
+
+func (s *Server) handlePlaylistTracks(w http.ResponseWriter, r *http.Request) {
+	pl := s.findPlaylist(spotify.ID(r.PathValue("id")))
+	if pl == nil {
+		writeError(w, http.StatusNotFound, "Playlist not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, page(s.savedItems(pl.tracks), len(pl.tracks)))
+}
+
+func (s *Server) handleAddToPlaylist(w http.ResponseWriter, r *http.Request) {
+	pl := s.findPlaylist(spotify.ID(r.PathValue("id")))
+	if pl == nil {
+		writeError(w, http.StatusNotFound, "Playlist not found")
+		return
+	}
+	var body struct {
+		URIs []spotify.URI `json:"uris"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	for _, uri := range body.URIs {
+		if id, ok := s.trackFromURI(uri); ok {
+			pl.tracks = append(pl.tracks, id)
+		}
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"snapshot_id": "mock"})
+}
+
+type playlistDetails struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Public      *bool   `json:"public"`
+}
+
+func (d playlistDetails) apply(pl *playlist) {
+	if d.Name != nil {
+		pl.name = *d.Name
+	}
+	if d.Description != nil {
+		pl.description = *d.Description
+	}
+	if d.Public != nil {
+		pl.public = *d.Public
+	}
+}
+
+func (s *Server) handleEditPlaylist(w http.ResponseWriter, r *http.Request) {
+	pl := s.findPlaylist(spotify.ID(r.PathValue("id")))
+	if pl == nil {
+		writeError(w, http.StatusNotFound, "Playlist not found")
+		return
+	}
+	var body playlistDetails
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	body.apply(pl)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleCreatePlaylist(w http.ResponseWriter, r *http.Request) {
+	var body playlistDetails
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == nil {
+		writeError(w, http.StatusBadRequest, "Missing playlist name")
+		return
+	}
+	pl := &playlist{id: spotify.ID("mockplaylist" + strconv.Itoa(len(s.playlists)))}
+	body.apply(pl)
+	s.playlists = append(s.playlists, pl)
+	writeJSON(w, http.StatusCreated, playlistJSON(pl, true, []struct{}{}))
+}
+
+func (s *Server) handleTracks(w http.ResponseWriter, r *http.Request) {
+	var tracks []interface{}
+	for _, id := range queryIDs(r) {
+		if t, ok := s.lib.track(id); ok {
+			tracks = append(tracks, t)
+		} else {
+			tracks = append(tracks, nil)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tracks": tracks})
+}
+
+func (s *Server) handleTrack(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lib.track(spotify.ID(r.PathValue("id")))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Track not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) handleAlbum(w http.ResponseWriter, r *http.Request) {
+	for _, al := range s.lib.albums {
+		if al.ID == spotify.ID(r.PathValue("id")) {
+			writeJSON(w, http.StatusOK, al)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Album not found")
+}
+
+func (s *Server) findArtist(id spotify.ID) (spotify.FullArtist, bool) {
+	for _, a := range s.lib.artists {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return spotify.FullArtist{}, false
+}
+
+func (s *Server) handleArtist(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.findArtist(spotify.ID(r.PathValue("id")))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Artist not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+func (s *Server) handleArtistTopTracks(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.findArtist(spotify.ID(r.PathValue("id")))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Artist not found")
+		return
+	}
+	tracks := s.tracks(s.lib.contextTracks(a.URI))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tracks": tracks})
+}
+
+func (s *Server) handleAudioFeatures(w http.ResponseWriter, r *http.Request) {
+	var features []*spotify.AudioFeatures
+	for _, id := range queryIDs(r) {
+		features = append(features, s.lib.features(id))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"audio_features": features})
+}
+
+// handleAudioAnalysis lays bars and beats out evenly at the track's tempo.
+func (s *Server) handleAudioAnalysis(w http.ResponseWriter, r *http.Request) {
+	f := s.lib.features(spotify.ID(r.PathValue("id")))
+	if f == nil {
+		writeError(w, http.StatusNotFound, "Track not found")
+		return
+	}
+	beat := 60 / float64(f.Tempo)
+	length := float64(f.Duration) / 1000
+	var beats, bars []spotify.Marker
+	for i := 0; float64(i)*beat < length; i++ {
+		mk := spotify.Marker{Start: float64(i) * beat, Duration: beat, Confidence: 1}
+		beats = append(beats, mk)
+		if i%4 == 0 {
+			mk.Duration = math.Min(4*beat, length-mk.Start)
+			bars = append(bars, mk)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"bars": bars, "beats": beats})
+}
+
+// handleRecommendations picks tracks whose artist matches a seed genre, or
+// any track without genre seeds.
+func (s *Server) handleRecommendations(w http.ResponseWriter, r *http.Request) {
+	seeds := strings.Split(r.URL.Query().Get("seed_genres"), ",")
+	var tracks []spotify.SimpleTrack
+	for _, t := range s.lib.tracks {
+		a, _ := s.findArtist(t.Artists[0].ID)
+		if seeds[0] == "" || hasGenre(a, seeds) {
+			tracks = append(tracks, t.SimpleTrack)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"seeds": []struct{}{}, "tracks": tracks})
+}
+
+// hasGenre matches genres either as shown ("indie folk") or as seed slugs
+// ("indie-folk").
+func hasGenre(a spotify.FullArtist, genres []string) bool {
+	for _, g := range a.Genres {
+		for _, want := range genres {
+			if strings.EqualFold(g, strings.ReplaceAll(want, "-", " ")) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package mock
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// searchQuery is a search string split into free words and the field
+// filters Spotify understands (artist:, album:, year:, genre:).
+type searchQuery struct {
+	words   []string
+	filters map[string]string
+}
+
+// parseQuery lowercases q and splits it up; filter values may be quoted to
+// hold spaces, as in artist:"paper lanterns".
+func parseQuery(q string) searchQuery {
+	sq := searchQuery{filters: make(map[string]string)}
+	q = strings.ToLower(q)
+	for q != "" {
+		q = strings.TrimLeft(q, " ")
+		end := strings.IndexByte(q, ' ')
+		if end < 0 {
+			end = len(q)
+		}
+		word := q[:end]
+		field, value, ok := strings.Cut(word, ":")
+		if ok && strings.HasPrefix(value, `"`) {
+			// Read up to the closing quote instead
+			rest := q[len(field)+2:]
+			if close := strings.IndexByte(rest, '"'); close >= 0 {
+				value, end = rest[:close], len(field)+2+close+1
+			}
+		}
+		value = strings.Trim(value, `"`)
+		switch {
+		case ok && value != "":
+			sq.filters[field] = value
+		case word != "":
+			sq.words = append(sq.words, word)
+		}
+		q = q[end:]
+	}
+	return sq
+}
+
+func (sq searchQuery) matchesTrack(t spotify.FullTrack, a spotify.FullArtist) bool {
+	text := strings.ToLower(t.Name + " " + t.Artists[0].Name + " " + t.Album.Name)
+	for _, w := range sq.words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	for field, value := range sq.filters {
+		var ok bool
+		switch field {
+		case "artist":
+			ok = strings.Contains(strings.ToLower(t.Artists[0].Name), value)
+		case "album":
+			ok = strings.Contains(strings.ToLower(t.Album.Name), value)
+		case "track":
+			ok = strings.Contains(strings.ToLower(t.Name), value)
+		case "year":
+			from, to, _ := strings.Cut(value, "-")
+			if to == "" {
+				to = from
+			}
+			year := t.Album.ReleaseDate[:4]
+			ok = year >= from && year <= to
+		case "genre":
+			ok = hasGenre(a, []string{value})
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	sq := parseQuery(r.URL.Query().Get("q"))
+	out := make(map[string]interface{})
+	for _, kind := range strings.Split(r.URL.Query().Get("type"), ",") {
+		switch kind {
+		case "track":
+			tracks := []spotify.FullTrack{}
+			for _, t := range s.lib.tracks {
+				a, _ := s.findArtist(t.Artists[0].ID)
+				if sq.matchesTrack(t, a) {
+					tracks = append(tracks, t)
+				}
+			}
+			out["tracks"] = page(tracks, len(tracks))
+		case "artist":
+			found := []spotify.FullArtist{}
+			for _, a := range s.lib.artists {
+				if sq.matchesName(a.Name) {
+					found = append(found, a)
+				}
+			}
+			out["artists"] = page(found, len(found))
+		case "album":
+			found := []spotify.SimpleAlbum{}
+			for _, al := range s.lib.albums {
+				if sq.matchesName(al.Name + " " + al.Artists[0].Name) {
+					found = append(found, al.SimpleAlbum)
+				}
+			}
+			out["albums"] = page(found, len(found))
+		case "playlist":
+			found := []map[string]interface{}{}
+			for _, pl := range s.playlists {
+				if sq.matchesName(pl.name) {
+					found = append(found, playlistJSON(pl, false, nil))
+				}
+			}
+			out["playlists"] = page(found, len(found))
+		default:
+			// Shows, episodes and audiobooks aren't in the canned library
+			out[kind+"s"] = page([]struct{}{}, 0)
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// matchesName checks the free words against a name; filters only apply to
+// track searches.
+func (sq searchQuery) matchesName(name string) bool {
+	name = strings.ToLower(name)
+	for _, w := range sq.words {
+		if !strings.Contains(name, w) {
+			return false
+		}
+	}
+	return len(sq.words) > 0
+}
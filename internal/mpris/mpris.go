@@ -0,0 +1,33 @@
+// Package mpris makes Spotirice an MPRIS2 media player on the D-Bus session
+// bus, so playerctl, status bars and desktop media controls can drive it.
+package mpris
+
+// BusName is the name Spotirice takes on the session bus. A second
+// instance adds ".instance<pid>", as the MPRIS spec suggests.
+const BusName = "org.mpris.MediaPlayer2.spotirice"
+
+// State is what the player shows, as MPRIS clients see it.
+type State struct {
+	Playing bool
+	// URI of the playing item; "" when nothing is loaded
+	URI     string
+	Title   string
+	Artists []string
+	Album   string
+	ArtURL  string
+	// URL is the item's open.spotify.com link
+	URL        string
+	LengthMs   int
+	PositionMs int
+	Volume     int // percent
+	Shuffle    bool
+	Repeat     string // "off", "track" or "context"
+	// CanControl is false where Spotify won't take commands (a free
+	// account, a restricted device or an ad).
+	CanControl bool
+}
+
+// Control carries out a command from an MPRIS client. The commands are
+// those of the control pipe: play, pause, toggle, next, prev, seek <ms>
+// and volume <0-100>.
+type Control func(cmd string, args ...string)
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package mpris
+
+import "errors"
+
+// Server is the player on the bus; there is no D-Bus session bus here.
+type Server struct{}
+
+// Serve reports that MPRIS isn't available on this platform.
+func Serve(control Control) (*Server, error) {
+	return nil, errors.New("MPRIS needs a D-Bus session bus")
+}
+
+// Close does nothing.
+func (s *Server) Close() error { return nil }
+
+// Update does nothing.
+func (s *Server) Update(st State) {}
@@ -0,0 +1,277 @@
+//go:build unix
+
+package mpris
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	objectPath  = "/org/mpris/MediaPlayer2"
+	rootIface   = "org.mpris.MediaPlayer2"
+	playerIface = "org.mpris.MediaPlayer2.Player"
+	// noTrack is the spec's track ID for "nothing loaded"
+	noTrack = "/org/mpris/MediaPlayer2/TrackList/NoTrack"
+)
+
+// Server is the player on the bus; Update keeps it in step with the TUI.
+type Server struct {
+	conn    *dbus.Conn
+	props   *prop.Properties
+	control Control
+
+	mu    sync.Mutex
+	state State
+}
+
+// Serve takes the MPRIS bus name and answers clients until Close, passing
+// their commands to control.
+func Serve(control Control) (*Server, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{conn: conn, control: control}
+	if err := s.export(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	name := BusName
+	reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	if err == nil && reply != dbus.RequestNameReplyPrimaryOwner {
+		name = fmt.Sprintf("%s.instance%d", BusName, os.Getpid())
+		reply, err = conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	}
+	if err == nil && reply != dbus.RequestNameReplyPrimaryOwner {
+		err = fmt.Errorf("%s is taken", name)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close leaves the bus.
+func (s *Server) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Server) export() error {
+	p := player{s}
+	if err := s.conn.Export(mediaPlayer{}, objectPath, rootIface); err != nil {
+		return err
+	}
+	if err := s.conn.ExportWithMap(p, playerMethods, objectPath, playerIface); err != nil {
+		return err
+	}
+
+	props, err := prop.Export(s.conn, objectPath, prop.Map{
+		rootIface: {
+			"CanQuit":             {Value: false, Emit: prop.EmitConst},
+			"CanRaise":            {Value: false, Emit: prop.EmitConst},
+			"HasTrackList":        {Value: false, Emit: prop.EmitConst},
+			"Identity":            {Value: "Spotirice", Emit: prop.EmitConst},
+			"SupportedUriSchemes": {Value: []string{}, Emit: prop.EmitConst},
+			"SupportedMimeTypes":  {Value: []string{}, Emit: prop.EmitConst},
+		},
+		playerIface: {
+			"PlaybackStatus": {Value: "Stopped", Emit: prop.EmitTrue},
+			"LoopStatus":     {Value: "None", Emit: prop.EmitTrue},
+			"Rate":           {Value: 1.0, Emit: prop.EmitTrue},
+			"Shuffle":        {Value: false, Emit: prop.EmitTrue},
+			"Metadata":       {Value: metadata(State{}), Emit: prop.EmitTrue},
+			"Volume":         {Value: 0.0, Writable: true, Emit: prop.EmitTrue, Callback: s.setVolume},
+			// Clients read the position when they need it
+			"Position":      {Value: int64(0), Emit: prop.EmitFalse},
+			"MinimumRate":   {Value: 1.0, Emit: prop.EmitConst},
+			"MaximumRate":   {Value: 1.0, Emit: prop.EmitConst},
+			"CanGoNext":     {Value: false, Emit: prop.EmitTrue},
+			"CanGoPrevious": {Value: false, Emit: prop.EmitTrue},
+			"CanPlay":       {Value: false, Emit: prop.EmitTrue},
+			"CanPause":      {Value: false, Emit: prop.EmitTrue},
+			"CanSeek":       {Value: false, Emit: prop.EmitTrue},
+			"CanControl":    {Value: true, Emit: prop.EmitConst},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	s.props = props
+
+	node := &introspect.Node{
+		Name: objectPath,
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{Name: rootIface, Methods: introspect.Methods(mediaPlayer{}), Properties: props.Introspection(rootIface)},
+			{Name: playerIface, Methods: playerIntrospection(p), Properties: props.Introspection(playerIface),
+				Signals: []introspect.Signal{{Name: "Seeked", Args: []introspect.Arg{{Name: "Position", Type: "x"}}}}},
+		},
+	}
+	return s.conn.Export(introspect.NewIntrospectable(node), objectPath, "org.freedesktop.DBus.Introspectable")
+}
+
+// Update publishes st, signalling the properties that changed.
+func (s *Server) Update(st State) {
+	s.mu.Lock()
+	s.state = st
+	s.mu.Unlock()
+
+	status := "Stopped"
+	switch {
+	case st.URI != "" && st.Playing:
+		status = "Playing"
+	case st.URI != "":
+		status = "Paused"
+	}
+	loop := map[string]string{"track": "Track", "context": "Playlist"}[st.Repeat]
+	if loop == "" {
+		loop = "None"
+	}
+	loaded := st.URI != "" && st.CanControl
+
+	s.set("PlaybackStatus", status)
+	s.set("LoopStatus", loop)
+	s.set("Shuffle", st.Shuffle)
+	s.set("Metadata", metadata(st))
+	s.set("Volume", float64(st.Volume)/100)
+	s.set("Position", int64(st.PositionMs)*1000)
+	s.set("CanGoNext", loaded)
+	s.set("CanGoPrevious", loaded)
+	s.set("CanPlay", loaded)
+	s.set("CanPause", loaded)
+	s.set("CanSeek", loaded && st.LengthMs > 0)
+}
+
+// set changes a player property, leaving unchanged ones alone so no
+// signal goes out for them.
+func (s *Server) set(name string, v any) {
+	if reflect.DeepEqual(s.props.GetMust(playerIface, name), v) {
+		return
+	}
+	s.props.SetMust(playerIface, name, v)
+}
+
+func (s *Server) setVolume(c *prop.Change) *dbus.Error {
+	v, ok := c.Value.(float64)
+	if !ok {
+		return prop.ErrInvalidArg
+	}
+	s.control("volume", strconv.Itoa(int(math.Round(min(max(v, 0), 1)*100))))
+	return nil
+}
+
+// trackID is the MPRIS object path naming the item at uri.
+func trackID(uri string) dbus.ObjectPath {
+	parts := strings.Split(uri, ":")
+	if uri == "" || len(parts) != 3 {
+		return noTrack
+	}
+	id := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, parts[2])
+	return dbus.ObjectPath("/org/spotirice/" + parts[1] + "/" + id)
+}
+
+func metadata(st State) map[string]dbus.Variant {
+	md := map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(trackID(st.URI)),
+	}
+	if st.URI == "" {
+		return md
+	}
+	md["mpris:length"] = dbus.MakeVariant(int64(st.LengthMs) * 1000)
+	md["xesam:title"] = dbus.MakeVariant(st.Title)
+	md["xesam:artist"] = dbus.MakeVariant(st.Artists)
+	if st.Album != "" {
+		md["xesam:album"] = dbus.MakeVariant(st.Album)
+	}
+	if st.ArtURL != "" {
+		md["mpris:artUrl"] = dbus.MakeVariant(st.ArtURL)
+	}
+	if st.URL != "" {
+		md["xesam:url"] = dbus.MakeVariant(st.URL)
+	}
+	return md
+}
+
+// mediaPlayer is the org.mpris.MediaPlayer2 interface; the TUI can neither
+// be raised nor quit from the bus.
+type mediaPlayer struct{}
+
+func (mediaPlayer) Raise() *dbus.Error { return nil }
+func (mediaPlayer) Quit() *dbus.Error  { return nil }
+
+// player is the org.mpris.MediaPlayer2.Player interface.
+type player struct{ s *Server }
+
+func (p player) Next() *dbus.Error      { p.s.control("next"); return nil }
+func (p player) Previous() *dbus.Error  { p.s.control("prev"); return nil }
+func (p player) Pause() *dbus.Error     { p.s.control("pause"); return nil }
+func (p player) PlayPause() *dbus.Error { p.s.control("toggle"); return nil }
+func (p player) Stop() *dbus.Error      { p.s.control("pause"); return nil }
+func (p player) Play() *dbus.Error      { p.s.control("play"); return nil }
+
+// playerMethods renames the player's Go methods whose D-Bus names would
+// clash with well-known Go signatures.
+var playerMethods = map[string]string{"SeekBy": "Seek"}
+
+func playerIntrospection(p player) []introspect.Method {
+	methods := introspect.Methods(p)
+	for i, m := range methods {
+		if name, ok := playerMethods[m.Name]; ok {
+			methods[i].Name = name
+		}
+	}
+	return methods
+}
+
+// SeekBy is Seek: it moves by offset microseconds, and past the end skips
+// to the next track, as the spec asks.
+func (p player) SeekBy(offset int64) *dbus.Error {
+	p.s.mu.Lock()
+	st := p.s.state
+	p.s.mu.Unlock()
+	if st.LengthMs == 0 {
+		return nil
+	}
+	pos := int64(st.PositionMs) + offset/1000
+	if pos >= int64(st.LengthMs) {
+		p.s.control("next")
+		return nil
+	}
+	p.s.control("seek", strconv.FormatInt(max(pos, 0), 10))
+	return nil
+}
+
+// SetPosition seeks to pos microseconds if track is still the one playing.
+func (p player) SetPosition(track dbus.ObjectPath, pos int64) *dbus.Error {
+	p.s.mu.Lock()
+	st := p.s.state
+	p.s.mu.Unlock()
+	if track != trackID(st.URI) || pos < 0 || pos/1000 > int64(st.LengthMs) {
+		return nil
+	}
+	p.s.control("seek", strconv.FormatInt(pos/1000, 10))
+	return nil
+}
+
+// OpenUri is required by the spec, but no URI schemes are supported.
+func (p player) OpenUri(uri string) *dbus.Error {
+	return dbus.MakeFailedError(fmt.Errorf("opening %s is not supported", uri))
+}
@@ -0,0 +1,139 @@
+// Package platform picks the external desktop tools (URL opener, clipboard,
+// notifications) that match the current OS and graphical session.
+package platform
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// tool is a command line program and the arguments that precede its input.
+type tool struct {
+	name string
+	args []string
+}
+
+// Wayland reports whether we're running inside a Wayland session.
+func Wayland() bool {
+	return os.Getenv("XDG_SESSION_TYPE") == "wayland" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// Termux reports whether we're running inside Termux on Android, where
+// desktop tools are replaced by the termux-api commands.
+func Termux() bool {
+	return os.Getenv("TERMUX_VERSION") != "" || strings.Contains(os.Getenv("PREFIX"), "com.termux")
+}
+
+// Remote reports whether we're running in an SSH session, where the desktop
+// of the machine we run on isn't the one the user is looking at.
+func Remote() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CLIENT") != ""
+}
+
+// pick returns the first tool that is installed.
+func pick(tools ...tool) (tool, error) {
+	for _, t := range tools {
+		if _, err := exec.LookPath(t.name); err == nil {
+			return t, nil
+		}
+	}
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.name
+	}
+	return tool{}, fmt.Errorf("none of %s found", strings.Join(names, ", "))
+}
+
+func openers() []tool {
+	if Termux() {
+		return []tool{{"termux-open-url", nil}}
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []tool{{"open", nil}}
+	case "windows":
+		// The empty argument is start's window title
+		return []tool{{"cmd", []string{"/c", "start", ""}}}
+	default:
+		return []tool{{"xdg-open", nil}, {"gio", []string{"open"}}}
+	}
+}
+
+func clipboards() []tool {
+	if Termux() {
+		return []tool{{"termux-clipboard-set", nil}}
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []tool{{"pbcopy", nil}}
+	case "windows":
+		return []tool{{"clip", nil}}
+	}
+	x11 := []tool{
+		{"xclip", []string{"-selection", "clipboard"}},
+		{"xsel", []string{"--clipboard", "--input"}},
+	}
+	if Wayland() {
+		// XWayland clipboards aren't always synced, so prefer wl-copy
+		return append([]tool{{"wl-copy", nil}}, x11...)
+	}
+	return x11
+}
+
+// OpenURL opens url in the default browser without waiting for it.
+func OpenURL(url string) error {
+	t, err := pick(openers()...)
+	if err != nil {
+		return err
+	}
+	return exec.Command(t.name, append(t.args, url)...).Start()
+}
+
+// CopyToClipboard puts text on the system clipboard. Over SSH the local
+// terminal's clipboard is set with an OSC 52 sequence instead, written to
+// Stdout.
+func CopyToClipboard(text string) error {
+	if Remote() {
+		seq := "\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+		if os.Getenv("TMUX") != "" {
+			seq = "\033Ptmux;\033" + seq + "\033\\"
+		}
+		_, err := Stdout.Write([]byte(seq))
+		return err
+	}
+	t, err := pick(clipboards()...)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(t.name, t.args...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// Notify shows a desktop notification.
+func Notify(title, body string) error {
+	if Termux() {
+		t, err := pick(tool{"termux-notification", nil})
+		if err != nil {
+			return err
+		}
+		return exec.Command(t.name, "--title", title, "--content", body).Run()
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		return exec.Command("osascript", "-e", script).Run()
+	case "windows":
+		return errors.New("desktop notifications aren't supported on Windows")
+	}
+	t, err := pick(tool{"notify-send", []string{"--app-name=Spotirice"}})
+	if err != nil {
+		return err
+	}
+	return exec.Command(t.name, append(t.args, title, body)...).Run()
+}
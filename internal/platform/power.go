@@ -0,0 +1,28 @@
+package platform
+
+import "slices"
+
+// Power events reported by WatchPower.
+const (
+	Suspend = "suspend"
+	Wake    = "wake"
+	Lock    = "lock"
+	Unlock  = "unlock"
+)
+
+// PowerAction decides what a power event means for playback, given the
+// pause_on and resume_on settings: "pause" when it should stop, "play" when
+// it should come back (only if we paused it), or "".
+func PowerAction(event string, pauseOn, resumeOn []string, playing, pausedByUs bool) string {
+	switch event {
+	case Suspend, Lock:
+		if playing && slices.Contains(pauseOn, event) {
+			return "pause"
+		}
+	case Wake, Unlock:
+		if pausedByUs && !playing && slices.Contains(resumeOn, event) {
+			return "play"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package platform
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// powerMonitors are the D-Bus services watched with gdbus: logind for
+// sleep and loginctl lock-session, and the screensavers desktops lock with.
+var powerMonitors = [][]string{
+	{"--system", "--dest", "org.freedesktop.login1"},
+	{"--session", "--dest", "org.gnome.ScreenSaver"},
+	{"--session", "--dest", "org.freedesktop.ScreenSaver"},
+}
+
+// WatchPower calls fn with Suspend, Wake, Lock and Unlock as they happen,
+// until ctx is cancelled. It needs gdbus (part of GLib); services that
+// aren't running are skipped.
+func WatchPower(ctx context.Context, fn func(event string)) error {
+	if _, err := exec.LookPath("gdbus"); err != nil {
+		return errors.New("gdbus not found; install GLib's tools to pause on suspend and lock")
+	}
+	events := make(chan string)
+	for _, args := range powerMonitors {
+		cmd := exec.CommandContext(ctx, "gdbus", append([]string{"monitor"}, args...)...)
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		go func() {
+			sc := bufio.NewScanner(out)
+			for sc.Scan() {
+				if ev := parsePowerSignal(sc.Text()); ev != "" {
+					select {
+					case events <- ev:
+					case <-ctx.Done():
+					}
+				}
+			}
+			_ = cmd.Wait()
+		}()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			fn(ev)
+		}
+	}
+}
+
+// parsePowerSignal reads a gdbus monitor line such as
+// "/org/freedesktop/login1: org.freedesktop.login1.Manager.PrepareForSleep (true,)".
+func parsePowerSignal(line string) string {
+	_, signal, ok := strings.Cut(line, ": ")
+	if !ok {
+		return ""
+	}
+	name, args, _ := strings.Cut(signal, " ")
+	on := strings.HasPrefix(args, "(true")
+	switch {
+	case strings.HasSuffix(name, ".Manager.PrepareForSleep"):
+		if on {
+			return Suspend
+		}
+		return Wake
+	case strings.HasSuffix(name, ".Session.Lock"):
+		return Lock
+	case strings.HasSuffix(name, ".Session.Unlock"):
+		return Unlock
+	case strings.HasSuffix(name, "ScreenSaver.ActiveChanged"):
+		if on {
+			return Lock
+		}
+		return Unlock
+	}
+	return ""
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package platform
+
+import (
+	"context"
+	"errors"
+)
+
+// WatchPower is only implemented on Linux, where logind and the desktop
+// screensavers announce sleep and locking over D-Bus.
+func WatchPower(ctx context.Context, fn func(event string)) error {
+	return errors.New("pausing on suspend and lock is only supported on Linux")
+}
@@ -0,0 +1,28 @@
+package platform
+
+import (
+	"os"
+	"sync"
+)
+
+// Stdout is os.Stdout with each write going out whole. A full-screen program
+// draws through it (tea.WithOutput), so the sequences CopyToClipboard sends
+// from another goroutine can't land in the middle of a frame.
+var Stdout = &terminal{f: os.Stdout}
+
+// terminal serializes writes to f. It keeps f's descriptor visible, so
+// bubbletea still sees a terminal.
+type terminal struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func (t *terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.f.Write(p)
+}
+
+func (t *terminal) Read(p []byte) (int, error) { return t.f.Read(p) }
+func (t *terminal) Close() error               { return t.f.Close() }
+func (t *terminal) Fd() uintptr                { return t.f.Fd() }
@@ -0,0 +1,441 @@
+// Package qr encodes short texts (links) as QR codes, small enough to draw
+// in a terminal.
+//
+// Only what sharing a link needs is implemented: byte mode, error
+// correction level M and versions 1 to 10, which hold up to 213 bytes.
+package qr
+
+import (
+	"errors"
+	"strings"
+)
+
+// version describes one QR version at error correction level M.
+type version struct {
+	codewords int   // total codewords, data and error correction
+	ecLen     int   // error correction codewords per block
+	blocks    int   // number of blocks
+	align     []int // alignment pattern centres
+}
+
+var versions = []version{
+	1:  {26, 10, 1, nil},
+	2:  {44, 16, 1, []int{6, 18}},
+	3:  {70, 26, 1, []int{6, 22}},
+	4:  {100, 18, 2, []int{6, 26}},
+	5:  {134, 24, 2, []int{6, 30}},
+	6:  {172, 16, 4, []int{6, 34}},
+	7:  {196, 18, 4, []int{6, 22, 38}},
+	8:  {242, 22, 4, []int{6, 24, 42}},
+	9:  {292, 22, 5, []int{6, 26, 46}},
+	10: {346, 26, 5, []int{6, 28, 50}},
+}
+
+// formatBitsM are the two format bits of error correction level M.
+const formatBitsM = 0
+
+// Code is an encoded symbol: Size×Size modules, true for dark.
+type Code struct {
+	Size    int
+	modules [][]bool
+	isFunc  [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark. Coordinates
+// outside the symbol (the quiet zone) are light.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// Encode returns the smallest code that holds text.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	for ver := 1; ver < len(versions); ver++ {
+		v := versions[ver]
+		capacity := v.codewords - v.ecLen*v.blocks
+		countBits := 8
+		if ver >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) > capacity*8 {
+			continue
+		}
+		codewords := dataCodewords(data, countBits, capacity)
+		return build(ver, addErrorCorrection(codewords, v)), nil
+	}
+	return nil, errors.New("text too long for a QR code")
+}
+
+// dataCodewords packs data in byte mode and pads it to capacity codewords.
+func dataCodewords(data []byte, countBits, capacity int) []byte {
+	var bits []bool
+	push := func(val, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, val>>i&1 == 1)
+		}
+	}
+	push(0b0100, 4)
+	push(len(data), countBits)
+	for _, b := range data {
+		push(int(b), 8)
+	}
+	push(0, min(4, capacity*8-len(bits)))
+	push(0, (8-len(bits)%8)%8)
+
+	out := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := range 8 {
+			if bits[i+j] {
+				b |= 1 << (7 - j)
+			}
+		}
+		out = append(out, b)
+	}
+	for pad := byte(0xEC); len(out) < capacity; pad ^= 0xEC ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+// addErrorCorrection splits data into blocks, appends each block's
+// Reed-Solomon codewords and interleaves the result.
+func addErrorCorrection(data []byte, v version) []byte {
+	numShort := v.blocks - v.codewords%v.blocks
+	shortLen := v.codewords / v.blocks
+	divisor := rsDivisor(v.ecLen)
+
+	var blocks [][]byte
+	k := 0
+	for i := range v.blocks {
+		n := shortLen - v.ecLen
+		if i >= numShort {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < numShort {
+			// Placeholder so all blocks line up; skipped when interleaving
+			block = append(block, 0)
+		}
+		blocks = append(blocks, append(block, ecc...))
+	}
+
+	out := make([]byte, 0, v.codewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortLen-v.ecLen || j >= numShort {
+				out = append(out, block[i])
+			}
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMul(d, factor)
+		}
+	}
+	return result
+}
+
+// build lays out the function patterns and codewords of version ver and
+// applies the mask with the lowest penalty.
+func build(ver int, codewords []byte) *Code {
+	size := ver*4 + 17
+	c := &Code{Size: size, modules: grid(size), isFunc: grid(size)}
+	c.drawFunctionPatterns(ver)
+	c.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := range 8 {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // masks are their own inverse
+	}
+	c.applyMask(best)
+	c.drawFormatBits(best)
+	return c
+}
+
+func grid(size int) [][]bool {
+	g := make([][]bool, size)
+	for i := range g {
+		g[i] = make([]bool, size)
+	}
+	return g
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunc[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns(ver int) {
+	for i := range c.Size {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	align := versions[ver].align
+	last := len(align) - 1
+	for i, x := range align {
+		for j, y := range align {
+			// The corners with finder patterns get none
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas; drawFormatBits fills them in per mask
+	c.drawFormatBits(0)
+	c.drawVersion(ver)
+}
+
+// drawFinder draws a finder pattern centred on x, y, with its separator.
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	data := formatBitsM<<3 | mask
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true) // always dark
+}
+
+func (c *Code) drawVersion(ver int) {
+	if ver < 7 {
+		return
+	}
+	rem := ver
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := ver<<12 | rem
+	for i := range 18 {
+		dark := bits>>i&1 == 1
+		a, b := c.Size-11+i%3, i/3
+		c.set(a, b, dark)
+		c.set(b, a, dark)
+	}
+}
+
+// drawCodewords fills the non-function modules in the zigzag order, two
+// columns at a time from the bottom right.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// Skip the vertical timing pattern
+			right = 5
+		}
+		for vert := range c.Size {
+			for j := range 2 {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !c.isFunc[y][x] && i < len(data)*8 {
+					c.modules[y][x] = data[i>>3]>>(7-i&7)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := range c.Size {
+		for x := range c.Size {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.isFunc[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol by the standard's rules: long runs, 2×2
+// blocks, finder-like patterns and an uneven dark/light balance.
+func (c *Code) penalty() int {
+	score := 0
+	lines := make([]string, 0, 2*c.Size)
+	for y := range c.Size {
+		var row, col strings.Builder
+		for x := range c.Size {
+			row.WriteByte(moduleByte(c.modules[y][x]))
+			col.WriteByte(moduleByte(c.modules[x][y]))
+		}
+		lines = append(lines, row.String(), col.String())
+	}
+	for _, line := range lines {
+		run := 1
+		for i := 1; i <= len(line); i++ {
+			if i < len(line) && line[i] == line[i-1] {
+				run++
+				continue
+			}
+			if run >= 5 {
+				score += 3 + run - 5
+			}
+			run = 1
+		}
+		score += 40 * (strings.Count(line, "10111010000") + strings.Count(line, "00001011101"))
+	}
+
+	dark := 0
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				m := c.modules[y][x]
+				if c.modules[y][x+1] == m && c.modules[y+1][x] == m && c.modules[y+1][x+1] == m {
+					score += 3
+				}
+			}
+		}
+	}
+	percent := dark * 100 / (c.Size * c.Size)
+	score += 10 * (abs(percent-50) / 5)
+	return score
+}
+
+func moduleByte(dark bool) byte {
+	if dark {
+		return '1'
+	}
+	return '0'
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// HalfBlocks draws the code with a quiet zone of quiet modules, two module
+// rows per line: "█" where both are dark, "▀" and "▄" where one is. It is
+// meant to be shown dark on light.
+func (c *Code) HalfBlocks(quiet int) []string {
+	var lines []string
+	for y := -quiet; y < c.Size+quiet; y += 2 {
+		var b strings.Builder
+		for x := -quiet; x < c.Size+quiet; x++ {
+			top, bottom := c.Dark(x, y), c.Dark(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
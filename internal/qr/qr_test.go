@@ -0,0 +1,197 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodeSize(t *testing.T) {
+	// The byte mode capacities at level M are the largest texts each
+	// version takes
+	tests := []struct {
+		length int
+		size   int // 0 for too long
+	}{
+		{0, 21}, {14, 21}, {15, 25}, {26, 25}, {27, 29}, {42, 29}, {43, 33},
+		{62, 33}, {63, 37}, {84, 37}, {85, 41}, {106, 41}, {107, 45},
+		{122, 45}, {123, 49}, {152, 49}, {153, 53}, {180, 53}, {181, 57},
+		{213, 57}, {214, 0},
+	}
+	for _, tt := range tests {
+		c, err := Encode(strings.Repeat("x", tt.length))
+		switch {
+		case tt.size == 0 && err == nil:
+			t.Errorf("Encode(%d bytes) = size %d, want an error", tt.length, c.Size)
+		case tt.size != 0 && err != nil:
+			t.Errorf("Encode(%d bytes): %v", tt.length, err)
+		case tt.size != 0 && c.Size != tt.size:
+			t.Errorf("Encode(%d bytes) = size %d, want %d", tt.length, c.Size, tt.size)
+		}
+	}
+}
+
+func TestRSRemainder(t *testing.T) {
+	// Version 1-M "HELLO WORLD", the standard's worked example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+// TestEncodeReadBack reads the text back out of codes: the format bits
+// name level M and the mask, and after unmasking the data codewords hold
+// the text in byte mode.
+func TestEncodeReadBack(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT", // version 4, two blocks
+		"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT" + // version 8, four blocks and version bits
+			"?si=0123456789abcdef&context=spotify%3Aplaylist%3A37i9dQZF1DX4sWSpwq3LiO",
+		strings.Repeat("é", 100), // version 10, with a 16-bit length
+	} {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode(%q): %v", text, err)
+		}
+		ver := (c.Size - 17) / 4
+
+		mask, ok := readFormat(c)
+		if !ok {
+			t.Errorf("Encode(%q): format bits aren't level M with a mask", text)
+			continue
+		}
+		c.applyMask(mask)
+		got, ok := readByteMode(deinterleave(readCodewords(c), versions[ver]), ver)
+		if !ok || got != text {
+			t.Errorf("Encode(%q) reads back as %q", text, got)
+		}
+	}
+}
+
+// readFormat decodes the format bits beside the top left finder.
+func readFormat(c *Code) (mask int, ok bool) {
+	var bits int
+	for i := 0; i <= 5; i++ {
+		bits |= boolBit(c.Dark(8, i)) << i
+	}
+	bits |= boolBit(c.Dark(8, 7))<<6 | boolBit(c.Dark(8, 8))<<7 | boolBit(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		bits |= boolBit(c.Dark(14-i, 8)) << i
+	}
+	for mask := range 8 {
+		data := formatBitsM<<3 | mask
+		rem := data
+		for range 10 {
+			rem = rem<<1 ^ (rem>>9)*0x537
+		}
+		if bits == (data<<10|rem)^0x5412 {
+			return mask, true
+		}
+	}
+	return 0, false
+}
+
+func boolBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// readCodewords reads the modules outside the function patterns in the
+// zigzag order.
+func readCodewords(c *Code) []byte {
+	var out []byte
+	var cur byte
+	n := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range c.Size {
+			for j := range 2 {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.isFunc[y][x] {
+					continue
+				}
+				cur = cur<<1 | byte(boolBit(c.modules[y][x]))
+				if n++; n%8 == 0 {
+					out = append(out, cur)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// deinterleave returns the data codewords of the blocks, in order.
+func deinterleave(codewords []byte, v version) []byte {
+	numShort := v.blocks - v.codewords%v.blocks
+	shortData := v.codewords/v.blocks - v.ecLen
+	blocks := make([][]byte, v.blocks)
+	k := 0
+	for i := range shortData + 1 {
+		for j := range blocks {
+			if i == shortData && j < numShort {
+				continue
+			}
+			blocks[j] = append(blocks[j], codewords[k])
+			k++
+		}
+	}
+	return bytes.Join(blocks, nil)
+}
+
+// readByteMode decodes a byte mode segment.
+func readByteMode(data []byte, ver int) (string, bool) {
+	pos := 0
+	read := func(n int) int {
+		v := 0
+		for range n {
+			v = v<<1 | int(data[pos>>3]>>(7-pos&7)&1)
+			pos++
+		}
+		return v
+	}
+	if read(4) != 0b0100 {
+		return "", false
+	}
+	countBits := 8
+	if ver >= 10 {
+		countBits = 16
+	}
+	text := make([]byte, read(countBits))
+	for i := range text {
+		text[i] = byte(read(8))
+	}
+	return string(text), true
+}
+
+func TestHalfBlocks(t *testing.T) {
+	c, err := Encode("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := c.HalfBlocks(2)
+	// 21 modules and a quiet zone of 2 on each side, two rows a line
+	if len(lines) != 13 {
+		t.Errorf("%d lines, want 13", len(lines))
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != 25 {
+			t.Errorf("line %d is %d wide, want 25", i, n)
+		}
+	}
+	// The quiet zone is blank, then come the top finders' first two rows
+	if strings.TrimSpace(lines[0]) != "" {
+		t.Errorf("first line %q isn't quiet", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "  █▀▀▀▀▀█ ") || !strings.HasSuffix(lines[1], " █▀▀▀▀▀█  ") {
+		t.Errorf("second line %q lacks the finders", lines[1])
+	}
+}
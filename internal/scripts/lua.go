@@ -0,0 +1,241 @@
+package scripts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Host is what a Lua script drives the player through.
+type Host struct {
+	// Do carries out a player command, as if a script had printed it.
+	Do func(Command) error
+	// Status returns the playback state now.
+	Status func() State
+}
+
+// Lua is a script for the embedded Lua interpreter. Unlike an executable it
+// is loaded once and stays loaded, so its variables keep their values from
+// one event to the next. It registers what it handles with the spotirice
+// module:
+//
+//	spotirice.on(event, function(state) ... end)    -- on a playback event
+//	spotirice.command(name, function(args) ... end) -- on `spotirice run name`
+//	spotirice.status()                              -- the state table
+//	spotirice.run("volume", 30)                     -- any player command
+//	                                                -- but run
+//	spotirice.next()                                -- and play, pause, toggle,
+//	                                                -- prev, like, volume, seek
+//	spotirice.log(...)                              -- to the daemon's stderr
+//
+// The state table has the fields of State's JSON form (track, artist,
+// liked, volume, ...).
+type Lua struct {
+	path string
+	host Host
+
+	mu       sync.Mutex // an LState can't be shared between goroutines
+	l        *lua.LState
+	handlers map[string][]*lua.LFunction
+	commands map[string]*lua.LFunction
+}
+
+// luaShorthands are the player commands with a function of their own.
+var luaShorthands = []string{"play", "pause", "toggle", "next", "prev", "like", "volume", "seek"}
+
+// LoadLua runs the script at path (which may start with ~/) so it can
+// register its handlers.
+func LoadLua(ctx context.Context, path string, host Host) (*Lua, error) {
+	s := &Lua{
+		path:     expandHome(path),
+		host:     host,
+		l:        lua.NewState(),
+		handlers: make(map[string][]*lua.LFunction),
+		commands: make(map[string]*lua.LFunction),
+	}
+	s.l.PreloadModule("spotirice", s.module)
+	// Scripts may use it without require
+	if err := s.l.DoString(`spotirice = require("spotirice")`); err != nil {
+		s.l.Close()
+		return nil, err
+	}
+	err := s.call(ctx, func() error { return s.l.DoFile(s.path) })
+	if err == nil && len(s.handlers) == 0 && len(s.commands) == 0 {
+		err = errors.New("registers no handlers or commands; see spotirice.on and spotirice.command")
+	}
+	if err != nil {
+		s.l.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Close frees the interpreter.
+func (s *Lua) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.l.Close()
+}
+
+// Commands lists the commands the script defined.
+func (s *Lua) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.commands))
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Events lists the events the script has handlers for.
+func (s *Lua) Events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.handlers))
+	for name := range s.handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Handle runs the script's handlers for st.Event.
+func (s *Lua) Handle(ctx context.Context, st State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, fn := range s.handlers[st.Event] {
+		err := s.call(ctx, func() error {
+			return s.l.CallByParam(lua.P{Fn: fn, Protect: true}, s.stateTable(st))
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %s handler: %w", s.path, st.Event, err)
+		}
+	}
+	return nil
+}
+
+// RunCommand runs the command called name with args. ok is false when the
+// script doesn't define it.
+func (s *Lua) RunCommand(ctx context.Context, name string, args []string) (ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn, ok := s.commands[name]
+	if !ok {
+		return false, nil
+	}
+	list := s.l.NewTable()
+	for _, a := range args {
+		list.Append(lua.LString(a))
+	}
+	err = s.call(ctx, func() error {
+		return s.l.CallByParam(lua.P{Fn: fn, Protect: true}, list)
+	})
+	if err != nil {
+		return true, fmt.Errorf("%s: command %s: %w", s.path, name, err)
+	}
+	return true, nil
+}
+
+// call runs fn in the interpreter within Timeout, so a script stuck in a
+// loop can't hold up the events after it.
+func (s *Lua) call(ctx context.Context, fn func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	s.l.SetContext(ctx)
+	defer s.l.RemoveContext()
+	return fn()
+}
+
+func (s *Lua) module(l *lua.LState) int {
+	mod := l.SetFuncs(l.NewTable(), map[string]lua.LGFunction{
+		"on":      s.luaOn,
+		"command": s.luaCommand,
+		"status":  s.luaStatus,
+		"run":     s.luaRun,
+		"log":     s.luaLog,
+	})
+	for _, name := range luaShorthands {
+		l.SetField(mod, name, l.NewFunction(func(l *lua.LState) int {
+			return s.runCommand(l, name, 1)
+		}))
+	}
+	l.Push(mod)
+	return 1
+}
+
+func (s *Lua) luaOn(l *lua.LState) int {
+	event := l.CheckString(1)
+	fn := l.CheckFunction(2)
+	s.handlers[event] = append(s.handlers[event], fn)
+	return 0
+}
+
+func (s *Lua) luaCommand(l *lua.LState) int {
+	name := l.CheckString(1)
+	s.commands[name] = l.CheckFunction(2)
+	return 0
+}
+
+func (s *Lua) luaStatus(l *lua.LState) int {
+	l.Push(s.stateTable(s.host.Status()))
+	return 1
+}
+
+func (s *Lua) luaRun(l *lua.LState) int {
+	return s.runCommand(l, l.CheckString(1), 2)
+}
+
+// runCommand carries out cmd with the Lua arguments from index first on. A
+// failed command raises a Lua error, which a script can catch with pcall.
+func (s *Lua) runCommand(l *lua.LState, cmd string, first int) int {
+	var args []string
+	for i := first; i <= l.GetTop(); i++ {
+		args = append(args, l.Get(i).String())
+	}
+	if err := s.host.Do(Command{Cmd: cmd, Args: args}); err != nil {
+		l.RaiseError("%s: %s", cmd, err)
+	}
+	return 0
+}
+
+func (s *Lua) luaLog(l *lua.LState) int {
+	parts := make([]string, l.GetTop())
+	for i := range parts {
+		parts[i] = l.Get(i + 1).String()
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s\n", s.path, strings.Join(parts, " "))
+	return 0
+}
+
+// stateTable is st as a Lua table, with the keys of its JSON form.
+func (s *Lua) stateTable(st State) *lua.LTable {
+	t := s.l.NewTable()
+	set := func(key string, v lua.LValue) { t.RawSetString(key, v) }
+	set("event", lua.LString(st.Event))
+	set("key", lua.LString(st.Key))
+	set("id", lua.LString(st.ID))
+	set("track", lua.LString(st.Track))
+	set("artist", lua.LString(st.Artist))
+	set("liked", lua.LBool(st.Liked))
+	set("playing", lua.LBool(st.Playing))
+	set("progress_ms", lua.LNumber(st.ProgressMs))
+	set("duration_ms", lua.LNumber(st.DurationMs))
+	set("device", lua.LString(st.Device))
+	set("volume", lua.LNumber(st.Volume))
+	return t
+}
+
+// IsLua reports whether a script command line names a Lua script, which
+// runs in the embedded interpreter rather than as a program.
+func IsLua(command string) bool {
+	args := strings.Fields(command)
+	return len(args) == 1 && strings.HasSuffix(args[0], ".lua")
+}
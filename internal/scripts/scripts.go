@@ -0,0 +1,122 @@
+// Package scripts runs user scripts for playback events and key presses.
+//
+// A script is any executable. It gets the playback state in SPOTIRICE_*
+// environment variables and as JSON on stdin, and drives the player by
+// printing commands (next, like, volume 30, ...), one per line, to stdout.
+// Scripts ending in .lua run in the embedded Lua interpreter instead; see
+// Lua.
+package scripts
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timeout bounds a script run, so a hung script can't pile up behind
+// later events.
+const Timeout = 30 * time.Second
+
+// State is what a script is told: the event (or "key" for a key binding)
+// and the playback state after it.
+type State struct {
+	Event      string `json:"event"`
+	Key        string `json:"key,omitempty"`
+	ID         string `json:"id"`
+	Track      string `json:"track"`
+	Artist     string `json:"artist"`
+	Liked      bool   `json:"liked"`
+	Playing    bool   `json:"playing"`
+	ProgressMs int    `json:"progress_ms"`
+	DurationMs int    `json:"duration_ms"`
+	Device     string `json:"device"`
+	Volume     int    `json:"volume"`
+}
+
+func (st State) env() []string {
+	return []string{
+		"SPOTIRICE_EVENT=" + st.Event,
+		"SPOTIRICE_KEY=" + st.Key,
+		"SPOTIRICE_TRACK_ID=" + st.ID,
+		"SPOTIRICE_TRACK=" + st.Track,
+		"SPOTIRICE_ARTIST=" + st.Artist,
+		"SPOTIRICE_LIKED=" + strconv.FormatBool(st.Liked),
+		"SPOTIRICE_PLAYING=" + strconv.FormatBool(st.Playing),
+		"SPOTIRICE_PROGRESS_MS=" + strconv.Itoa(st.ProgressMs),
+		"SPOTIRICE_DURATION_MS=" + strconv.Itoa(st.DurationMs),
+		"SPOTIRICE_DEVICE=" + st.Device,
+		"SPOTIRICE_VOLUME=" + strconv.Itoa(st.Volume),
+	}
+}
+
+// Command is one line a script printed.
+type Command struct {
+	Cmd  string
+	Args []string
+}
+
+// Run runs command (split on spaces, like headless_player) with st in its
+// environment and on stdin, and returns the commands it printed. When it
+// fails, the error ends with the last line it wrote to stderr.
+func Run(ctx context.Context, command string, st State) ([]Command, error) {
+	out, err := Output(ctx, command, st)
+	if err != nil {
+		return nil, err
+	}
+	var cmds []Command
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) > 0 {
+			cmds = append(cmds, Command{Cmd: fields[0], Args: fields[1:]})
+		}
+	}
+	return cmds, nil
+}
+
+// Output runs command like Run but returns what it printed as is.
+func Output(ctx context.Context, command string, st State) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", errors.New("empty script command")
+	}
+	stdin, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, expandHome(args[0]), args[1:]...)
+	cmd.Env = append(os.Environ(), st.env()...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if last := lines[len(lines)-1]; last != "" {
+			return "", fmt.Errorf("%s: %w: %s", args[0], err, last)
+		}
+		return "", fmt.Errorf("%s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// expandHome resolves a leading ~/ so config entries can point into the
+// home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + string(os.PathSeparator) + rest
+		}
+	}
+	return path
+}
@@ -0,0 +1,38 @@
+package spotifylauncher
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const macBundleID = "com.spotify.client"
+
+// detectMacOS returns the path of Spotify.app, checking the usual
+// Applications folders before asking Spotlight for the bundle ID.
+func detectMacOS() (string, error) {
+	candidates := []string{"/Applications/Spotify.app"}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, "Applications", "Spotify.app"))
+	}
+	for _, app := range candidates {
+		if info, err := os.Stat(app); err == nil && info.IsDir() {
+			return app, nil
+		}
+	}
+
+	if commandExists("mdfind") {
+		out, err := exec.Command("mdfind", "kMDItemCFBundleIdentifier == '"+macBundleID+"'").Output()
+		if err == nil {
+			for _, line := range strings.Split(string(out), "\n") {
+				if line = strings.TrimSpace(line); strings.HasSuffix(line, ".app") {
+					return line, nil
+				}
+			}
+		}
+	}
+
+	return "", errors.New("spotify not installed: Spotify.app not found in /Applications or via Spotlight")
+}
@@ -0,0 +1,82 @@
+package spotifylauncher
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	headlessMu   sync.Mutex
+	headlessCmd  *exec.Cmd
+	headlessDone chan struct{} // closed once headlessCmd has exited
+)
+
+// headlessStopTimeout is how long StopHeadless waits for the player to shut
+// down on its own before killing it.
+const headlessStopTimeout = 3 * time.Second
+
+// LaunchHeadless starts a headless Connect player such as spotifyd or
+// librespot as a child process. command is split on whitespace, e.g.
+// "spotifyd --no-daemon". The process is stopped by StopHeadless.
+func LaunchHeadless(command string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return errors.New("no headless player configured")
+	}
+	if !commandExists(args[0]) {
+		return errors.New("headless player " + args[0] + " not found")
+	}
+
+	headlessMu.Lock()
+	defer headlessMu.Unlock()
+	if headlessCmd != nil {
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	headlessCmd = cmd
+	done := make(chan struct{})
+	headlessDone = done
+
+	// Reap the process if it exits on its own
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+		headlessMu.Lock()
+		if headlessCmd == cmd {
+			headlessCmd = nil
+		}
+		headlessMu.Unlock()
+	}()
+	return nil
+}
+
+// StopHeadless asks the headless player started by LaunchHeadless to shut
+// down, if it is still running, so it can leave the Connect session cleanly.
+// It is killed if it hasn't exited after headlessStopTimeout, or can't be
+// signalled (Windows).
+func StopHeadless() {
+	headlessMu.Lock()
+	cmd, done := headlessCmd, headlessDone
+	headlessCmd = nil
+	headlessMu.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		_ = cmd.Process.Kill()
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(headlessStopTimeout):
+		_ = cmd.Process.Kill()
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"os/exec"
 	"runtime"
+
+	"github.com/metolius25/spotirice/internal/platform"
 )
 
 func commandExists(cmd string) bool {
@@ -13,19 +15,23 @@ func commandExists(cmd string) bool {
 
 // DetectSpotify checks for Spotify installation on the current platform
 func DetectSpotify() (string, error) {
+	if platform.Termux() {
+		// The Android app can't be queried from Termux; trust it to be there
+		if commandExists("am") {
+			return "android", nil
+		}
+		return "", errors.New("spotify not found")
+	}
+
 	switch runtime.GOOS {
 	case "darwin":
-		// macOS: Check for Spotify.app
-		if commandExists("open") {
-			return "macos", nil
+		if _, err := detectMacOS(); err != nil {
+			return "", err
 		}
+		return "macos", nil
 	case "windows":
-		// Windows: Check for Spotify in common locations
-		if commandExists("spotify.exe") {
-			return "windows", nil
-		}
-		// Try AppData location
-		return "windows-store", nil
+		kind, _, err := detectWindows()
+		return kind, err
 	default:
 		// Linux and others
 		if commandExists("flatpak") {
@@ -46,25 +52,91 @@ func DetectSpotify() (string, error) {
 	return "", errors.New("spotify not found")
 }
 
+// launchedKind records the installation kind when Spotirice started the
+// client itself, so QuitSpotify only closes clients we opened.
+var launchedKind string
+
+// Options controls how the Spotify client is started.
+type Options struct {
+	// Minimized starts the client without showing or focusing its window,
+	// where the platform supports it.
+	Minimized bool
+}
+
+// minimizedFlag is understood by the desktop client on Windows and Linux
+// (the same flag its own autostart entry uses); clients that don't know it
+// ignore it.
+const minimizedFlag = "--minimized"
+
 // LaunchSpotify attempts to launch Spotify on the current platform
-func LaunchSpotify() error {
+func LaunchSpotify(opts Options) error {
 	kind, err := DetectSpotify()
 	if err != nil {
 		return err
 	}
 
+	if err := launch(kind, opts); err != nil {
+		return err
+	}
+	launchedKind = kind
+	return nil
+}
+
+func launch(kind string, opts Options) error {
+	var extra []string
+	if opts.Minimized {
+		extra = []string{minimizedFlag}
+	}
+
 	switch kind {
 	case "macos":
-		return exec.Command("open", "-a", "Spotify").Start()
+		app, err := detectMacOS()
+		if err != nil {
+			return err
+		}
+		if opts.Minimized {
+			// -g: don't bring to foreground, -j: launch hidden
+			return exec.Command("open", "-g", "-j", app).Start()
+		}
+		return exec.Command("open", app).Start()
 	case "windows", "windows-store":
-		return exec.Command("cmd", "/c", "start", "spotify:").Start()
+		return launchWindows(opts)
+	case "android":
+		// Opening the app's deep link starts it and registers it as a device
+		return exec.Command("am", "start", "-a", "android.intent.action.VIEW", "-d", "spotify:").Start()
 	case "flatpak":
-		return exec.Command("flatpak", "run", "com.spotify.Client").Start()
+		return exec.Command("flatpak", append([]string{"run", "com.spotify.Client"}, extra...)...).Start()
 	case "snap":
-		return exec.Command("snap", "run", "spotify").Start()
+		return exec.Command("snap", append([]string{"run", "spotify"}, extra...)...).Start()
 	case "binary":
-		return exec.Command("spotify").Start()
+		return exec.Command("spotify", extra...).Start()
 	}
 
 	return errors.New("unknown spotify installation")
 }
+
+// LaunchedSpotify reports whether LaunchSpotify started the client.
+func LaunchedSpotify() bool {
+	return launchedKind != ""
+}
+
+// QuitSpotify closes the client started by LaunchSpotify. It does nothing if
+// Spotirice didn't launch Spotify.
+func QuitSpotify() error {
+	switch launchedKind {
+	case "":
+		return nil
+	case "macos":
+		return exec.Command("osascript", "-e", `quit app "Spotify"`).Run()
+	case "windows", "windows-store":
+		return exec.Command("taskkill", "/IM", "Spotify.exe").Run()
+	case "android":
+		// Apps can only be stopped from Android itself
+		return nil
+	case "flatpak":
+		return exec.Command("flatpak", "kill", "com.spotify.Client").Run()
+	default:
+		// snap and plain binary installs both run a "spotify" process
+		return exec.Command("pkill", "-x", "spotify").Run()
+	}
+}
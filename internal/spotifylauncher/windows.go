@@ -0,0 +1,96 @@
+package spotifylauncher
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// storeAppID is the AppsFolder ID of the Microsoft Store Spotify package.
+const storeAppID = `SpotifyAB.SpotifyMusic_zpdnekdrzrea0!Spotify`
+
+// uninstallKeys are the registry keys the desktop installer writes.
+var uninstallKeys = []string{
+	`HKCU\Software\Microsoft\Windows\CurrentVersion\Uninstall\Spotify`,
+	`HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\Spotify`,
+	`HKLM\Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\Spotify`,
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// detectWindows looks for the desktop installer's Spotify.exe, the Microsoft
+// Store package and finally the uninstall registry keys. It returns the kind
+// of installation and, for desktop installs, the executable path.
+func detectWindows() (kind, path string, err error) {
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		exe := filepath.Join(appData, "Spotify", "Spotify.exe")
+		if fileExists(exe) {
+			return "windows", exe, nil
+		}
+	}
+
+	if localAppData := os.Getenv("LOCALAPPDATA"); localAppData != "" {
+		// Store apps expose an execution alias here
+		if fileExists(filepath.Join(localAppData, "Microsoft", "WindowsApps", "Spotify.exe")) {
+			return "windows-store", "", nil
+		}
+	}
+	if commandExists("powershell") {
+		out, err := exec.Command("powershell", "-NoProfile", "-Command",
+			"Get-AppxPackage -Name SpotifyAB.SpotifyMusic | Select-Object -ExpandProperty Name").Output()
+		if err == nil && strings.TrimSpace(string(out)) != "" {
+			return "windows-store", "", nil
+		}
+	}
+
+	for _, key := range uninstallKeys {
+		if exe := registryDisplayIcon(key); exe != "" && fileExists(exe) {
+			return "windows", exe, nil
+		}
+	}
+
+	return "", "", errors.New("spotify not installed: no Spotify.exe in %APPDATA%, no Microsoft Store package and no uninstall registry entry")
+}
+
+// registryDisplayIcon reads the DisplayIcon value of an uninstall key, which
+// points at Spotify.exe (optionally followed by ",0").
+func registryDisplayIcon(key string) string {
+	out, err := exec.Command("reg", "query", key, "/v", "DisplayIcon").Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "REG_SZ", 2)
+		if len(fields) == 2 && strings.HasPrefix(fields[0], "DisplayIcon") {
+			v := strings.Trim(strings.TrimSpace(fields[1]), `"`)
+			if i := strings.LastIndex(v, ","); i > 0 {
+				v = v[:i]
+			}
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+func launchWindows(opts Options) error {
+	kind, path, err := detectWindows()
+	if err != nil {
+		return err
+	}
+	if kind == "windows-store" {
+		if opts.Minimized {
+			// Store apps can't take arguments; ask for a minimized window
+			return exec.Command("cmd", "/c", "start", "/min", "", `shell:AppsFolder\`+storeAppID).Start()
+		}
+		return exec.Command("explorer.exe", `shell:AppsFolder\`+storeAppID).Start()
+	}
+	if opts.Minimized {
+		return exec.Command(path, minimizedFlag).Start()
+	}
+	return exec.Command(path).Start()
+}
@@ -0,0 +1,41 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+)
+
+// premiumRequiredReason is shown when a playback control is used on an
+// account that can't control playback through the Web API.
+const premiumRequiredReason = "Playback control needs Spotify Premium; Spotirice is in read-only mode."
+
+// accountMsg reports whether the user's subscription allows playback control.
+type accountMsg struct{ Premium bool }
+
+// fetchAccountCmd checks the subscription level. Failures are ignored: a
+// premium-required error from a control endpoint still switches to
+// read-only mode later.
+func fetchAccountCmd(c *spotify.Client) tea.Cmd {
+	return func() tea.Msg {
+		user, err := c.CurrentUser(context.Background())
+		if err != nil || user.Product == "" {
+			return nil
+		}
+		return accountMsg{Premium: user.Product == "premium"}
+	}
+}
+
+// isPremiumRequired reports whether err is the 403 Spotify returns for player
+// commands on free accounts.
+func isPremiumRequired(err error) bool {
+	var se spotify.Error
+	if !errors.As(err, &se) || se.Status != http.StatusForbidden {
+		return false
+	}
+	return strings.Contains(strings.ToLower(se.Message), "premium")
+}
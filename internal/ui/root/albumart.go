@@ -0,0 +1,185 @@
+package root
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Spotify serves cover art as JPEG
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/graphics"
+)
+
+// Cover art size limits, in rows; below the minimum it isn't worth drawing.
+const (
+	minArtRows = 4
+	maxArtRows = 12
+)
+
+// artView is the cover art of the playing item, shown under the player
+// when album_art is set.
+type artView struct {
+	enabled  bool
+	protocol graphics.Protocol
+	url      string      // of the art being shown or fetched
+	img      image.Image // nil until fetched, or when fetching failed
+	// cache holds the last rendering, which is costly to redo every frame
+	cache *artRender
+}
+
+type artRender struct {
+	img        image.Image
+	cols, rows int
+	lines      []string
+}
+
+type albumArtMsg struct {
+	URL string
+	Img image.Image
+}
+
+// newArtView sets up cover art for the album_art setting.
+func newArtView(setting string) (artView, error) {
+	if setting == "" || setting == "off" {
+		return artView{}, nil
+	}
+	p, err := graphics.Parse(setting)
+	if err != nil {
+		return artView{}, err
+	}
+	return artView{enabled: true, protocol: p, cache: &artRender{}}, nil
+}
+
+// artURL picks the smallest image at least 300 pixels wide, or the largest
+// there is; Spotify lists them largest first.
+func artURL(images []spotify.Image) string {
+	url := ""
+	for _, img := range images {
+		if url == "" || img.Width >= 300 {
+			url = img.URL
+		}
+	}
+	return url
+}
+
+// fetchAlbumArtCmd loads cover art from the cache directory, downloading it
+// there first if needed. Failures leave the space empty.
+func fetchAlbumArtCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := cachedArt(url)
+		if err != nil {
+			return albumArtMsg{URL: url}
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return albumArtMsg{URL: url}
+		}
+		return albumArtMsg{URL: url, Img: img}
+	}
+}
+
+func cachedArt(url string) ([]byte, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(url))
+	path := filepath.Join(dir, "art", hex.EncodeToString(sum[:]))
+	if data, err := os.ReadFile(path); err == nil {
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cover art: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Without a cache it is fetched again next time
+	if os.MkdirAll(filepath.Dir(path), 0700) == nil {
+		_ = os.WriteFile(path, data, 0600)
+	}
+	return data, nil
+}
+
+// updateArt starts fetching the art at url when it changes.
+func (m RootModel) updateArt(url string) (RootModel, tea.Cmd) {
+	if !m.art.enabled || url == m.art.url {
+		return m, nil
+	}
+	m.art.url = url
+	m.art.img = nil
+	if url == "" {
+		return m, nil
+	}
+	return m, fetchAlbumArtCmd(url)
+}
+
+// renderAlbumArt draws the art in at most rows x cols cells, square on
+// screen, or returns "" when it is off, missing or doesn't fit.
+func (m RootModel) renderAlbumArt(rows, cols int) string {
+	return m.art.render(m.art.img, m.art.cache, min(rows, maxArtRows), cols)
+}
+
+// render draws img in at most rows x cols cells like renderAlbumArt, but
+// without its size cap, keeping the result in c. Other covers than the
+// playing one (a playlist's) bring their own cache.
+func (a artView) render(img image.Image, c *artRender, rows, cols int) string {
+	if !a.enabled || img == nil || c == nil {
+		return ""
+	}
+	cw, ch := graphics.CellSize()
+	artCols := rows * ch / cw
+	if artCols > cols {
+		artCols = cols
+		rows = artCols * cw / ch
+	}
+	if rows < minArtRows {
+		return ""
+	}
+
+	if c.img != img || c.cols != artCols || c.rows != rows {
+		*c = artRender{
+			img:   img,
+			cols:  artCols,
+			rows:  rows,
+			lines: graphics.Render(a.protocol, img, artCols, rows),
+		}
+	}
+	return strings.Join(c.lines, "\n")
+}
+
+// withArtCleared removes kitty art from the screen when view doesn't show
+// it; kitty keeps images on a layer of their own, which drawing text over
+// doesn't erase.
+func (m RootModel) withArtCleared(view string) string {
+	if !m.art.enabled || m.art.protocol != graphics.Kitty || strings.Contains(view, "\033_Ga=T") {
+		return view
+	}
+	return graphics.ClearKitty() + view
+}
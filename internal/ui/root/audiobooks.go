@@ -0,0 +1,339 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+type audiobook struct {
+	ID            spotify.ID              `json:"id"`
+	Name          string                  `json:"name"`
+	URI           spotify.URI             `json:"uri"`
+	Authors       []struct{ Name string } `json:"authors"`
+	TotalChapters int                     `json:"total_chapters"`
+}
+
+type chapter struct {
+	ID          spotify.ID                `json:"id"`
+	Name        string                    `json:"name"`
+	URI         spotify.URI               `json:"uri"`
+	DurationMs  int                       `json:"duration_ms"`
+	IsPlayable  *bool                     `json:"is_playable"`
+	ResumePoint spotify.ResumePointObject `json:"resume_point"`
+}
+
+func (b audiobook) author() string {
+	if len(b.Authors) == 0 {
+		return ""
+	}
+	return b.Authors[0].Name
+}
+
+// audiobookView holds the state of the audiobook browser. The view is only
+// reachable when the capability probe succeeded, since the API only exposes
+// audiobooks in some markets.
+type audiobookView struct {
+	available bool
+	visible   bool
+	items     []audiobook
+	cursor    int
+
+	searching bool
+	input     textinput.Model
+
+	// chapter listing for the opened audiobook
+	book     *audiobook
+	chapters []chapter
+	chCursor int
+}
+
+type audiobooksAvailableMsg struct{ Available bool }
+type audiobooksMsg struct{ Items []audiobook }
+type chaptersMsg struct {
+	Book     audiobook
+	Chapters []chapter
+}
+
+// probeAudiobooksCmd checks whether the audiobook endpoints work for this
+// account's market.
+func probeAudiobooksCmd(c *spotify.Client) tea.Cmd {
+	return func() tea.Msg {
+		var page struct{}
+		err := apiRequest(context.Background(), c, http.MethodGet, "me/audiobooks", url.Values{"limit": {"1"}}, &page)
+		return audiobooksAvailableMsg{Available: err == nil}
+	}
+}
+
+func fetchSavedAudiobooksCmd(c *spotify.Client) tea.Cmd {
+	return func() tea.Msg {
+		var page struct {
+			Items []audiobook `json:"items"`
+		}
+		if err := apiRequest(context.Background(), c, http.MethodGet, "me/audiobooks", url.Values{"limit": {"50"}}, &page); err != nil {
+			return errMsg{Err: err}
+		}
+		return audiobooksMsg{Items: page.Items}
+	}
+}
+
+func searchAudiobooksCmd(c *spotify.Client, query string) tea.Cmd {
+	return func() tea.Msg {
+		var result struct {
+			Audiobooks struct {
+				Items []audiobook `json:"items"`
+			} `json:"audiobooks"`
+		}
+		q := url.Values{"q": {query}, "type": {"audiobook"}, "limit": {"20"}, "market": {spotify.MarketFromToken}}
+		if err := apiRequest(context.Background(), c, http.MethodGet, "search", q, &result); err != nil {
+			return errMsg{Err: err}
+		}
+		if len(result.Audiobooks.Items) == 0 {
+			return statusMsg("No audiobooks found")
+		}
+		return audiobooksMsg{Items: result.Audiobooks.Items}
+	}
+}
+
+func fetchChaptersCmd(c *spotify.Client, book audiobook) tea.Cmd {
+	return func() tea.Msg {
+		var page struct {
+			Items []chapter `json:"items"`
+		}
+		q := url.Values{"limit": {"50"}, "market": {spotify.MarketFromToken}}
+		if err := apiRequest(context.Background(), c, http.MethodGet, "audiobooks/"+string(book.ID)+"/chapters", q, &page); err != nil {
+			return errMsg{Err: err}
+		}
+		return chaptersMsg{Book: book, Chapters: page.Items}
+	}
+}
+
+// playChapterCmd plays a chapter from its resume point.
+func playChapterCmd(c *spotify.Client, ch chapter) tea.Cmd {
+	return func() tea.Msg {
+		opts := &spotify.PlayOptions{URIs: []spotify.URI{ch.URI}}
+		if !ch.ResumePoint.FullyPlayed {
+			opts.PositionMs = ch.ResumePoint.ResumePositionMs
+		}
+		if err := c.PlayOpt(context.Background(), opts); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Playing " + ch.Name)
+	}
+}
+
+// resumeChapter returns the index of the first chapter that isn't finished.
+func resumeChapter(chapters []chapter) int {
+	for i, ch := range chapters {
+		if !ch.ResumePoint.FullyPlayed {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m RootModel) openAudiobooks() (RootModel, tea.Cmd) {
+	m.audiobooks.visible = true
+	m.audiobooks.cursor = 0
+	m.audiobooks.book = nil
+	return m, fetchSavedAudiobooksCmd(m.client)
+}
+
+func (m RootModel) updateAudiobooks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.audiobooks
+
+	if v.searching {
+		switch msg.String() {
+		case "esc":
+			v.searching = false
+		case "enter":
+			v.searching = false
+			if q := v.input.Value(); q != "" {
+				return m, searchAudiobooksCmd(m.client, q)
+			}
+		default:
+			var cmd tea.Cmd
+			v.input, cmd = v.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	// Chapter listing
+	if v.book != nil {
+		switch msg.String() {
+		case "esc":
+			v.book = nil
+			v.chapters = nil
+		case "up":
+			if v.chCursor > 0 {
+				v.chCursor--
+			}
+		case "down":
+			if v.chCursor < len(v.chapters)-1 {
+				v.chCursor++
+			}
+		case "enter", "r":
+			if msg.String() == "r" {
+				v.chCursor = resumeChapter(v.chapters)
+			}
+			if v.chCursor < len(v.chapters) {
+				ch := v.chapters[v.chCursor]
+				if m.readOnly {
+					m.status = premiumRequiredReason
+					return m, clearStatusCmd()
+				}
+				if ch.IsPlayable != nil && !*ch.IsPlayable {
+					m.status = "This chapter isn't available in your market."
+					return m, clearStatusCmd()
+				}
+				v.visible = false
+				m.burstTicksRemaining = 10
+				return m, playChapterCmd(m.client, ch)
+			}
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "a":
+		v.visible = false
+	case "/":
+		v.searching = true
+		v.input = textinput.New()
+		v.input.Placeholder = "Search audiobooks..."
+		v.input.Focus()
+		return m, v.input.Cursor.BlinkCmd()
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(v.items)-1 {
+			v.cursor++
+		}
+	case "enter":
+		if v.cursor < len(v.items) {
+			return m, fetchChaptersCmd(m.client, v.items[v.cursor])
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderAudiobooksScreen() string {
+	v := m.audiobooks
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + title(2) + footer(2)
+	maxVisible := m.height - 9
+	if maxVisible < 3 {
+		maxVisible = 3
+	}
+
+	var lines []string
+	var footer string
+	title := " 📚 Audiobooks"
+
+	switch {
+	case v.book != nil:
+		title += " › " + v.book.Name
+		start, end := visibleRange(v.chCursor, len(v.chapters), maxVisible)
+		for i := start; i < end; i++ {
+			ch := v.chapters[i]
+			mark := " "
+			if ch.ResumePoint.FullyPlayed {
+				mark = "✓"
+			}
+			progress := formatTime(ch.DurationMs)
+			if pos := int(ch.ResumePoint.ResumePositionMs); pos > 0 && !ch.ResumePoint.FullyPlayed {
+				progress = formatTime(pos) + "/" + progress
+			}
+			line := m.fitLine(fmt.Sprintf("  %s %s [%s]", mark, ch.Name, progress))
+			switch {
+			case i == v.chCursor:
+				line = selectedStyle.Render("▶ " + line[2:])
+			case ch.ResumePoint.FullyPlayed:
+				line = dimStyle.Render(line)
+			default:
+				line = normalStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		footer = "Enter play  •  r resume  •  ESC back"
+
+	default:
+		if v.searching {
+			lines = append(lines, "Search: "+v.input.View(), "")
+		}
+		if len(v.items) == 0 {
+			lines = append(lines, "No audiobooks. Press / to search.")
+		}
+		start, end := visibleRange(v.cursor, len(v.items), maxVisible)
+		for i := start; i < end; i++ {
+			b := v.items[i]
+			line := m.fitLine(fmt.Sprintf("  %s - %s (%d chapters)", b.Name, b.author(), b.TotalChapters))
+			if i == v.cursor {
+				line = selectedStyle.Render("▶ " + line[2:])
+			} else {
+				line = normalStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		footer = "Enter chapters  •  / search  •  ESC close"
+	}
+
+	lines = append(lines, "", footer)
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(title),
+		box,
+	)
+}
+
+// visibleRange returns the window of list indices to draw so that the
+// cursor stays on screen.
+func visibleRange(cursor, total, maxVisible int) (int, int) {
+	start := 0
+	if cursor >= maxVisible {
+		start = cursor - maxVisible + 1
+	}
+	end := start + maxVisible
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -0,0 +1,71 @@
+package root
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+)
+
+type audioAnalysisMsg struct {
+	ID    spotify.ID
+	Bars  []int // bar start offsets in ms
+	Beats []int // beat start offsets in ms
+}
+
+// fetchAudioAnalysisCmd loads bar and beat markers for a track. Failures are
+// swallowed on purpose: many apps no longer have access to the analysis
+// endpoint and the progress bar simply renders without ticks.
+func fetchAudioAnalysisCmd(c *spotify.Client, id spotify.ID) tea.Cmd {
+	return func() tea.Msg {
+		analysis, err := c.GetAudioAnalysis(context.Background(), id)
+		if err != nil || analysis == nil {
+			return audioAnalysisMsg{ID: id}
+		}
+		return audioAnalysisMsg{
+			ID:    id,
+			Bars:  markerOffsets(analysis.Bars),
+			Beats: markerOffsets(analysis.Beats),
+		}
+	}
+}
+
+func markerOffsets(markers []spotify.Marker) []int {
+	offsets := make([]int, 0, len(markers))
+	for _, mk := range markers {
+		offsets = append(offsets, int(mk.Start*1000))
+	}
+	return offsets
+}
+
+// barTickCells maps bar start offsets onto progress bar cells.
+func barTickCells(bars []int, durationMs, barWidth int) map[int]bool {
+	cells := make(map[int]bool, len(bars))
+	if durationMs <= 0 {
+		return cells
+	}
+	for _, start := range bars {
+		cell := int(float64(start) / float64(durationMs) * float64(barWidth))
+		if cell > 0 && cell < barWidth {
+			cells[cell] = true
+		}
+	}
+	return cells
+}
+
+// onBeat reports whether progressMs falls in the first half of a beat.
+func onBeat(beats []int, progressMs int) bool {
+	for i, start := range beats {
+		if start > progressMs {
+			return false
+		}
+		end := start + 500
+		if i+1 < len(beats) {
+			end = start + (beats[i+1]-start)/2
+		}
+		if progressMs < end {
+			return true
+		}
+	}
+	return false
+}
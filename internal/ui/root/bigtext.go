@@ -0,0 +1,170 @@
+package root
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bigFont holds 5-row block glyphs for large text. Letters are upper case
+// only; bigText folds case before looking them up.
+var bigFont = map[rune][5]string{
+	'A':  {"███", "█ █", "███", "█ █", "█ █"},
+	'B':  {"██ ", "█ █", "██ ", "█ █", "██ "},
+	'C':  {"███", "█  ", "█  ", "█  ", "███"},
+	'D':  {"██ ", "█ █", "█ █", "█ █", "██ "},
+	'E':  {"███", "█  ", "██ ", "█  ", "███"},
+	'F':  {"███", "█  ", "██ ", "█  ", "█  "},
+	'G':  {"███", "█  ", "█ █", "█ █", "███"},
+	'H':  {"█ █", "█ █", "███", "█ █", "█ █"},
+	'I':  {"███", " █ ", " █ ", " █ ", "███"},
+	'J':  {"  █", "  █", "  █", "█ █", "███"},
+	'K':  {"█ █", "█ █", "██ ", "█ █", "█ █"},
+	'L':  {"█  ", "█  ", "█  ", "█  ", "███"},
+	'M':  {"█   █", "██ ██", "█ █ █", "█   █", "█   █"},
+	'N':  {"█  █", "██ █", "█ ██", "█  █", "█  █"},
+	'O':  {"███", "█ █", "█ █", "█ █", "███"},
+	'P':  {"███", "█ █", "███", "█  ", "█  "},
+	'Q':  {"███", "█ █", "█ █", "███", "  █"},
+	'R':  {"███", "█ █", "██ ", "█ █", "█ █"},
+	'S':  {"███", "█  ", "███", "  █", "███"},
+	'T':  {"███", " █ ", " █ ", " █ ", " █ "},
+	'U':  {"█ █", "█ █", "█ █", "█ █", "███"},
+	'V':  {"█ █", "█ █", "█ █", "█ █", " █ "},
+	'W':  {"█   █", "█   █", "█ █ █", "██ ██", "█   █"},
+	'X':  {"█ █", "█ █", " █ ", "█ █", "█ █"},
+	'Y':  {"█ █", "█ █", " █ ", " █ ", " █ "},
+	'Z':  {"███", "  █", " █ ", "█  ", "███"},
+	'.':  {" ", " ", " ", " ", "█"},
+	',':  {" ", " ", " ", "█", "█"},
+	'-':  {"   ", "   ", "███", "   ", "   "},
+	'\'': {"█", "█", " ", " ", " "},
+	'!':  {"█", "█", "█", " ", "█"},
+	'?':  {"███", "  █", " ██", "   ", " █ "},
+	'&':  {" █ ", "█ █", " █ ", "█ █", " ██"},
+	'(':  {" █", "█ ", "█ ", "█ ", " █"},
+	')':  {"█ ", " █", " █", " █", "█ "},
+	'/':  {"  █", "  █", " █ ", "█  ", "█  "},
+	'0':  {"███", "█ █", "█ █", "█ █", "███"},
+	'1':  {" █ ", "██ ", " █ ", " █ ", "███"},
+	'2':  {"███", "  █", "███", "█  ", "███"},
+	'3':  {"███", "  █", "███", "  █", "███"},
+	'4':  {"█ █", "█ █", "███", "  █", "  █"},
+	'5':  {"███", "█  ", "███", "  █", "███"},
+	'6':  {"███", "█  ", "███", "█ █", "███"},
+	'7':  {"███", "  █", "  █", "  █", "  █"},
+	'8':  {"███", "█ █", "███", "█ █", "███"},
+	'9':  {"███", "█ █", "███", "  █", "███"},
+	':':  {" ", "█", " ", "█", " "},
+	' ':  {"  ", "  ", "  ", "  ", "  "},
+}
+
+// bigText renders s in bigFont, one space between glyphs. Characters
+// without a glyph are skipped.
+func bigText(s string) string {
+	var rows [5][]string
+	for _, r := range s {
+		g, ok := bigFont[unicode.ToUpper(r)]
+		if !ok {
+			continue
+		}
+		for i := range rows {
+			rows[i] = append(rows[i], g[i])
+		}
+	}
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = strings.Join(row, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// bigTextWrap renders s in large letters, wrapping words onto at most
+// maxLines lines of width cells and cutting the rest off with "..". ok is
+// false when s uses characters the font lacks (most non-Latin scripts), so
+// the caller can fall back to normal text.
+func bigTextWrap(s string, width, maxLines int) (text string, ok bool) {
+	for _, r := range s {
+		if _, has := bigFont[unicode.ToUpper(r)]; !has {
+			return "", false
+		}
+	}
+	fits := func(line string) bool { return lipgloss.Width(bigText(line)) <= width }
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := strings.TrimSpace(line + " " + word)
+		if fits(next) {
+			line = next
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = word
+	}
+	lines = append(lines, line)
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] += ".."
+	}
+	for i, l := range lines {
+		// Over-long words and the ".." marker get trimmed from the end
+		r := []rune(l)
+		for len(r) > 0 && !fits(string(r)) {
+			r = r[:len(r)-1]
+			if strings.HasSuffix(l, "..") && len(r) > 2 {
+				r = append(r[:len(r)-2], '.', '.')
+			}
+		}
+		lines[i] = bigText(string(r))
+	}
+	return strings.Join(lines, "\n\n"), true
+}
+
+// renderBigMain is the big-text display mode: title and artist in large
+// letters, centered, with the progress bar beneath.
+func (m RootModel) renderBigMain() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.TrackPlaying)).Bold(true)
+	if !m.isPlaying {
+		titleStyle = titleStyle.Foreground(lipgloss.Color(m.colors.TrackPaused))
+	}
+	artistStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Artist))
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Status))
+
+	title, artist := m.trackName, m.artistName
+	if title == "" {
+		title = "Nothing playing"
+	}
+	width := m.width - 4
+	// Two title lines and one artist line need 5+1+5+2+5 rows; drop to
+	// normal text where the terminal is shorter than that plus the bar
+	titleLines := 2
+	if m.height < 22 {
+		titleLines = 1
+	}
+	if big, ok := bigTextWrap(title, width, titleLines); ok {
+		title = big
+	} else {
+		title = truncate(title, width)
+	}
+	if big, ok := bigTextWrap(artist, width, 1); ok && m.height >= 16 {
+		artist = big
+	} else {
+		artist = truncate(artist, width)
+	}
+
+	body := lipgloss.JoinVertical(lipgloss.Center,
+		titleStyle.Render(title),
+		"",
+		artistStyle.Render(artist),
+		"",
+		m.renderProgressLine(),
+		"",
+		statusStyle.Render("B normal view  •  ? help"),
+	)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, body)
+}
@@ -0,0 +1,95 @@
+package root
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// chordTimeout is how long a prefix key waits for its second key.
+const chordTimeout = 1500 * time.Millisecond
+
+// chord is one continuation of a prefix key.
+type chord struct {
+	key  string
+	desc string
+	run  func(m RootModel) (RootModel, tea.Cmd)
+}
+
+// chords maps prefix keys to their continuations, in hint order.
+var chords = map[string][]chord{
+	"g": {
+		{"a", "album", func(m RootModel) (RootModel, tea.Cmd) {
+			return m.openPlayingURI(m.albumURI, "album")
+		}},
+		{"r", "artist", func(m RootModel) (RootModel, tea.Cmd) {
+			return m.openPlayingURI(m.artistURI, "artist")
+		}},
+		{"q", "queue", func(m RootModel) (RootModel, tea.Cmd) {
+			return m.switchTab(tabQueue)
+		}},
+		{"c", "context", func(m RootModel) (RootModel, tea.Cmd) {
+			return m.openCurrentContext()
+		}},
+		{"n", "genre radio", func(m RootModel) (RootModel, tea.Cmd) {
+			return m.openGenres()
+		}},
+	},
+}
+
+// chordTimeoutMsg cancels the prefix started at At if it is still pending.
+type chordTimeoutMsg struct{ At time.Time }
+
+// startChord makes key the pending prefix and shows its continuations.
+func (m RootModel) startChord(key string) (RootModel, tea.Cmd) {
+	at := time.Now()
+	m.chord = key
+	m.chordAt = at
+	return m, tea.Tick(chordTimeout, func(time.Time) tea.Msg { return chordTimeoutMsg{At: at} })
+}
+
+// finishChord runs the continuation of the pending prefix bound to key. Any
+// other key, including esc, just cancels the prefix.
+func (m RootModel) finishChord(key string) (RootModel, tea.Cmd) {
+	prefix := m.chord
+	m.chord = ""
+	// Only :q works without a client
+	if m.client == nil && prefix != ":" {
+		return m, nil
+	}
+	cs, _ := m.chordsFor(prefix)
+	for _, c := range cs {
+		if c.key == key {
+			return c.run(m)
+		}
+	}
+	return m, nil
+}
+
+// renderChordHint is the which-key line listing what can follow the
+// pending prefix.
+func (m RootModel) renderChordHint() string {
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.TrackPlaying)).Bold(true)
+	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Status))
+
+	parts := []string{keyStyle.Render(m.chord + "-")}
+	cs, _ := m.chordsFor(m.chord)
+	for _, c := range cs {
+		parts = append(parts, keyStyle.Render(c.key)+" "+descStyle.Render(c.desc))
+	}
+	parts = append(parts, keyStyle.Render("esc")+" "+descStyle.Render("cancel"))
+	return strings.Join(parts, descStyle.Render("  ·  "))
+}
+
+// openPlayingURI opens the album or artist of the playing track in the
+// track list.
+func (m RootModel) openPlayingURI(uri spotify.URI, what string) (RootModel, tea.Cmd) {
+	if uri == "" {
+		m.status = "The playing item has no " + what + "."
+		return m, clearStatusCmd()
+	}
+	return m, fetchContextTracksCmd(m.client, uri, m.currentTrackURI)
+}
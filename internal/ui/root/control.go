@@ -0,0 +1,109 @@
+package root
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/metolius25/spotirice/internal/platform"
+)
+
+// ControlMsg is a playback command from outside the terminal, such as a
+// line written to the control pipe.
+type ControlMsg struct {
+	Cmd  string
+	Args []string
+}
+
+// PowerMsg is a suspend, wake, lock or unlock event from
+// platform.WatchPower.
+type PowerMsg struct{ Event string }
+
+// controlKeys maps commands to the key whose restrictions they share.
+var controlKeys = map[string]string{
+	"play": "p", "pause": "p", "toggle": "p", "next": "n", "prev": "b", "volume": "+",
+	"focus": "F", "seek": "left",
+}
+
+// handleControl runs msg like the matching key press, whatever screen is open.
+func (m RootModel) handleControl(msg ControlMsg) (RootModel, tea.Cmd) {
+	if m.client == nil {
+		return m, nil
+	}
+	if key, ok := controlKeys[msg.Cmd]; ok {
+		if reason := m.controlBlockedReason(key); reason != "" {
+			m.status = reason
+			return m, clearStatusCmd()
+		}
+	}
+
+	m.burstTicksRemaining = 10
+	switch msg.Cmd {
+	case "play":
+		if !m.isPlaying {
+			return m, m.fadeResumeCmd()
+		}
+	case "pause":
+		if m.isPlaying {
+			return m, m.fadePauseCmd()
+		}
+	case "toggle":
+		if m.isPlaying {
+			return m, m.fadePauseCmd()
+		}
+		return m, m.fadeResumeCmd()
+	case "next":
+		return m, nextCmd(m.client)
+	case "prev":
+		return m, prevCmd(m.client)
+	case "like":
+		if m.currentTrackID != "" {
+			return m, toggleLikeCmd(m.client, m.currentTrackID, m.trackIsLiked)
+		}
+	case "focus":
+		return m.toggleFocus()
+	case "volume":
+		v, err := 0, strconv.ErrSyntax
+		if len(msg.Args) == 1 {
+			v, err = strconv.Atoi(msg.Args[0])
+		}
+		if err != nil || v < 0 || v > 100 {
+			m.status = "Usage: volume <0-100>"
+			return m, clearStatusCmd()
+		}
+		m.volume = v
+		m.muted = false
+		return m, setVolumeCmd(m.client, v)
+	case "seek":
+		ms, err := 0, strconv.ErrSyntax
+		if len(msg.Args) == 1 {
+			ms, err = strconv.Atoi(msg.Args[0])
+		}
+		if err != nil || ms < 0 || m.durationMs == 0 {
+			m.status = "Usage: seek <milliseconds>"
+			return m, clearStatusCmd()
+		}
+		return m.seek(min(ms, m.durationMs-1000))
+	default:
+		m.status = "Unknown command " + strconv.Quote(msg.Cmd)
+		return m, clearStatusCmd()
+	}
+	return m, nil
+}
+
+// handlePower pauses or resumes for a power event per pause_on/resume_on.
+func (m RootModel) handlePower(msg PowerMsg) (RootModel, tea.Cmd) {
+	if m.client == nil || m.readOnly {
+		return m, nil
+	}
+	switch platform.PowerAction(msg.Event, m.settings.PauseOn, m.settings.ResumeOn, m.isPlaying, m.pausedForPower) {
+	case "pause":
+		m.pausedForPower = true
+		return m, m.fadePauseCmd()
+	case "play":
+		m.pausedForPower = false
+		m.burstTicksRemaining = 10
+		return m, m.fadeResumeCmd()
+	}
+	return m, nil
+}
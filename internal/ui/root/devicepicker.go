@@ -0,0 +1,226 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// deviceEntry adds the capability flags the spotify library doesn't decode.
+type deviceEntry struct {
+	spotify.PlayerDevice
+	SupportsVolume bool `json:"supports_volume"`
+}
+
+// deviceView is the device picker overlay.
+type deviceView struct {
+	visible bool
+	devices []deviceEntry
+	cursor  int
+	gen     int  // identifies the refresh loop of the current opening
+	loaded  bool // the list has arrived and the cursor was placed on it
+}
+
+type devicesMsg struct {
+	Devices []deviceEntry
+}
+
+type deviceRefreshMsg struct{ Gen int }
+
+// deviceRefreshInterval is how often the open device picker re-enumerates.
+const deviceRefreshInterval = 5 * time.Second
+
+func deviceRefreshCmd(gen int) tea.Cmd {
+	return tea.Tick(deviceRefreshInterval, func(time.Time) tea.Msg { return deviceRefreshMsg{Gen: gen} })
+}
+
+func fetchDevicesCmd(c *spotify.Client) tea.Cmd {
+	return func() tea.Msg {
+		var resp struct {
+			Devices []deviceEntry `json:"devices"`
+		}
+		if err := apiRequest(context.Background(), c, http.MethodGet, "me/player/devices", nil, &resp); err != nil {
+			return errMsg{Err: err}
+		}
+		return devicesMsg{Devices: resp.Devices}
+	}
+}
+
+func transferPlaybackCmd(c *spotify.Client, d deviceEntry, play bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.TransferPlayback(context.Background(), d.ID, play); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Playback moved to " + d.Name + ".")
+	}
+}
+
+// setDeviceVolumeCmd changes the volume of a specific (possibly inactive)
+// device and refreshes the device list.
+func setDeviceVolumeCmd(c *spotify.Client, d deviceEntry, volume int) tea.Cmd {
+	return func() tea.Msg {
+		id := d.ID
+		if err := c.VolumeOpt(context.Background(), volume, &spotify.PlayOptions{DeviceID: &id}); err != nil {
+			return errMsg{Err: err}
+		}
+		return fetchDevicesCmd(c)()
+	}
+}
+
+func (m RootModel) openDevices() (RootModel, tea.Cmd) {
+	m.devices = deviceView{visible: true, gen: m.devices.gen + 1}
+	return m, tea.Batch(fetchDevicesCmd(m.client), deviceRefreshCmd(m.devices.gen))
+}
+
+func (m RootModel) updateDevices(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.devices
+	switch msg.String() {
+	case "enter", "p", "+", "=", "-", "_":
+		if m.readOnly {
+			m.status = premiumRequiredReason
+			return m, clearStatusCmd()
+		}
+	}
+
+	switch msg.String() {
+	case "esc", "d":
+		v.visible = false
+	case "r":
+		return m, fetchDevicesCmd(m.client)
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(v.devices)-1 {
+			v.cursor++
+		}
+	case "enter", "p":
+		if v.cursor < len(v.devices) {
+			d := v.devices[v.cursor]
+			if d.Restricted {
+				m.status = d.Name + " is restricted and can't receive playback from Spotirice."
+				return m, clearStatusCmd()
+			}
+			v.visible = false
+			m.burstTicksRemaining = 10
+			// "p" does the opposite of the transfer_play setting
+			play := m.settings.TransferPlay != (msg.String() == "p")
+			return m, transferPlaybackCmd(m.client, d, play)
+		}
+	case "+", "=", "-", "_":
+		if v.cursor < len(v.devices) {
+			d := v.devices[v.cursor]
+			if d.Restricted || !d.SupportsVolume {
+				m.status = d.Name + " doesn't support remote volume control."
+				return m, clearStatusCmd()
+			}
+			vol := int(d.Volume)
+			if msg.String() == "+" || msg.String() == "=" {
+				vol = min(vol+10, 100)
+			} else {
+				vol = max(vol-10, 0)
+			}
+			return m, setDeviceVolumeCmd(m.client, d, vol)
+		}
+	}
+	return m, nil
+}
+
+// deviceIcon returns the icon for a device type, preferring the one set in
+// the device_icons config table.
+func (m RootModel) deviceIcon(kind string) string {
+	if icon, ok := m.settings.DeviceIcons[kind]; ok {
+		return icon
+	}
+	if m.settings.ScreenReader {
+		return ""
+	}
+	switch kind {
+	case "Computer":
+		return "💻"
+	case "Smartphone", "Tablet":
+		return "📱"
+	case "Speaker", "AVR", "TV", "CastAudio", "CastVideo":
+		return "🔊"
+	}
+	return "🎵"
+}
+
+func (m RootModel) renderDevicesScreen() string {
+	v := m.devices
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	var lines []string
+	if len(v.devices) == 0 {
+		lines = append(lines, "No devices found. Open Spotify on a device.")
+	}
+	for i, d := range v.devices {
+		active := ""
+		if d.Active {
+			active = " (active)"
+		}
+		if d.Restricted {
+			active += " (restricted)"
+		}
+		volume := "  —"
+		if d.SupportsVolume && !d.Restricted {
+			volume = fmt.Sprintf("%3d%%", int(d.Volume))
+		}
+		line := fmt.Sprintf("  %s %s %s  %s%s", m.deviceIcon(d.Type), padRight(d.Name, 28), volume, padRight(d.Type, 10), active)
+		line = m.fitLine(line)
+		switch {
+		case i == v.cursor:
+			line = selectedStyle.Render("▶ " + line[2:])
+		case d.Restricted:
+			line = dimStyle.Render(line)
+		default:
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	alt := "p transfer & play"
+	if m.settings.TransferPlay {
+		alt = "p transfer paused"
+	}
+	lines = append(lines, "", "Enter transfer  •  "+alt+"  •  +/- device volume  •  r refresh  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 🔈 Devices"),
+		box,
+	)
+}
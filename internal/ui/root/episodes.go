@@ -0,0 +1,201 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+type savedEpisode struct {
+	AddedAt string              `json:"added_at"`
+	Episode spotify.EpisodePage `json:"episode"`
+}
+
+type savedEpisodesMsg struct {
+	Episodes []savedEpisode
+}
+
+type episodeRemovedMsg struct {
+	ID spotify.ID
+}
+
+// fetchSavedEpisodesCmd loads the user's saved episodes (GET /me/episodes).
+func fetchSavedEpisodesCmd(c *spotify.Client) tea.Cmd {
+	return func() tea.Msg {
+		var page struct {
+			Items []savedEpisode `json:"items"`
+		}
+		q := url.Values{"limit": {"50"}, "market": {spotify.MarketFromToken}}
+		if err := apiRequest(context.Background(), c, http.MethodGet, "me/episodes", q, &page); err != nil {
+			return errMsg{Err: err}
+		}
+		return savedEpisodesMsg{Episodes: page.Items}
+	}
+}
+
+func removeSavedEpisodeCmd(c *spotify.Client, id spotify.ID) tea.Cmd {
+	return func() tea.Msg {
+		q := url.Values{"ids": {string(id)}}
+		if err := apiRequest(context.Background(), c, http.MethodDelete, "me/episodes", q, nil); err != nil {
+			return errMsg{Err: err}
+		}
+		return episodeRemovedMsg{ID: id}
+	}
+}
+
+// playEpisodeCmd starts an episode at its resume point.
+func playEpisodeCmd(c *spotify.Client, ep spotify.EpisodePage) tea.Cmd {
+	return func() tea.Msg {
+		opts := &spotify.PlayOptions{URIs: []spotify.URI{ep.URI}}
+		if !ep.ResumePoint.FullyPlayed {
+			opts.PositionMs = ep.ResumePoint.ResumePositionMs
+		}
+		if err := c.PlayOpt(context.Background(), opts); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Playing " + ep.Name)
+	}
+}
+
+func (m RootModel) openEpisodes() (RootModel, tea.Cmd) {
+	m.showEpisodes = true
+	m.episodesCursor = 0
+	if m.playedEpisodes == nil {
+		played, err := config.LoadPlayedEpisodes()
+		if err != nil {
+			played = make(map[string]bool)
+		}
+		m.playedEpisodes = played
+	}
+	return m, fetchSavedEpisodesCmd(m.client)
+}
+
+func (m RootModel) episodePlayed(ep spotify.EpisodePage) bool {
+	return ep.ResumePoint.FullyPlayed || m.playedEpisodes[string(ep.ID)]
+}
+
+func (m RootModel) updateEpisodes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "e":
+		m.showEpisodes = false
+		return m, nil
+	case "up":
+		if m.episodesCursor > 0 {
+			m.episodesCursor--
+		}
+	case "down":
+		if m.episodesCursor < len(m.episodes)-1 {
+			m.episodesCursor++
+		}
+	case "enter":
+		if m.episodesCursor < len(m.episodes) {
+			ep := m.episodes[m.episodesCursor].Episode
+			if m.readOnly {
+				m.status = premiumRequiredReason
+				return m, clearStatusCmd()
+			}
+			if !ep.IsPlayable {
+				m.status = "This episode isn't available in your market."
+				return m, clearStatusCmd()
+			}
+			m.showEpisodes = false
+			m.burstTicksRemaining = 10
+			return m, playEpisodeCmd(m.client, ep)
+		}
+	case "x":
+		if m.episodesCursor < len(m.episodes) {
+			ep := m.episodes[m.episodesCursor].Episode
+			id := string(ep.ID)
+			m.playedEpisodes[id] = !m.playedEpisodes[id]
+			if err := config.SavePlayedEpisodes(m.playedEpisodes); err != nil {
+				return m, func() tea.Msg { return errMsg{Err: err} }
+			}
+		}
+	case "delete", "r":
+		if m.episodesCursor < len(m.episodes) {
+			return m, removeSavedEpisodeCmd(m.client, m.episodes[m.episodesCursor].Episode.ID)
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderEpisodesScreen() string {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	header := headerStyle.Render(" 🎙 Your Episodes")
+
+	var lines []string
+	if len(m.episodes) == 0 {
+		lines = append(lines, "No saved episodes (or still loading)...")
+	} else {
+		// Reserve lines for: header(1) + border(2) + padding(2) + footer(2)
+		maxVisible := m.height - 7
+		if maxVisible < 3 {
+			maxVisible = 3
+		}
+		start, end := visibleRange(m.episodesCursor, len(m.episodes), maxVisible)
+
+		for i := start; i < end; i++ {
+			ep := m.episodes[i].Episode
+			mark := " "
+			if m.episodePlayed(ep) {
+				mark = "✓"
+			}
+			progress := formatTime(int(ep.Duration_ms))
+			if pos := int(ep.ResumePoint.ResumePositionMs); pos > 0 && !m.episodePlayed(ep) {
+				progress = formatTime(pos) + "/" + progress
+			}
+			line := m.fitLine(fmt.Sprintf("  %s %s — %s [%s]", mark, ep.Name, ep.Show.Name, progress))
+			switch {
+			case i == m.episodesCursor:
+				line = selectedStyle.Render("▶ " + line[2:])
+			case m.episodePlayed(ep) || !ep.IsPlayable:
+				line = dimStyle.Render(line)
+			default:
+				line = normalStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	lines = append(lines, "", "Enter play  •  x mark played  •  r remove  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		box,
+	)
+}
@@ -0,0 +1,59 @@
+package root
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+)
+
+// explicitMark tags explicit tracks in lists.
+func explicitMark(t spotify.FullTrack) string {
+	if t.Explicit {
+		return " [E]"
+	}
+	return ""
+}
+
+// filterExplicit drops explicit tracks when explicit_filter = "hide".
+func (m RootModel) filterExplicit(tracks []spotify.FullTrack) []spotify.FullTrack {
+	if m.settings.ExplicitFilter != "hide" {
+		return tracks
+	}
+	kept := tracks[:0:0]
+	for _, t := range tracks {
+		if !t.Explicit {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// confirmExplicit implements explicit_filter = "warn": the first attempt to
+// play an explicit track only shows a warning, pressing Enter again on the
+// same track plays it. It reports whether playback may go ahead.
+func (m *RootModel) confirmExplicit(t spotify.FullTrack) bool {
+	if m.settings.ExplicitFilter != "warn" || !t.Explicit || m.explicitConfirm == t.URI {
+		m.explicitConfirm = ""
+		return true
+	}
+	m.explicitConfirm = t.URI
+	m.status = "\"" + t.Name + "\" is explicit. Press Enter again to play it."
+	return false
+}
+
+// skipExplicitCmd implements explicit_filter = "hide" for tracks that start
+// playing on their own, from an album, a playlist or another device: an
+// explicit one is skipped as soon as it is seen.
+func (m RootModel) skipExplicitCmd(name string, explicit bool) tea.Cmd {
+	if m.settings.ExplicitFilter != "hide" || !explicit || m.client == nil || m.controlBlockedReason("n") != "" {
+		return nil
+	}
+	c := m.client
+	return func() tea.Msg {
+		if err := c.Next(context.Background()); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Skipped \"" + name + "\": it is explicit.")
+	}
+}
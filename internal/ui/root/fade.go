@@ -0,0 +1,91 @@
+package root
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+)
+
+// fadeSteps bounds the volume changes of one fade; each is an API call.
+const fadeSteps = 8
+
+// fadeVolume steps the volume from one level to another over d.
+func fadeVolume(ctx context.Context, c *spotify.Client, from, to int, d time.Duration) error {
+	for i := 1; i <= fadeSteps; i++ {
+		time.Sleep(d / fadeSteps)
+		if err := c.Volume(ctx, from+(to-from)*i/fadeSteps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fadeDuration returns how long pausing and resuming fade for, or 0 when
+// they shouldn't: fade_ms unset, or a device without remote volume.
+func (m RootModel) fadeDuration() time.Duration {
+	if m.settings.FadeMs <= 0 || !m.device.SupportsVolume || m.muted || m.volume == 0 {
+		return 0
+	}
+	return time.Duration(m.settings.FadeMs) * time.Millisecond
+}
+
+// fadePauseCmd pauses, fading out first when fade_ms is set. The volume
+// is put back afterwards, so resuming from another app isn't silent.
+func (m RootModel) fadePauseCmd() tea.Cmd {
+	d := m.fadeDuration()
+	if d == 0 {
+		return pauseCmd(m.client)
+	}
+	c, volume := m.client, m.volume
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := fadeVolume(ctx, c, volume, 0, d); err != nil {
+			return errMsg{Err: err}
+		}
+		err := c.Pause(ctx)
+		if verr := c.Volume(ctx, volume); err == nil {
+			err = verr
+		}
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Paused.")
+	}
+}
+
+// fadeResumeCmd resumes like resumePlaybackCmd, fading in from silence when
+// fade_ms is set.
+func (m RootModel) fadeResumeCmd() tea.Cmd {
+	d := m.fadeDuration()
+	if d == 0 {
+		return resumePlaybackCmd(m.client, m.settings.PreferredDevice, m.lastDevice)
+	}
+	c, volume := m.client, m.volume
+	preferred, last := m.settings.PreferredDevice, m.lastDevice
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := ensureActiveDevice(c, preferred, last); err != nil {
+			return errMsg{Err: err}
+		}
+		state, err := c.PlayerState(ctx)
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		if state != nil && !state.Playing {
+			if err := c.Volume(ctx, 0); err != nil {
+				return errMsg{Err: err}
+			}
+			if err := c.Play(ctx); err != nil {
+				// Don't leave the device silent
+				_ = c.Volume(ctx, volume)
+				return errMsg{Err: err}
+			}
+			if err := fadeVolume(ctx, c, 0, volume, d); err != nil {
+				return errMsg{Err: err}
+			}
+		}
+		return statusMsg("Resumed playback.")
+	}
+}
@@ -0,0 +1,89 @@
+package root
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/metolius25/spotirice/internal/daemon"
+)
+
+// attached is set by AttachDaemon; the player then takes the state the
+// daemon polls from its notifications instead of polling Spotify.
+var attached bool
+
+// AttachDaemon makes the player a front end of the running daemon: API
+// calls go over its socket, made with its login, and the state comes from
+// its poll.
+func AttachDaemon() {
+	SetTransport(daemon.Transport{})
+	attached = true
+}
+
+type subscribedMsg struct{ sub *daemon.Subscription }
+
+// feedMsg is a state notification, as the message a poll would have given.
+type feedMsg struct{ msg tea.Msg }
+
+type feedLostMsg struct{ err error }
+
+func subscribeCmd() tea.Cmd {
+	return func() tea.Msg {
+		sub, err := daemon.Subscribe()
+		if err != nil {
+			return feedLostMsg{err}
+		}
+		return subscribedMsg{sub}
+	}
+}
+
+func nextStateCmd(sub *daemon.Subscription) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := sub.Next()
+		if err != nil {
+			return feedLostMsg{err}
+		}
+		if resp.Error != "" {
+			return feedMsg{noPlaybackMsg{Err: errors.New(resp.Error)}}
+		}
+		var state *playerStatus
+		var latency time.Duration
+		if p := resp.Player; p != nil {
+			latency = time.Duration(p.LatencyMs) * time.Millisecond
+			if len(p.State) > 0 {
+				if err := json.Unmarshal(p.State, &state); err != nil {
+					return feedMsg{noPlaybackMsg{Err: err}}
+				}
+			}
+		}
+		return feedMsg{playerState(state, latency)}
+	}
+}
+
+// handleFeed follows the daemon's state notifications. When the daemon
+// goes away, what's on screen stays and it is subscribed to again every
+// second, the poll interval of a player on its own.
+func (m RootModel) handleFeed(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case subscribedMsg:
+		m.feed = msg.sub
+		return m, nextStateCmd(msg.sub)
+
+	case feedMsg:
+		next, cmd := m.Update(msg.msg)
+		return next, tea.Batch(cmd, nextStateCmd(m.feed))
+
+	case feedLostMsg:
+		if m.feed != nil {
+			m.feed.Close()
+			m.feed = nil
+		}
+		next, cmd := m.Update(noPlaybackMsg{Err: msg.err})
+		return next, tea.Batch(cmd, tea.Tick(time.Second, func(time.Time) tea.Msg {
+			return subscribeCmd()()
+		}))
+	}
+	return m, nil
+}
@@ -0,0 +1,125 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Defaults for a [focus] table that leaves the lengths out.
+const (
+	defaultFocusMinutes = 25
+	defaultBreakMinutes = 5
+)
+
+// focusSession is a running focus mode: alternating focus and break
+// periods until it is stopped.
+type focusSession struct {
+	active  bool
+	onBreak bool
+	until   time.Time // end of the current period
+	rounds  int       // focus periods finished
+}
+
+// focusTimerMsg fires when the period ending at At is over.
+type focusTimerMsg struct{ At time.Time }
+
+func (m RootModel) focusLength(onBreak bool) time.Duration {
+	minutes := m.settings.Focus.Minutes
+	if minutes <= 0 {
+		minutes = defaultFocusMinutes
+	}
+	if onBreak {
+		minutes = m.settings.Focus.BreakMinutes
+		if minutes <= 0 {
+			minutes = defaultBreakMinutes
+		}
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// playContextURICmd starts uri from the beginning on the usual device.
+func playContextURICmd(c *spotify.Client, uri spotify.URI, preferred, last string) tea.Cmd {
+	return func() tea.Msg {
+		if err := ensureActiveDevice(c, preferred, last); err != nil {
+			return errMsg{Err: err}
+		}
+		if err := c.PlayOpt(context.Background(), &spotify.PlayOptions{PlaybackContext: &uri}); err != nil {
+			return errMsg{Err: err}
+		}
+		return nil
+	}
+}
+
+// toggleFocus starts focus mode with the focus playlist, or stops it.
+func (m RootModel) toggleFocus() (RootModel, tea.Cmd) {
+	if m.focus.active {
+		m.focus = focusSession{}
+		m.status = "Focus mode off."
+		return m, clearStatusCmd()
+	}
+	m.focus = focusSession{active: true}
+	m.status = fmt.Sprintf("Focus for %d minutes.", int(m.focusLength(false).Minutes()))
+	return m, tea.Batch(clearStatusCmd(), m.startFocusPeriod())
+}
+
+// startFocusPeriod arms the timer for the current period and starts its
+// music: the focus playlist (or whatever was playing), and during breaks
+// the break playlist or nothing.
+func (m *RootModel) startFocusPeriod() tea.Cmd {
+	m.focus.until = time.Now().Add(m.focusLength(m.focus.onBreak))
+	at := m.focus.until
+	timer := tea.Tick(time.Until(at), func(time.Time) tea.Msg { return focusTimerMsg{At: at} })
+
+	uri := m.settings.Focus.Playlist
+	if m.focus.onBreak {
+		uri = m.settings.Focus.BreakPlaylist
+	}
+	m.burstTicksRemaining = 10
+	var music tea.Cmd
+	switch {
+	case uri != "":
+		music = playContextURICmd(m.client, spotify.URI(uri), m.settings.PreferredDevice, m.lastDevice)
+	case m.focus.onBreak:
+		if m.isPlaying {
+			music = m.fadePauseCmd()
+		}
+	case !m.isPlaying:
+		music = m.fadeResumeCmd()
+	}
+	return tea.Batch(timer, music)
+}
+
+// handleFocusTimer switches between focus and break when a period ends.
+func (m RootModel) handleFocusTimer(msg focusTimerMsg) (RootModel, tea.Cmd) {
+	if !m.focus.active || !msg.At.Equal(m.focus.until) || m.client == nil {
+		return m, nil
+	}
+	if !m.focus.onBreak {
+		m.focus.rounds++
+	}
+	m.focus.onBreak = !m.focus.onBreak
+	if m.focus.onBreak {
+		m.status = fmt.Sprintf("Break time (%d done).", m.focus.rounds)
+	} else {
+		m.status = "Back to focus."
+	}
+	cmd := m.startFocusPeriod()
+	return m, tea.Batch(clearStatusCmd(), cmd)
+}
+
+// focusIndicator is the focus timer for the indicator row.
+func (m RootModel) focusIndicator() string {
+	left := time.Until(m.focus.until)
+	if left < 0 {
+		left = 0
+	}
+	clock := fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+	if m.focus.onBreak {
+		return "☕ break " + clock
+	}
+	return "🍅 focus " + clock
+}
@@ -0,0 +1,204 @@
+package root
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// maxGenreTags is how many of the artist's genres the player line shows;
+// the genre picker lists them all.
+const maxGenreTags = 3
+
+// genreRadioSize is how many tracks a genre radio queues up.
+const genreRadioSize = 50
+
+type artistGenresMsg struct {
+	ID     spotify.ID
+	Genres []string
+}
+
+// fetchArtistGenresCmd looks up the genres Spotify files an artist under.
+// Failures are dropped: the tags are decoration, and the next artist change
+// tries again.
+func fetchArtistGenresCmd(c *spotify.Client, id spotify.ID) tea.Cmd {
+	return func() tea.Msg {
+		artist, err := c.GetArtist(context.Background(), id)
+		if err != nil {
+			return nil
+		}
+		return artistGenresMsg{ID: id, Genres: artist.Genres}
+	}
+}
+
+// needsGenres reports whether the genres of the artist at uri still have to
+// be fetched.
+func (m RootModel) needsGenres(uri spotify.URI) bool {
+	if uriKind(uri) != "artist" {
+		return false
+	}
+	_, ok := m.genreCache[uriID(uri)]
+	return !ok
+}
+
+// currentGenres are the cached genres of the playing track's first artist.
+func (m RootModel) currentGenres() []string {
+	if m.trackName == "" || uriKind(m.artistURI) != "artist" {
+		return nil
+	}
+	return m.genreCache[uriID(m.artistURI)]
+}
+
+// renderGenreTags is the short genre list shown after the artist name.
+func (m RootModel) renderGenreTags() string {
+	genres := m.currentGenres()
+	if len(genres) == 0 {
+		return ""
+	}
+	if len(genres) > maxGenreTags {
+		genres = genres[:maxGenreTags]
+	}
+	tagStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status)).
+		Italic(true)
+	return tagStyle.Render(strings.Join(genres, " · "))
+}
+
+// genreView picks one of the current artist's genres to start a radio from.
+type genreView struct {
+	visible bool
+	artist  string
+	genres  []string
+	cursor  int
+}
+
+func (m RootModel) openGenres() (RootModel, tea.Cmd) {
+	genres := m.currentGenres()
+	if len(genres) == 0 {
+		m.status = "No genres known for this artist."
+		return m, clearStatusCmd()
+	}
+	if reason := m.controlBlockedReason(""); reason != "" {
+		m.status = reason
+		return m, clearStatusCmd()
+	}
+	m.genres = genreView{visible: true, artist: m.artistName, genres: genres}
+	return m, nil
+}
+
+// seedGenre turns a display genre ("indie rock") into the slug the
+// recommendations endpoint takes ("indie-rock").
+func seedGenre(genre string) string {
+	return strings.ReplaceAll(strings.ToLower(genre), " ", "-")
+}
+
+// genreRadioCmd plays a shuffled set of tracks in genre. Recommendations
+// only know a fixed list of seed genres and aren't available to every app,
+// so a genre search fills in when they come back empty or refused.
+func genreRadioCmd(c *spotify.Client, genre, preferred, last string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := ensureActiveDevice(c, preferred, last); err != nil {
+			return errMsg{Err: err}
+		}
+
+		var uris []spotify.URI
+		seeds := spotify.Seeds{Genres: []string{seedGenre(genre)}}
+		recs, err := c.GetRecommendations(ctx, seeds, nil, spotify.Limit(genreRadioSize), spotify.Country(spotify.MarketFromToken))
+		if err == nil {
+			for _, t := range recs.Tracks {
+				uris = append(uris, t.URI)
+			}
+		}
+		if len(uris) == 0 {
+			res, err := c.Search(ctx, `genre:"`+genre+`"`, spotify.SearchTypeTrack, spotify.Limit(genreRadioSize), spotify.Market(spotify.MarketFromToken))
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			if res.Tracks != nil {
+				for _, t := range res.Tracks.Tracks {
+					uris = append(uris, t.URI)
+				}
+			}
+		}
+		if len(uris) == 0 {
+			return statusMsg("No tracks found for " + genre + ".")
+		}
+
+		rand.Shuffle(len(uris), func(i, j int) { uris[i], uris[j] = uris[j], uris[i] })
+		if err := c.PlayOpt(ctx, &spotify.PlayOptions{URIs: uris}); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Genre radio: " + genre)
+	}
+}
+
+func (m RootModel) updateGenres(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.genres
+	switch msg.String() {
+	case "esc":
+		v.visible = false
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.genres)-1 {
+			v.cursor++
+		}
+	case "enter":
+		v.visible = false
+		m.burstTicksRemaining = 10
+		m.status = "Starting " + v.genres[v.cursor] + " radio..."
+		return m, genreRadioCmd(m.client, v.genres[v.cursor], m.settings.PreferredDevice, m.lastDevice)
+	}
+	return m, nil
+}
+
+func (m RootModel) renderGenresScreen() string {
+	v := m.genres
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	lines := []string{dimStyle.Render(m.fitLine("Start a radio from a genre of " + v.artist + ":")), ""}
+	for i, g := range v.genres {
+		line := m.fitLine("  " + g)
+		if i == v.cursor {
+			line = selectedStyle.Render("▶ " + line[2:])
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "Enter start radio  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 🏷 Genres"),
+		box,
+	)
+}
@@ -0,0 +1,195 @@
+package root
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// action is a player command that can be bound to keys in [keybindings].
+// Its first default key is the one Update's main switch handles it under.
+type action struct {
+	name     string
+	title    string // as listed in the command palette
+	defaults []string
+}
+
+var actions = []action{
+	{"play_pause", "Play / pause", []string{"p", " "}},
+	{"next", "Next track", []string{"n"}},
+	{"prev", "Previous track", []string{"b"}},
+	{"like", "Like / unlike the playing track", []string{"l"}},
+	{"volume_up", "Volume up", []string{"+", "="}},
+	{"volume_down", "Volume down", []string{"-", "_"}},
+	{"mute", "Mute / unmute", []string{"m", "0"}},
+	{"seek_back", "Seek back 10s", []string{"left"}},
+	{"seek_forward", "Seek forward 10s", []string{"right"}},
+	{"seek_back_long", "Seek back 30s", []string{"ctrl+left"}},
+	{"seek_forward_long", "Seek forward 30s", []string{"ctrl+right"}},
+	{"search", "Search", []string{"/", "s"}},
+	{"help", "Help", []string{"?"}},
+	{"episodes", "Your episodes", []string{"e"}},
+	{"audiobooks", "Audiobooks", []string{"a"}},
+	{"context", "Open the playing context", []string{"c"}},
+	{"smart_playlist", "Build a smart playlist", []string{"S"}},
+	{"devices", "Switch device", []string{"d"}},
+	{"party", "Party requests", []string{"R"}},
+	{"share", "Share the playing track", []string{"Q"}},
+	{"track_info", "Track info", []string{"i"}},
+	{"update", "Release notes of the new version", []string{"U"}},
+	{"theme_editor", "Edit the theme", []string{"T"}},
+	{"lyrics", "Lyrics", []string{"y"}},
+	{"playlists", "Open a playlist", []string{"P"}},
+	{"liked_songs", "Liked Songs", []string{"L"}},
+	{"profiles", "Switch profile", []string{"O"}},
+	{"add_to_playlist", "Add the playing track to a playlist", []string{"A"}},
+	{"remaining_time", "Toggle elapsed / remaining time", []string{"t"}},
+	{"sleep_timer", "Sleep timer", []string{"z"}},
+	{"focus", "Focus mode", []string{"F"}},
+	{"big_mode", "Big mode", []string{"B"}},
+	{"visualizer", "Visualizer", []string{"v"}},
+	{"command_palette", "Command palette", []string{"ctrl+p"}},
+	{"next_tab", "Next tab", []string{"tab"}},
+	{"prev_tab", "Previous tab", []string{"shift+tab"}},
+	{"player_tab", "Player", []string{"1"}},
+	{"queue_tab", "Queue", []string{"2"}},
+	{"library_tab", "Library", []string{"3"}},
+	{"search_tab", "Search tab", []string{"4"}},
+	// Ctrl+C always quits, whatever q is rebound to
+	{"quit", "Quit", []string{"q"}},
+}
+
+// keyMap translates the keys pressed on the player to the built-in keys of
+// the actions they are bound to.
+type keyMap struct {
+	keys map[string]string
+	vim  bool // the vim preset, which also adds list keys and :q
+}
+
+// newKeyMap applies the [keybindings] table, on top of the preset if one is
+// chosen, to the built-in keys. An action that is configured loses its
+// built-in keys, unless another action is bound to them.
+func newKeyMap(preset string, bindings map[string]config.KeyList) (keyMap, error) {
+	km := keyMap{keys: map[string]string{}}
+	switch preset {
+	case "", "default":
+	case "vim":
+		km.vim = true
+		merged := make(map[string]config.KeyList, len(vimBindings)+len(bindings))
+		for name, keys := range vimBindings {
+			merged[name] = keys
+		}
+		for name, keys := range bindings {
+			merged[name] = keys
+		}
+		bindings = merged
+	default:
+		return km, fmt.Errorf("unknown keymap %q (expected \"default\" or \"vim\")", preset)
+	}
+
+	byName := make(map[string]action, len(actions))
+	for _, a := range actions {
+		byName[a.name] = a
+	}
+	var unknown []string
+	for name := range bindings {
+		a, ok := byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		for _, key := range a.defaults {
+			km.keys[key] = ""
+		}
+	}
+	for name, keys := range bindings {
+		a, ok := byName[name]
+		if !ok {
+			continue
+		}
+		for _, key := range keys {
+			km.keys[keyName(key)] = a.defaults[0]
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return km, fmt.Errorf("unknown action %s", strings.Join(unknown, ", "))
+	}
+	return km, nil
+}
+
+// keyName turns the names config.toml may use into bubbletea's.
+func keyName(key string) string {
+	if key == "space" {
+		return " "
+	}
+	return key
+}
+
+// keysFor lists the keys that trigger a, for display.
+func (km keyMap) keysFor(a action) []string {
+	var keys []string
+	for _, key := range a.defaults {
+		if _, ok := km.keys[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	var bound []string
+	for key, to := range km.keys {
+		if to == a.defaults[0] {
+			bound = append(bound, key)
+		}
+	}
+	sort.Strings(bound)
+	keys = append(keys, bound...)
+	for i, key := range keys {
+		if key == " " {
+			keys[i] = "space"
+		}
+	}
+	return keys
+}
+
+// keyLabels lists keys as the help screen shows them.
+func keyLabels(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, key := range keys {
+		labels[i] = keyLabel(key)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// keyLabel spells out a key name: "ctrl+left" is shown as "Ctrl+←".
+func keyLabel(key string) string {
+	if len(key) <= 1 {
+		return key
+	}
+	var mods string
+	if i := strings.LastIndex(key[:len(key)-1], "+"); i >= 0 {
+		mods, key = key[:i+1], key[i+1:]
+	}
+	if arrow, ok := map[string]string{"left": "←", "right": "→", "up": "↑", "down": "↓"}[key]; ok {
+		key = arrow
+	} else if len(key) > 1 || mods == "ctrl+" {
+		key = strings.ToUpper(key[:1]) + key[1:]
+	}
+	var b strings.Builder
+	for _, mod := range strings.SplitAfter(mods, "+") {
+		if mod != "" {
+			b.WriteString(strings.ToUpper(mod[:1]) + mod[1:])
+		}
+	}
+	return b.String() + key
+}
+
+// resolve returns the built-in key to handle key as, and whether key is
+// bound in [keybindings], which puts it ahead of panels and chords. An
+// unbound built-in key resolves to "".
+func (km keyMap) resolve(key string) (string, bool) {
+	if k, ok := km.keys[key]; ok {
+		return k, k != ""
+	}
+	return key, false
+}
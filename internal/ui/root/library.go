@@ -0,0 +1,75 @@
+package root
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// libraryTab is the library tab: the playlists (P) or, as its other half,
+// Liked Songs (L).
+type libraryTab struct {
+	env        tabEnv
+	liked      bool // showing Liked Songs rather than the playlists
+	playlists  playlistsView
+	likedSongs likedSongsView
+}
+
+// newLibraryTab opens the library at Liked Songs, or at the playlists.
+func newLibraryTab(liked bool) libraryTab {
+	if liked {
+		return libraryTab{liked: true, likedSongs: likedSongsView{loading: true}}
+	}
+	return libraryTab{playlists: playlistsView{loading: true}}
+}
+
+func (l libraryTab) withEnv(env tabEnv) tabModel {
+	l.env = env
+	return l
+}
+
+func (l libraryTab) Init() tea.Cmd {
+	if l.liked {
+		return fetchLikedSongsPageCmd(l.env.client, 0)
+	}
+	return fetchPlaylistsPageCmd(l.env.client, 0)
+}
+
+func (l libraryTab) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case playlistsPageMsg:
+		return l.handlePlaylistsPage(msg)
+
+	case likedSongsPageMsg:
+		return l.handleLikedSongsPage(msg)
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			return l.Update(tea.KeyMsg{Type: tea.KeyUp})
+		case tea.MouseButtonWheelDown:
+			return l.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+
+	case tea.KeyMsg:
+		if l.liked {
+			return l.updateLikedSongs(msg)
+		}
+		return l.updatePlaylists(msg)
+	}
+	return l, nil
+}
+
+// more fetches the next page of the half showing, if the cursor has got
+// close enough to the end for it.
+func (l libraryTab) more() (libraryTab, tea.Cmd) {
+	if l.liked {
+		return l.moreLikedSongs()
+	}
+	return l.morePlaylists()
+}
+
+func (l libraryTab) View() string {
+	if l.liked {
+		return l.renderLikedSongs()
+	}
+	return l.renderPlaylists()
+}
@@ -0,0 +1,179 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// likedSongsView browses Liked Songs (L), newest first. Tracks unliked here
+// stay listed, without their heart, so l can undo a slip until it's closed.
+type likedSongsView struct {
+	tracks  []spotify.FullTrack
+	total   int // as reported by Spotify, for pagination
+	cursor  int
+	loading bool
+}
+
+type likedSongsPageMsg struct {
+	Offset int
+	Tracks []spotify.FullTrack
+	Total  int
+}
+
+func fetchLikedSongsPageCmd(c *spotify.Client, offset int) tea.Cmd {
+	return func() tea.Msg {
+		page, err := c.CurrentUsersTracks(context.Background(), spotify.Limit(playlistPageSize), spotify.Offset(offset), spotify.Market(spotify.MarketFromToken))
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		msg := likedSongsPageMsg{Offset: offset, Total: int(page.Total)}
+		for _, st := range page.Tracks {
+			msg.Tracks = append(msg.Tracks, st.FullTrack)
+		}
+		return msg
+	}
+}
+
+func (l libraryTab) handleLikedSongsPage(msg likedSongsPageMsg) (libraryTab, tea.Cmd) {
+	v := &l.likedSongs
+	// Pages arrive in order; anything else is from an earlier opening
+	if !l.liked || msg.Offset != len(v.tracks) {
+		return l, nil
+	}
+	v.loading = false
+	v.tracks = append(v.tracks, msg.Tracks...)
+	v.total = msg.Total
+	return l.moreLikedSongs()
+}
+
+// moreLikedSongs fetches the next page once the cursor is within a screen of
+// the end of what has been loaded.
+func (l libraryTab) moreLikedSongs() (libraryTab, tea.Cmd) {
+	v := &l.likedSongs
+	if v.loading || len(v.tracks) >= v.total || v.cursor < len(v.tracks)-l.env.height {
+		return l, nil
+	}
+	v.loading = true
+	return l, fetchLikedSongsPageCmd(l.env.client, len(v.tracks))
+}
+
+func (l libraryTab) updateLikedSongs(msg tea.KeyMsg) (libraryTab, tea.Cmd) {
+	v := &l.likedSongs
+	switch msg.String() {
+	case "esc", "L":
+		return l, request(closeTabMsg{})
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(v.tracks)-1 {
+			v.cursor++
+		}
+		return l.moreLikedSongs()
+	case "l":
+		if v.cursor < len(v.tracks) {
+			id := v.tracks[v.cursor].ID
+			return l, toggleLikeCmd(l.env.client, id, l.env.liked[id])
+		}
+	case "A":
+		if v.cursor < len(v.tracks) {
+			return l, request(addTrackToPlaylistMsg{Track: v.tracks[v.cursor]})
+		}
+	case "enter":
+		if v.cursor < len(v.tracks) {
+			// Without a context the loaded tracks from here on are played in order
+			return l, request(playTracksMsg{Tracks: v.tracks, Index: v.cursor})
+		}
+	}
+	return l, nil
+}
+
+func (l libraryTab) renderLikedSongs() string {
+	v := l.likedSongs
+	colors := l.env.colors
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(l.env.border).
+		BorderForeground(lipgloss.Color(colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.TrackPlaying)).
+		Bold(true)
+
+	playingStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.TrackPaused))
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Status)).
+		Faint(true)
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + footer(2)
+	maxVisible := max(l.env.height-7, 3)
+
+	var lines []string
+	switch {
+	case len(v.tracks) == 0 && v.loading:
+		lines = append(lines, dimStyle.Render("Loading Liked Songs..."))
+	case len(v.tracks) == 0:
+		lines = append(lines, "You haven't liked any songs yet.")
+	}
+	start, end := visibleRange(v.cursor, len(v.tracks), maxVisible)
+	for i := start; i < end; i++ {
+		t := v.tracks[i]
+		marker := "  "
+		if t.URI == l.env.playing {
+			marker = "♪ "
+		}
+		line := fmt.Sprintf("  %s%s%s - %s%s", marker, t.Name, explicitMark(t), trackArtist(t), l.env.likedMark(t.ID))
+		unavailable := unplayableReason(t) != ""
+		if unavailable {
+			line += " (unavailable)"
+		}
+		line = l.env.fitLine(line)
+		switch {
+		case i == v.cursor:
+			line = selectedStyle.Render("▶ " + line[2:])
+		case unavailable || !l.env.liked[t.ID]:
+			line = dimStyle.Render(line)
+		case t.URI == l.env.playing:
+			line = playingStyle.Render(line)
+		default:
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "Enter play  •  l like/unlike  •  A add to playlist  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := l.env.width - containerStyle.GetHorizontalBorderSize()
+	h := l.env.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	title := " ♥ Liked Songs"
+	if v.total > 0 {
+		title += fmt.Sprintf(" (%d)", v.total)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		withTabBar(colors, tabLibrary, headerStyle.Render(title)),
+		box,
+	)
+}
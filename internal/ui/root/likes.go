@@ -0,0 +1,136 @@
+package root
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+)
+
+// likeBatchSize is the maximum number of IDs UserHasTracks accepts.
+const likeBatchSize = 50
+
+type likedStatusMsg struct {
+	Liked map[spotify.ID]bool
+}
+
+// likeToggledMsg confirms a single like/unlike, replacing the cached status.
+type likeToggledMsg struct {
+	ID    spotify.ID
+	Liked bool
+}
+
+// fetchLikedCmd looks up the Liked Songs status of ids in batches of 50.
+func fetchLikedCmd(c *spotify.Client, ids []spotify.ID) tea.Cmd {
+	if len(ids) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx := context.Background()
+		liked := make(map[spotify.ID]bool, len(ids))
+		for start := 0; start < len(ids); start += likeBatchSize {
+			end := start + likeBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			batch := ids[start:end]
+			has, err := c.UserHasTracks(ctx, batch...)
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			for i, id := range batch {
+				if i < len(has) {
+					liked[id] = has[i]
+				}
+			}
+		}
+		return likedStatusMsg{Liked: liked}
+	}
+}
+
+// fetchMissingLikesCmd requests the liked status for tracks that aren't in
+// the like cache yet.
+func (m RootModel) fetchMissingLikesCmd(tracks []spotify.FullTrack) tea.Cmd {
+	seen := make(map[spotify.ID]bool)
+	var ids []spotify.ID
+	for _, t := range tracks {
+		if t.ID == "" || seen[t.ID] {
+			continue
+		}
+		if _, ok := m.likeCache[t.ID]; ok {
+			continue
+		}
+		seen[t.ID] = true
+		ids = append(ids, t.ID)
+	}
+	return fetchLikedCmd(m.client, ids)
+}
+
+// likedMark returns the heart glyph for tracks known to be in Liked Songs.
+func (m RootModel) likedMark(id spotify.ID) string {
+	return heartFor(m.likeCache, id)
+}
+
+// heartFor is likedMark going by the like cache liked.
+func heartFor(liked map[spotify.ID]bool, id spotify.ID) string {
+	if liked[id] {
+		return " ♥"
+	}
+	return ""
+}
+
+type likeBatchMsg struct {
+	Add       bool
+	Batch     []spotify.ID
+	Remaining []spotify.ID
+	Done      int
+	Total     int
+}
+
+// batchLikeCmd adds or removes the first batch of ids from Liked Songs and
+// reports progress; Update schedules the next batch until none remain.
+func batchLikeCmd(c *spotify.Client, ids []spotify.ID, add bool, done, total int) tea.Cmd {
+	return func() tea.Msg {
+		end := likeBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[:end]
+		var err error
+		if add {
+			err = c.AddTracksToLibrary(context.Background(), batch...)
+		} else {
+			err = c.RemoveTracksFromLibrary(context.Background(), batch...)
+		}
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return likeBatchMsg{Add: add, Batch: batch, Remaining: ids[end:], Done: done + len(batch), Total: total}
+	}
+}
+
+// startBatchLike likes or unlikes the selected tracks in batches of 50.
+func (m RootModel) startBatchLike(tracks []spotify.FullTrack, selected map[spotify.ID]bool, add bool) (RootModel, tea.Cmd) {
+	var ids []spotify.ID
+	for _, t := range tracks {
+		if t.ID != "" && selected[t.ID] {
+			ids = append(ids, t.ID)
+		}
+	}
+	if len(ids) == 0 {
+		m.status = "No tracks selected."
+		return m, clearStatusCmd()
+	}
+	return m, batchLikeCmd(m.client, ids, add, 0, len(ids))
+}
+
+// selectionMark returns the checkbox shown in front of multi-select lists.
+func selectionMark(selected map[spotify.ID]bool, id spotify.ID) string {
+	if len(selected) == 0 {
+		return ""
+	}
+	if selected[id] {
+		return "[x] "
+	}
+	return "[ ] "
+}
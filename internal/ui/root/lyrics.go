@@ -0,0 +1,173 @@
+package root
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/metolius25/spotirice/internal/lyrics"
+)
+
+// lyricsView is the lyrics screen (y): synced lyrics follow the playing
+// position, plain ones are scrolled by hand.
+type lyricsView struct {
+	visible bool
+	song    lyrics.Song // what was looked up last
+	lyrics  *lyrics.Lyrics
+	loading bool
+	err     error
+	scroll  int // first plain line shown
+}
+
+type lyricsMsg struct {
+	Song   lyrics.Song
+	Lyrics *lyrics.Lyrics
+	Err    error
+}
+
+func fetchLyricsCmd(song lyrics.Song) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		l, err := lyrics.Fetch(ctx, song)
+		return lyricsMsg{Song: song, Lyrics: l, Err: err}
+	}
+}
+
+func (m RootModel) playingSong() lyrics.Song {
+	return lyrics.Song{Track: m.trackName, Artist: m.artistName, Album: m.albumName, DurationMs: m.durationMs}
+}
+
+// lyricsCmd looks up the lyrics of the playing track unless they are the
+// ones already shown.
+func (m RootModel) lyricsCmd() (RootModel, tea.Cmd) {
+	song := m.playingSong()
+	if song == m.lyrics.song {
+		return m, nil
+	}
+	m.lyrics = lyricsView{visible: true, song: song, loading: true}
+	return m, fetchLyricsCmd(song)
+}
+
+func (m RootModel) openLyrics() (RootModel, tea.Cmd) {
+	if m.trackName == "" || m.playingType == "episode" {
+		m.status = "Lyrics are only available for songs."
+		return m, clearStatusCmd()
+	}
+	m.lyrics.visible = true
+	return m.lyricsCmd()
+}
+
+func (m RootModel) handleLyrics(msg lyricsMsg) (RootModel, tea.Cmd) {
+	// A late answer for the previous track is dropped
+	if msg.Song != m.lyrics.song {
+		return m, nil
+	}
+	m.lyrics.loading = false
+	m.lyrics.lyrics = msg.Lyrics
+	m.lyrics.err = msg.Err
+	return m, nil
+}
+
+func (m RootModel) updateLyrics(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.lyrics
+	switch msg.String() {
+	case "esc", "y":
+		v.visible = false
+	case "r":
+		// Retry, e.g. after a network error
+		v.song = lyrics.Song{}
+		return m.lyricsCmd()
+	case "up", "k":
+		if v.scroll > 0 {
+			v.scroll--
+		}
+	case "down", "j":
+		if v.lyrics != nil && v.scroll < len(v.lyrics.Plain)-1 {
+			v.scroll++
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderLyricsScreen() string {
+	v := m.lyrics
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	currentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Error))
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + title(2) + footer(2)
+	maxVisible := max(m.height-9, 3)
+
+	lines := []string{dimStyle.Render(m.fitLine(v.song.Track + " – " + v.song.Artist)), ""}
+	footer := "r retry  •  ESC close"
+	switch {
+	case v.loading:
+		lines = append(lines, dimStyle.Render("Loading lyrics..."))
+	case v.err != nil:
+		lines = append(lines, errorStyle.Render(m.fitLine("Couldn't load lyrics: "+v.err.Error())))
+	case v.lyrics == nil:
+		lines = append(lines, dimStyle.Render("lrclib.net has no lyrics for this song."))
+	case v.lyrics.Instrumental:
+		lines = append(lines, dimStyle.Render("♪ Instrumental ♪"))
+	case len(v.lyrics.Synced) > 0:
+		// The current line stays a third of the way down
+		synced := v.lyrics.Synced
+		cur := lyrics.Current(synced, m.progressMs)
+		start := min(max(cur-maxVisible/3, 0), max(len(synced)-maxVisible, 0))
+		for i := start; i < min(start+maxVisible, len(synced)); i++ {
+			text := synced[i].Text
+			if text == "" {
+				text = "♪"
+			}
+			if i == cur {
+				lines = append(lines, currentStyle.Render(m.fitLine(text)))
+			} else {
+				lines = append(lines, dimStyle.Render(m.fitLine(text)))
+			}
+		}
+		footer = "ESC close"
+	default:
+		plain := v.lyrics.Plain
+		end := min(v.scroll+maxVisible, len(plain))
+		for _, line := range plain[v.scroll:end] {
+			lines = append(lines, m.fitLine(line))
+		}
+		footer = "No synced lyrics  •  ↑/↓ scroll  •  ESC close"
+	}
+
+	lines = append(lines, "", footer)
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 🎤 Lyrics"),
+		box,
+	)
+}
@@ -0,0 +1,254 @@
+package root
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// paletteCommand is one entry of the command palette.
+type paletteCommand struct {
+	title string
+	keys  string // how to reach it without the palette, if at all
+	run   func(m RootModel) (tea.Model, tea.Cmd)
+}
+
+// paletteView is the command palette (Ctrl+P): every action, found by
+// typing part of its name.
+type paletteView struct {
+	visible bool
+	input   textinput.Model
+	matches []paletteCommand
+	cursor  int
+}
+
+// paletteCommands lists the actions under the current keymap, then the g
+// chords, then what only the palette offers.
+func (m RootModel) paletteCommands() []paletteCommand {
+	var cmds []paletteCommand
+	for _, a := range actions {
+		if a.name == "command_palette" {
+			continue
+		}
+		key := a.defaults[0]
+		cmds = append(cmds, paletteCommand{
+			title: a.title,
+			keys:  strings.Join(m.keys.keysFor(a), " "),
+			run:   func(m RootModel) (tea.Model, tea.Cmd) { return m.runKey(key) },
+		})
+	}
+	for _, c := range chords["g"] {
+		run := c.run
+		cmds = append(cmds, paletteCommand{
+			title: "Go to " + c.desc,
+			keys:  "g " + c.key,
+			run: func(m RootModel) (tea.Model, tea.Cmd) {
+				if m.client == nil {
+					return m, nil
+				}
+				return run(m)
+			},
+		})
+	}
+	cmds = append(cmds,
+		paletteCommand{title: "Toggle shuffle", run: func(m RootModel) (tea.Model, tea.Cmd) {
+			if reason := m.controlBlockedReason("p"); reason != "" {
+				m.status = reason
+				return m, clearStatusCmd()
+			}
+			m.burstTicksRemaining = 10
+			return m, shuffleCmd(m.client, !m.shuffle)
+		}},
+		paletteCommand{title: "Cycle repeat (off, context, track)", run: func(m RootModel) (tea.Model, tea.Cmd) {
+			if reason := m.controlBlockedReason("p"); reason != "" {
+				m.status = reason
+				return m, clearStatusCmd()
+			}
+			m.burstTicksRemaining = 10
+			return m, repeatCmd(m.client, nextRepeat(m.repeat))
+		}},
+	)
+	return cmds
+}
+
+func shuffleCmd(c *spotify.Client, on bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.Shuffle(context.Background(), on); err != nil {
+			return errMsg{Err: err}
+		}
+		if on {
+			return statusMsg("Shuffle on")
+		}
+		return statusMsg("Shuffle off")
+	}
+}
+
+func repeatCmd(c *spotify.Client, state string) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.Repeat(context.Background(), state); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Repeat: " + state)
+	}
+}
+
+// nextRepeat is the repeat state after state, in Spotify's own order.
+func nextRepeat(state string) string {
+	switch state {
+	case "off", "":
+		return "context"
+	case "context":
+		return "track"
+	}
+	return "off"
+}
+
+func (m RootModel) openPalette() (RootModel, tea.Cmd) {
+	m.palette = paletteView{visible: true, input: textinput.New()}
+	m.palette.input.Placeholder = "Type a command..."
+	m.palette.input.Focus()
+	m.palette.matches = m.paletteCommands()
+	return m, m.palette.input.Cursor.BlinkCmd()
+}
+
+// filterPalette ranks the commands matching the typed text, best first.
+func (m RootModel) filterPalette() []paletteCommand {
+	query := strings.TrimSpace(m.palette.input.Value())
+	all := m.paletteCommands()
+	if query == "" {
+		return all
+	}
+	type scored struct {
+		cmd   paletteCommand
+		score int
+	}
+	var found []scored
+	for _, c := range all {
+		if score, ok := fuzzyScore(query, c.title); ok {
+			found = append(found, scored{c, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	matches := make([]paletteCommand, len(found))
+	for i, f := range found {
+		matches[i] = f.cmd
+	}
+	return matches
+}
+
+// fuzzyScore matches the letters of query in order anywhere in text,
+// ignoring case. Letters that follow each other or start a word score
+// higher.
+func fuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if q[qi] != t[ti] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) {
+			score += 3
+		}
+		prev = ti
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+func (m RootModel) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.palette
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		v.visible = false
+		return m, nil
+	case "up", "ctrl+p":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if v.cursor < len(v.matches)-1 {
+			v.cursor++
+		}
+		return m, nil
+	case "enter":
+		if v.cursor < len(v.matches) {
+			v.visible = false
+			return v.matches[v.cursor].run(m)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(msg)
+	v.matches = m.filterPalette()
+	v.cursor = 0
+	return m, cmd
+}
+
+func (m RootModel) renderPaletteScreen() string {
+	v := m.palette
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	lines := []string{v.input.View(), ""}
+	// Reserve lines for: header(1) + border(2) + padding(2) + input(2) + footer(2)
+	maxVisible := max(m.height-9, 3)
+	if len(v.matches) == 0 {
+		lines = append(lines, dimStyle.Render("No matching command."))
+	}
+	start, end := visibleRange(v.cursor, len(v.matches), maxVisible)
+	for i := start; i < end; i++ {
+		c := v.matches[i]
+		line := m.fitLine("  " + padRight(c.title, 40) + " " + c.keys)
+		if i == v.cursor {
+			line = selectedStyle.Render("▶ " + line[2:])
+		} else {
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "Enter run  •  ↑/↓ select  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" ⌘ Commands"),
+		box,
+	)
+}
@@ -0,0 +1,155 @@
+package root
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/scripts"
+)
+
+// panelView is a [[panels]] screen: whatever its program printed for the
+// current track, ANSI colors included.
+type panelView struct {
+	visible bool
+	index   int // into settings.Panels
+	lines   []string
+	err     error
+	loading bool
+	scroll  int
+	gen     int // identifies the refresh loop of the current opening
+}
+
+type panelOutputMsg struct {
+	Gen    int
+	Output string
+	Err    error
+}
+
+type panelRefreshMsg struct{ Gen int }
+
+func (m RootModel) panelConfig() config.Panel {
+	return m.settings.Panels[m.panel.index]
+}
+
+// panelForKey returns the index of the panel bound to key.
+func (m RootModel) panelForKey(key string) (int, bool) {
+	for i, p := range m.settings.Panels {
+		if p.Key == key && p.Run != "" {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// runPanelCmd runs the panel's program with the playback state, like a
+// script_keys script.
+func (m RootModel) runPanelCmd() tea.Cmd {
+	run, gen := m.panelConfig().Run, m.panel.gen
+	st := m.scriptState("panel")
+	return func() tea.Msg {
+		out, err := scripts.Output(context.Background(), run, st)
+		return panelOutputMsg{Gen: gen, Output: out, Err: err}
+	}
+}
+
+func (m RootModel) panelRefreshCmd() tea.Cmd {
+	secs := m.panelConfig().Refresh
+	if secs <= 0 {
+		return nil
+	}
+	gen := m.panel.gen
+	return tea.Tick(time.Duration(secs)*time.Second, func(time.Time) tea.Msg { return panelRefreshMsg{Gen: gen} })
+}
+
+func (m RootModel) openPanel(index int) (RootModel, tea.Cmd) {
+	m.panel = panelView{visible: true, index: index, loading: true, gen: m.panel.gen + 1}
+	return m, tea.Batch(m.runPanelCmd(), m.panelRefreshCmd())
+}
+
+func (m RootModel) handlePanelOutput(msg panelOutputMsg) (RootModel, tea.Cmd) {
+	if !m.panel.visible || msg.Gen != m.panel.gen {
+		return m, nil
+	}
+	m.panel.loading = false
+	m.panel.err = msg.Err
+	if msg.Err == nil {
+		m.panel.lines = strings.Split(strings.TrimRight(msg.Output, "\n"), "\n")
+		m.panel.scroll = min(m.panel.scroll, max(len(m.panel.lines)-1, 0))
+	}
+	return m, nil
+}
+
+func (m RootModel) updatePanel(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.panel
+	switch msg.String() {
+	case "esc", m.panelConfig().Key:
+		v.visible = false
+	case "r":
+		v.loading = true
+		return m, m.runPanelCmd()
+	case "up", "k":
+		if v.scroll > 0 {
+			v.scroll--
+		}
+	case "down", "j":
+		if v.scroll < len(v.lines)-1 {
+			v.scroll++
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderPanelScreen() string {
+	v := m.panel
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Error))
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + footer(2)
+	maxVisible := max(m.height-7, 3)
+
+	var lines []string
+	switch {
+	case v.err != nil:
+		lines = append(lines, errorStyle.Render(m.fitLine(v.err.Error())))
+	case v.loading && v.lines == nil:
+		lines = append(lines, dimStyle.Render("Loading..."))
+	}
+	end := min(v.scroll+maxVisible, len(v.lines))
+	for _, line := range v.lines[v.scroll:end] {
+		lines = append(lines, m.fitLine(line))
+	}
+
+	lines = append(lines, "", "↑/↓ scroll  •  r refresh  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" "+m.panelConfig().Name),
+		box,
+	)
+}
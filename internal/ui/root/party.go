@@ -0,0 +1,194 @@
+package root
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/metolius25/spotirice/internal/daemon"
+)
+
+// partyPollInterval is how often the daemon is asked for new requests while
+// party mode is configured.
+const partyPollInterval = 3 * time.Second
+
+// partyView is the party request overlay. The requests themselves live in
+// the daemon, which serves the guest page.
+type partyView struct {
+	visible bool
+	state   daemon.PartyState
+	cursor  int
+}
+
+type partyStateMsg struct{ State *daemon.PartyState }
+
+type partyPollMsg struct{}
+
+// fetchPartyCmd asks the daemon for the pending requests. Without a daemon
+// (or with party mode off there) the list is just empty.
+func fetchPartyCmd() tea.Cmd {
+	return func() tea.Msg {
+		resp, err := daemon.Send(daemon.Request{Cmd: "party"})
+		if err != nil || resp.Party == nil {
+			return partyStateMsg{}
+		}
+		return partyStateMsg{State: resp.Party}
+	}
+}
+
+func partyPollCmd() tea.Cmd {
+	return tea.Tick(partyPollInterval, func(time.Time) tea.Msg { return partyPollMsg{} })
+}
+
+// partyCommandCmd sends a party command and refreshes the list.
+func partyCommandCmd(cmd string, args ...string) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := daemon.Send(daemon.Request{Cmd: cmd, Args: args})
+		if err == nil && resp.Error != "" {
+			err = fmt.Errorf("%s", resp.Error)
+		}
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return fetchPartyCmd()()
+	}
+}
+
+func (m RootModel) handlePartyState(msg partyStateMsg) (RootModel, tea.Cmd) {
+	if msg.State == nil {
+		m.party.state = daemon.PartyState{}
+	} else {
+		m.party.state = *msg.State
+	}
+	m.party.cursor = min(m.party.cursor, max(len(m.party.state.Pending)-1, 0))
+	return m, nil
+}
+
+func (m RootModel) openParty() (RootModel, tea.Cmd) {
+	if !daemon.Running() {
+		m.status = "Party mode runs in the daemon: start `spotirice daemon` first."
+		return m, clearStatusCmd()
+	}
+	m.party.visible = true
+	return m, fetchPartyCmd()
+}
+
+func (m RootModel) updateParty(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.party
+	pending := v.state.Pending
+	switch msg.String() {
+	case "esc", "R":
+		v.visible = false
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(pending)-1 {
+			v.cursor++
+		}
+	case "enter", "x", "delete":
+		if v.cursor < len(pending) {
+			cmd := "party-accept"
+			if msg.String() != "enter" {
+				cmd = "party-reject"
+			}
+			m.burstTicksRemaining = 10
+			return m, partyCommandCmd(cmd, strconv.Itoa(pending[v.cursor].ID))
+		}
+	case "a":
+		auto := "on"
+		if v.state.AutoAccept {
+			auto = "off"
+		}
+		return m, partyCommandCmd("party-auto", auto)
+	}
+	return m, nil
+}
+
+// partyIndicator is the request count for the indicator row, or "".
+func (m RootModel) partyIndicator() string {
+	switch n := len(m.party.state.Pending); n {
+	case 0:
+		return ""
+	case 1:
+		return "🎉 1 request (R)"
+	default:
+		return fmt.Sprintf("🎉 %d requests (R)", n)
+	}
+}
+
+func (m RootModel) renderPartyScreen() string {
+	v := m.party
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + auto(2) + footer(2)
+	maxVisible := max(m.height-9, 3)
+
+	auto := "off: requests wait here"
+	if v.state.AutoAccept {
+		auto = "on: requests go straight to the queue"
+	}
+	lines := []string{dimStyle.Render("Auto-accept " + auto), ""}
+	if len(v.state.Pending) == 0 {
+		lines = append(lines, "No requests. Guests can send some from "+m.settings.Party.Addr+".")
+	}
+	start, end := visibleRange(v.cursor, len(v.state.Pending), maxVisible)
+	for i := start; i < end; i++ {
+		r := v.state.Pending[i]
+		from := ""
+		if r.Guest != "" {
+			from = "  from " + r.Guest
+		}
+		age := "just now"
+		if mins := int(time.Since(r.At).Minutes()); mins > 0 {
+			age = fmt.Sprintf("%d min ago", mins)
+		}
+		line := fmt.Sprintf("  %s - %s%s  (%s)", r.Track, r.Artist, from, age)
+		line = m.fitLine(line)
+		if i == v.cursor {
+			line = selectedStyle.Render("▶ " + line[2:])
+		} else {
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "Enter accept (queue)  •  x reject  •  a toggle auto-accept  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 🎉 Party requests"),
+		box,
+	)
+}
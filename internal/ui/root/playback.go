@@ -0,0 +1,41 @@
+package root
+
+import (
+	"github.com/metolius25/spotirice/internal/mpris"
+)
+
+// playbackListener is told the playback state after every poll; see
+// OnPlayback.
+var playbackListener func(mpris.State)
+
+// OnPlayback has fn called with the playback state after every poll, from
+// the program's update loop, so fn must not block.
+func OnPlayback(fn func(mpris.State)) {
+	playbackListener = fn
+}
+
+func (m RootModel) publishPlayback() {
+	if playbackListener == nil {
+		return
+	}
+	st := mpris.State{
+		Playing:    m.isPlaying,
+		URI:        string(m.currentTrackURI),
+		Title:      m.trackName,
+		Album:      m.albumName,
+		ArtURL:     m.artURL,
+		LengthMs:   m.durationMs,
+		PositionMs: m.progressMs,
+		Volume:     m.volume,
+		Shuffle:    m.shuffle,
+		Repeat:     m.repeat,
+		CanControl: m.controlBlockedReason("p") == "",
+	}
+	if m.artistName != "" {
+		st.Artists = []string{m.artistName}
+	}
+	if kind, id := uriKind(m.currentTrackURI), uriID(m.currentTrackURI); kind != "" && id != "" {
+		st.URL = "https://open.spotify.com/" + kind + "/" + string(id)
+	}
+	playbackListener(st)
+}
@@ -0,0 +1,249 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// playlistContents caches the track IDs of a playlist at a given snapshot.
+type playlistContents struct {
+	snapshot string
+	ids      map[spotify.ID]bool
+}
+
+// addToPlaylistView is the overlay for picking a playlist to add a track to.
+type addToPlaylistView struct {
+	visible   bool
+	track     spotify.FullTrack
+	playlists []spotify.SimplePlaylist
+	cursor    int
+
+	// pending is set while the contents of the chosen playlist are loading
+	pending *spotify.SimplePlaylist
+	// duplicate is set when the track is already in the chosen playlist and
+	// the user has to confirm adding it again
+	duplicate *spotify.SimplePlaylist
+}
+
+type editablePlaylistsMsg struct {
+	Playlists []spotify.SimplePlaylist
+}
+
+type playlistContentsMsg struct {
+	ID       spotify.ID
+	Contents playlistContents
+}
+
+type trackAddedToPlaylistMsg struct {
+	Playlist spotify.SimplePlaylist
+	TrackID  spotify.ID
+	Snapshot string
+}
+
+// fetchEditablePlaylistsCmd lists playlists the user can add tracks to:
+// their own and collaborative ones.
+func fetchEditablePlaylistsCmd(c *spotify.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		user, err := c.CurrentUser(ctx)
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		page, err := c.CurrentUsersPlaylists(ctx, spotify.Limit(50))
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		var editable []spotify.SimplePlaylist
+		for {
+			for _, p := range page.Playlists {
+				if p.Owner.ID == user.ID || p.Collaborative {
+					editable = append(editable, p)
+				}
+			}
+			if err := c.NextPage(ctx, page); err != nil {
+				if !errors.Is(err, spotify.ErrNoMorePages) {
+					return errMsg{Err: err}
+				}
+				break
+			}
+		}
+		return editablePlaylistsMsg{Playlists: editable}
+	}
+}
+
+// fetchPlaylistContentsCmd loads the track IDs of a playlist for duplicate
+// detection.
+func fetchPlaylistContentsCmd(c *spotify.Client, p spotify.SimplePlaylist) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		page, err := c.GetPlaylistItems(ctx, p.ID, spotify.Fields("items(track(id,type)),next"))
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		contents := playlistContents{snapshot: p.SnapshotID, ids: make(map[spotify.ID]bool)}
+		for {
+			for _, item := range page.Items {
+				if item.Track.Track != nil {
+					contents.ids[item.Track.Track.ID] = true
+				}
+			}
+			if err := c.NextPage(ctx, page); err != nil {
+				if !errors.Is(err, spotify.ErrNoMorePages) {
+					return errMsg{Err: err}
+				}
+				break
+			}
+		}
+		return playlistContentsMsg{ID: p.ID, Contents: contents}
+	}
+}
+
+func addTrackToPlaylistCmd(c *spotify.Client, p spotify.SimplePlaylist, trackID spotify.ID) tea.Cmd {
+	return func() tea.Msg {
+		snapshot, err := c.AddTracksToPlaylist(context.Background(), p.ID, trackID)
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return trackAddedToPlaylistMsg{Playlist: p, TrackID: trackID, Snapshot: snapshot}
+	}
+}
+
+// openAddToPlaylist starts the add-to-playlist flow for a track.
+func (m RootModel) openAddToPlaylist(track spotify.FullTrack) (RootModel, tea.Cmd) {
+	if track.ID == "" || isLocalTrack(track) {
+		m.status = "Only Spotify tracks can be added to playlists."
+		return m, clearStatusCmd()
+	}
+	m.addToPlaylist = addToPlaylistView{visible: true, track: track}
+	return m, fetchEditablePlaylistsCmd(m.client)
+}
+
+// tryAddToPlaylist checks the cached contents of p and either adds the track,
+// asks for confirmation, or loads the contents first.
+func (m RootModel) tryAddToPlaylist(p spotify.SimplePlaylist) (RootModel, tea.Cmd) {
+	v := &m.addToPlaylist
+	contents, ok := m.playlistCache[p.ID]
+	if !ok || contents.snapshot != p.SnapshotID {
+		v.pending = &p
+		return m, fetchPlaylistContentsCmd(m.client, p)
+	}
+	v.pending = nil
+	if contents.ids[v.track.ID] {
+		v.duplicate = &p
+		return m, nil
+	}
+	v.visible = false
+	return m, addTrackToPlaylistCmd(m.client, p, v.track.ID)
+}
+
+func (m RootModel) updateAddToPlaylist(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.addToPlaylist
+
+	if v.duplicate != nil {
+		switch msg.String() {
+		case "y", "enter":
+			p := *v.duplicate
+			v.duplicate = nil
+			v.visible = false
+			return m, addTrackToPlaylistCmd(m.client, p, v.track.ID)
+		case "n", "esc":
+			v.duplicate = nil
+			m.status = "Skipped: already in playlist."
+			return m, clearStatusCmd()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		v.visible = false
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(v.playlists)-1 {
+			v.cursor++
+		}
+	case "enter":
+		if v.cursor < len(v.playlists) && v.pending == nil {
+			return m.tryAddToPlaylist(v.playlists[v.cursor])
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderAddToPlaylistScreen() string {
+	v := m.addToPlaylist
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	warnStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Error)).
+		Bold(true)
+
+	lines := []string{fmt.Sprintf("Add \"%s - %s\" to:", v.track.Name, trackArtist(v.track)), ""}
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + title(2) + footer(2)
+	maxVisible := m.height - 9
+	if maxVisible < 3 {
+		maxVisible = 3
+	}
+	if len(v.playlists) == 0 {
+		lines = append(lines, "Loading playlists...")
+	}
+	start, end := visibleRange(v.cursor, len(v.playlists), maxVisible)
+	for i := start; i < end; i++ {
+		line := m.fitLine(fmt.Sprintf("  %s (%d tracks)", v.playlists[i].Name, v.playlists[i].Tracks.Total))
+		if i == v.cursor {
+			line = selectedStyle.Render("▶ " + line[2:])
+		} else {
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	switch {
+	case v.duplicate != nil:
+		lines = append(lines, warnStyle.Render(fmt.Sprintf("Already in %s. Add anyway? (y/n)", v.duplicate.Name)))
+	case v.pending != nil:
+		lines = append(lines, "Checking playlist contents...")
+	default:
+		lines = append(lines, "Enter add  •  ESC cancel")
+	}
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" ➕ Add to playlist"),
+		box,
+	)
+}
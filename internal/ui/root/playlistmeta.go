@@ -0,0 +1,223 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"image"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// playlistMeta is the playlist information shown above its track list.
+type playlistMeta struct {
+	ID          spotify.ID
+	Name        string
+	Description string
+	Owner       string
+	Followers   int
+	Public      bool
+	Owned       bool
+	CoverURL    string
+}
+
+// playlistCoverRows is the height of the cover beside the playlist details.
+const playlistCoverRows = 6
+
+// playlistCover is a playlist's cover art, fetched like the playing item's.
+type playlistCover struct {
+	img   image.Image
+	cache *artRender
+}
+
+func newPlaylistMeta(pl *spotify.FullPlaylist, userID string) *playlistMeta {
+	owner := pl.Owner.DisplayName
+	if owner == "" {
+		owner = pl.Owner.ID
+	}
+	return &playlistMeta{
+		ID:   pl.ID,
+		Name: pl.Name,
+		// The API returns descriptions HTML-escaped
+		Description: html.UnescapeString(pl.Description),
+		Owner:       owner,
+		Followers:   int(pl.Followers.Count),
+		Public:      pl.IsPublic,
+		Owned:       pl.Owner.ID == userID,
+		CoverURL:    artURL(pl.Images),
+	}
+}
+
+func (m RootModel) renderPlaylistMeta(meta playlistMeta) []string {
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	visibility := "private"
+	if meta.Public {
+		visibility = "public"
+	}
+	fit := m.fitLine
+	cover := m.art.render(m.trackList.cover.img, m.trackList.cover.cache, min(playlistCoverRows, m.height/4), m.width/3)
+	if cover != "" {
+		// The details go beside the cover
+		gap := lipgloss.Width(cover) + 2
+		fit = func(line string) string { return truncate(line, m.width-6-gap) }
+	}
+	lines := []string{dimStyle.Render(fit(fmt.Sprintf("by %s  •  %d followers  •  %s", meta.Owner, meta.Followers, visibility)))}
+	if meta.Description != "" {
+		lines = append(lines, dimStyle.Render(fit(meta.Description)))
+	}
+	if cover != "" {
+		block := lipgloss.JoinHorizontal(lipgloss.Top, cover, "  ", strings.Join(lines, "\n"))
+		lines = strings.Split(block, "\n")
+	}
+	return append(lines, "")
+}
+
+// playlistEditView edits the name, description and visibility of a playlist
+// the user owns.
+type playlistEditView struct {
+	visible     bool
+	meta        playlistMeta
+	name        textinput.Model
+	description textinput.Model
+	public      bool
+	focus       int // 0 name, 1 description, 2 visibility
+}
+
+type playlistUpdatedMsg struct {
+	Meta playlistMeta
+}
+
+func updatePlaylistDetailsCmd(c *spotify.Client, meta playlistMeta) tea.Cmd {
+	return func() tea.Msg {
+		err := c.ChangePlaylistNameAccessAndDescription(context.Background(), meta.ID, meta.Name, meta.Description, meta.Public)
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return playlistUpdatedMsg{Meta: meta}
+	}
+}
+
+func (m RootModel) openPlaylistEdit(meta playlistMeta) (RootModel, tea.Cmd) {
+	if !meta.Owned {
+		m.status = "You can only edit playlists you own."
+		return m, clearStatusCmd()
+	}
+	v := playlistEditView{visible: true, meta: meta, public: meta.Public}
+	v.name = textinput.New()
+	v.name.Prompt = ""
+	v.name.SetValue(meta.Name)
+	v.name.Focus()
+	v.description = textinput.New()
+	v.description.Prompt = ""
+	v.description.Placeholder = "No description"
+	v.description.SetValue(meta.Description)
+	m.playlistEdit = v
+	return m, v.name.Cursor.BlinkCmd()
+}
+
+func (m RootModel) updatePlaylistEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.playlistEdit
+	switch msg.String() {
+	case "esc":
+		v.visible = false
+		return m, nil
+	case "tab", "shift+tab":
+		if msg.String() == "tab" {
+			v.focus = (v.focus + 1) % 3
+		} else {
+			v.focus = (v.focus + 2) % 3
+		}
+		v.name.Blur()
+		v.description.Blur()
+		switch v.focus {
+		case 0:
+			v.name.Focus()
+		case 1:
+			v.description.Focus()
+		}
+		return m, nil
+	case "enter":
+		name := strings.TrimSpace(v.name.Value())
+		if name == "" {
+			m.status = "Playlist name can't be empty."
+			return m, clearStatusCmd()
+		}
+		meta := v.meta
+		meta.Name = name
+		meta.Description = strings.TrimSpace(v.description.Value())
+		meta.Public = v.public
+		v.visible = false
+		return m, updatePlaylistDetailsCmd(m.client, meta)
+	case " ":
+		if v.focus == 2 {
+			v.public = !v.public
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch v.focus {
+	case 0:
+		v.name, cmd = v.name.Update(msg)
+	case 1:
+		v.description, cmd = v.description.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m RootModel) renderPlaylistEditScreen() string {
+	v := m.playlistEdit
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	label := func(i int, text string) string {
+		text = fmt.Sprintf("%-12s", text)
+		if v.focus == i {
+			return selectedStyle.Render(text)
+		}
+		return text
+	}
+
+	visibility := "[ ] Public"
+	if v.public {
+		visibility = "[x] Public"
+	}
+
+	content := strings.Join([]string{
+		label(0, "Name:") + v.name.View(),
+		label(1, "Description:") + v.description.View(),
+		label(2, "Visibility:") + visibility,
+		"",
+		"Tab next field  •  Space toggle visibility  •  Enter save  •  ESC cancel",
+	}, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" ✎ Edit playlist"),
+		box,
+	)
+}
@@ -0,0 +1,187 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// playlistPageSize is how many playlists are fetched at a time; the next
+// page is loaded when the cursor gets close to the end of the list.
+const playlistPageSize = 50
+
+// playlistsView is the library's playlist browser (P). Enter opens a
+// playlist's tracks on top of it; Esc there comes back here.
+type playlistsView struct {
+	playlists []spotify.SimplePlaylist
+	total     int // as reported by Spotify, for pagination
+	cursor    int
+	loading   bool
+}
+
+type playlistsPageMsg struct {
+	Offset    int
+	Playlists []spotify.SimplePlaylist
+	Total     int
+}
+
+func fetchPlaylistsPageCmd(c *spotify.Client, offset int) tea.Cmd {
+	return func() tea.Msg {
+		page, err := c.CurrentUsersPlaylists(context.Background(), spotify.Limit(playlistPageSize), spotify.Offset(offset))
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return playlistsPageMsg{Offset: offset, Playlists: page.Playlists, Total: int(page.Total)}
+	}
+}
+
+func playContextCmd(c *spotify.Client, uri spotify.URI, name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.PlayOpt(context.Background(), &spotify.PlayOptions{PlaybackContext: &uri}); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Playing " + name)
+	}
+}
+
+func (l libraryTab) handlePlaylistsPage(msg playlistsPageMsg) (libraryTab, tea.Cmd) {
+	v := &l.playlists
+	// Pages arrive in order; anything else is from an earlier opening
+	if l.liked || msg.Offset != len(v.playlists) {
+		return l, nil
+	}
+	v.loading = false
+	v.playlists = append(v.playlists, msg.Playlists...)
+	v.total = msg.Total
+	return l.morePlaylists()
+}
+
+// morePlaylists fetches the next page once the cursor is within a screen of
+// the end of what has been loaded.
+func (l libraryTab) morePlaylists() (libraryTab, tea.Cmd) {
+	v := &l.playlists
+	if v.loading || len(v.playlists) >= v.total || v.cursor < len(v.playlists)-l.env.height {
+		return l, nil
+	}
+	v.loading = true
+	return l, fetchPlaylistsPageCmd(l.env.client, len(v.playlists))
+}
+
+func (l libraryTab) updatePlaylists(msg tea.KeyMsg) (libraryTab, tea.Cmd) {
+	v := &l.playlists
+	switch msg.String() {
+	case "esc", "P":
+		return l, request(closeTabMsg{})
+	case "L":
+		// Liked Songs is the other half of the library tab
+		l.liked = true
+		l.likedSongs = likedSongsView{loading: true}
+		return l, fetchLikedSongsPageCmd(l.env.client, 0)
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(v.playlists)-1 {
+			v.cursor++
+		}
+		return l.morePlaylists()
+	case "enter":
+		if v.cursor < len(v.playlists) {
+			return l, fetchContextTracksCmd(l.env.client, v.playlists[v.cursor].URI, l.env.playing)
+		}
+	case "p":
+		if v.cursor < len(v.playlists) {
+			pl := v.playlists[v.cursor]
+			return l, request(playContextMsg{URI: pl.URI, Name: pl.Name})
+		}
+	}
+	return l, nil
+}
+
+func (l libraryTab) renderPlaylists() string {
+	v := l.playlists
+	colors := l.env.colors
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(l.env.border).
+		BorderForeground(lipgloss.Color(colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.TrackPlaying)).
+		Bold(true)
+
+	playingStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.TrackPaused))
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Status))
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + footer(2)
+	maxVisible := max(l.env.height-7, 3)
+
+	var lines []string
+	switch {
+	case len(v.playlists) == 0 && v.loading:
+		lines = append(lines, dimStyle.Render("Loading playlists..."))
+	case len(v.playlists) == 0:
+		lines = append(lines, "You have no playlists.")
+	}
+	start, end := visibleRange(v.cursor, len(v.playlists), maxVisible)
+	for i := start; i < end; i++ {
+		pl := v.playlists[i]
+		marker := "  "
+		if pl.URI == l.env.context {
+			marker = "♪ "
+		}
+		line := l.env.fitLine(fmt.Sprintf("  %s%s  (%d tracks, %s)", marker, pl.Name, pl.Tracks.Total, playlistOwner(pl)))
+		switch {
+		case i == v.cursor:
+			line = selectedStyle.Render("▶ " + line[2:])
+		case pl.URI == l.env.context:
+			line = playingStyle.Render(line)
+		default:
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "Enter open  •  p play playlist  •  L Liked Songs  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := l.env.width - containerStyle.GetHorizontalBorderSize()
+	h := l.env.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	title := " 📚 Playlists"
+	if v.total > 0 {
+		title += fmt.Sprintf(" (%d)", v.total)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		withTabBar(colors, tabLibrary, headerStyle.Render(title)),
+		box,
+	)
+}
+
+func playlistOwner(pl spotify.SimplePlaylist) string {
+	if pl.Owner.DisplayName != "" {
+		return "by " + pl.Owner.DisplayName
+	}
+	return "by " + pl.Owner.ID
+}
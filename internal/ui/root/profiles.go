@@ -0,0 +1,137 @@
+package root
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// profilesView is the profile switcher (O). Switching quits the player and
+// starts it again with the other profile's config and login; see
+// SwitchProfile.
+type profilesView struct {
+	visible  bool
+	profiles []string // "" is the default profile
+	cursor   int
+	// switchTo is the profile picked, once one is
+	switchTo *string
+}
+
+func (m RootModel) openProfiles() (RootModel, tea.Cmd) {
+	profiles, err := config.Profiles()
+	if err != nil {
+		m.status = "Could not list profiles: " + err.Error()
+		return m, clearStatusCmd()
+	}
+	m.profiles = profilesView{
+		visible:  true,
+		profiles: profiles,
+		cursor:   max(slices.Index(profiles, config.Profile()), 0),
+	}
+	return m, nil
+}
+
+func (m RootModel) updateProfiles(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.profiles
+	switch msg.String() {
+	case "esc", "O":
+		v.visible = false
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(v.profiles)-1 {
+			v.cursor++
+		}
+	case "enter":
+		if v.cursor < len(v.profiles) {
+			name := v.profiles[v.cursor]
+			if name == config.Profile() {
+				v.visible = false
+				return m, nil
+			}
+			v.switchTo = &name
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// SwitchProfile returns the profile picked in the switcher, whose player
+// should start once this one has quit.
+func (m RootModel) SwitchProfile() (name string, ok bool) {
+	if m.profiles.switchTo == nil {
+		return "", false
+	}
+	return *m.profiles.switchTo, true
+}
+
+// profileTitle is how a profile is listed; the default one has no name.
+func profileTitle(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+func (m RootModel) renderProfilesScreen() string {
+	v := m.profiles
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	var lines []string
+	for i, name := range v.profiles {
+		line := "  " + profileTitle(name)
+		if name == config.Profile() {
+			line += " (current)"
+		}
+		line = m.fitLine(line)
+		if i == v.cursor {
+			line = selectedStyle.Render("▶ " + line[2:])
+		} else {
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(v.profiles) == 1 {
+		lines = append(lines, "", dimStyle.Render("Start with --profile NAME to add a profile."))
+	}
+
+	lines = append(lines, "", "Enter switch  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 👤 Profiles"),
+		box,
+	)
+}
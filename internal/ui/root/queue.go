@@ -0,0 +1,174 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// queueTab is the queue tab: the tracks coming up next.
+type queueTab struct {
+	env      tabEnv
+	loaded   bool
+	tracks   []spotify.FullTrack
+	cursor   int
+	selected map[spotify.ID]bool
+}
+
+type queueMsg struct {
+	Tracks []spotify.FullTrack
+}
+
+// fetchQueueCmd fetches the tracks coming up next.
+func fetchQueueCmd(c *spotify.Client) tea.Cmd {
+	return func() tea.Msg {
+		q, err := c.GetQueue(context.Background())
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return queueMsg{Tracks: q.Items}
+	}
+}
+
+func (q queueTab) withEnv(env tabEnv) tabModel {
+	q.env = env
+	return q
+}
+
+func (q queueTab) Init() tea.Cmd {
+	return fetchQueueCmd(q.env.client)
+}
+
+func (q queueTab) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case queueMsg:
+		q.loaded = true
+		q.tracks = msg.Tracks
+		q.cursor = 0
+		q.selected = make(map[spotify.ID]bool)
+
+	case likeBatchMsg:
+		clear(q.selected)
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			if q.cursor > 0 {
+				q.cursor--
+			}
+		case tea.MouseButtonWheelDown:
+			if q.cursor < len(q.tracks)-1 {
+				q.cursor++
+			}
+		}
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "c":
+			return q, request(closeTabMsg{})
+		case "up":
+			if q.cursor > 0 {
+				q.cursor--
+			}
+		case "down":
+			if q.cursor < len(q.tracks)-1 {
+				q.cursor++
+			}
+		case "A":
+			if q.cursor < len(q.tracks) {
+				return q, request(addTrackToPlaylistMsg{Track: q.tracks[q.cursor]})
+			}
+		case "x":
+			if q.cursor < len(q.tracks) {
+				id := q.tracks[q.cursor].ID
+				if q.selected[id] {
+					delete(q.selected, id)
+				} else if id != "" {
+					q.selected[id] = true
+				}
+			}
+		case "L", "U":
+			return q, request(likeTracksMsg{Tracks: q.tracks, Selected: q.selected, Add: msg.String() == "L"})
+		case "enter":
+			if q.cursor < len(q.tracks) {
+				// The queue has no context: the tracks from here on are played in order
+				return q, request(playTracksMsg{Tracks: q.tracks, Index: q.cursor})
+			}
+		}
+	}
+	return q, nil
+}
+
+func (q queueTab) View() string {
+	colors := q.env.colors
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(q.env.border).
+		BorderForeground(lipgloss.Color(colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Status)).
+		Faint(true)
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + footer(2)
+	maxVisible := max(q.env.height-7, 3)
+
+	var lines []string
+	switch {
+	case !q.loaded:
+		lines = append(lines, dimStyle.Render("Loading the queue..."))
+	case len(q.tracks) == 0:
+		lines = append(lines, "Nothing is queued.")
+	}
+	start, end := visibleRange(q.cursor, len(q.tracks), maxVisible)
+	for i := start; i < end; i++ {
+		t := q.tracks[i]
+		line := fmt.Sprintf("  %s  %s%s - %s%s", selectionMark(q.selected, t.ID), t.Name, explicitMark(t), trackArtist(t), q.env.likedMark(t.ID))
+		unplayable := unplayableReason(t) != ""
+		if unplayable {
+			line += " (unavailable)"
+		}
+		line = q.env.fitLine(line)
+		switch {
+		case i == q.cursor:
+			line = selectedStyle.Render("▶ " + line[2:])
+		case unplayable:
+			line = dimStyle.Render(line)
+		default:
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "Enter play  •  A add to playlist  •  x select  •  L/U like/unlike selected  •  ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := q.env.width - containerStyle.GetHorizontalBorderSize()
+	h := q.env.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		withTabBar(colors, tabQueue, headerStyle.Render(" Queue")),
+		box,
+	)
+}
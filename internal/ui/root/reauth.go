@@ -0,0 +1,136 @@
+package root
+
+import (
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/auth"
+	"github.com/metolius25/spotirice/internal/platform"
+)
+
+// reauthView replaces the UI once Spotify stops accepting the saved refresh
+// token, offering a new login instead of a stream of failing requests.
+type reauthView struct {
+	visible bool
+	login   *auth.Login // set while waiting for the browser callback
+	err     string
+}
+
+type loginStartedMsg struct{ Login *auth.Login }
+type loginDoneMsg struct {
+	Client *spotify.Client
+	Err    error
+}
+
+func startLoginCmd() tea.Msg {
+	login, err := auth.StartLogin()
+	if err != nil {
+		return loginDoneMsg{Err: err}
+	}
+	if !platform.Remote() {
+		_ = platform.OpenURL(login.URL)
+	}
+	return loginStartedMsg{Login: login}
+}
+
+// headlessLogin is auth.AuthenticateHeadless run on the terminal, which the
+// program hands over for it with tea.Exec: over SSH the browser's callback
+// can't reach this machine, so the user pastes the address instead.
+type headlessLogin struct {
+	in     io.Reader
+	out    io.Writer
+	client *spotify.Client
+}
+
+func (h *headlessLogin) SetStdin(r io.Reader)  { h.in = r }
+func (h *headlessLogin) SetStdout(w io.Writer) { h.out = w }
+func (h *headlessLogin) SetStderr(io.Writer)   {}
+
+func (h *headlessLogin) Run() (err error) {
+	h.client, err = auth.AuthenticateHeadless(h.in, h.out)
+	return err
+}
+
+func headlessLoginCmd() tea.Cmd {
+	h := &headlessLogin{}
+	return tea.Exec(h, func(err error) tea.Msg {
+		return loginDoneMsg{Client: h.client, Err: err}
+	})
+}
+
+func waitLoginCmd(l *auth.Login) tea.Cmd {
+	return func() tea.Msg {
+		client, err := l.Wait()
+		return loginDoneMsg{Client: client, Err: err}
+	}
+}
+
+func (m RootModel) updateReauth(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "enter", "l":
+		if m.reauth.login == nil {
+			m.reauth.err = ""
+			if platform.Remote() {
+				return m, headlessLoginCmd()
+			}
+			return m, startLoginCmd
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderReauthScreen() string {
+	v := m.reauth
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Error)).
+		Bold(true)
+
+	lines := []string{
+		errorStyle.Render("Spotify no longer accepts Spotirice's saved login."),
+		"This happens after a password change or when app access is removed.",
+		"",
+	}
+	if v.login != nil {
+		lines = append(lines,
+			"Waiting for you to log in. If no browser opened, visit:",
+			"",
+			// Left whole so it can be copied even when it wraps
+			v.login.URL,
+		)
+	} else {
+		if v.err != "" {
+			lines = append(lines, errorStyle.Render("Login failed: "+v.err), "")
+		}
+		lines = append(lines, "Enter/l log in again  •  q quit")
+	}
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 🔑 Log in"),
+		box,
+	)
+}
@@ -0,0 +1,104 @@
+package root
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/daemon"
+)
+
+// playbackSaveInterval is how often the playback position is recorded
+// while a track plays; track changes are recorded right away.
+const playbackSaveInterval = 15 * time.Second
+
+// recordPlayback saves what is playing for resume_last_session, at most
+// every playbackSaveInterval for the same track, and adds new tracks to the
+// history when record_history is on.
+func (m *RootModel) recordPlayback(trackChanged bool) {
+	if !m.isPlaying || m.currentTrackURI == "" || m.playingType == "ad" {
+		return
+	}
+	// A running daemon keeps the history itself
+	if trackChanged && m.settings.RecordHistory && m.currentTrackID != "" && !daemon.Running() {
+		err := config.AppendHistory(config.HistoryEntry{
+			Time:       time.Now(),
+			TrackID:    string(m.currentTrackID),
+			Track:      m.trackName,
+			Artist:     m.artistName,
+			DurationMs: m.durationMs,
+		})
+		if err != nil {
+			m.status = "Couldn't record history: " + err.Error()
+		}
+	}
+	if !trackChanged && time.Since(m.playbackSavedAt) < playbackSaveInterval {
+		return
+	}
+	m.playbackSavedAt = time.Now()
+	err := config.SaveLastPlayback(config.LastPlayback{
+		ContextURI: string(m.contextURI),
+		TrackURI:   string(m.currentTrackURI),
+		ProgressMs: m.progressMs,
+		SavedAt:    m.playbackSavedAt,
+	})
+	if err != nil {
+		m.status = "Couldn't record playback: " + err.Error()
+	}
+}
+
+// resumeLastPlaybackCmd starts the recorded playback again, at the saved
+// position, on the device resumePlaybackCmd would pick.
+func resumeLastPlaybackCmd(c *spotify.Client, p config.LastPlayback, preferred, last string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := ensureActiveDevice(c, preferred, last); err != nil {
+			return errMsg{Err: err}
+		}
+
+		track := spotify.URI(p.TrackURI)
+		opts := &spotify.PlayOptions{PositionMs: spotify.Numeric(p.ProgressMs)}
+		if p.ContextURI != "" {
+			playContext := spotify.URI(p.ContextURI)
+			opts.PlaybackContext = &playContext
+			opts.PlaybackOffset = &spotify.PlaybackOffset{URI: track}
+		} else {
+			opts.URIs = []spotify.URI{track}
+		}
+		err := c.PlayOpt(ctx, opts)
+		if err != nil && p.ContextURI != "" {
+			// Some contexts (artists, Liked Songs) can't start at a given
+			// track; the track on its own still picks up where it was
+			err = c.PlayOpt(ctx, &spotify.PlayOptions{URIs: []spotify.URI{track}, PositionMs: opts.PositionMs})
+		}
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Resumed where you left off.")
+	}
+}
+
+// resumeLastSession restores the recorded playback when the first poll
+// finds nothing playing and resume_last_session is on.
+func (m RootModel) resumeLastSession() (RootModel, tea.Cmd) {
+	if m.resumeChecked {
+		return m, nil
+	}
+	m.resumeChecked = true
+	if !m.settings.ResumeLastSession || m.readOnly || m.client == nil {
+		return m, nil
+	}
+	p, err := config.LoadLastPlayback()
+	if err != nil {
+		m.status = "Couldn't load last playback: " + err.Error()
+		return m, clearStatusCmd()
+	}
+	if p == nil || p.TrackURI == "" {
+		return m, nil
+	}
+	m.burstTicksRemaining = 10
+	return m, resumeLastPlaybackCmd(m.client, *p, m.settings.PreferredDevice, m.lastDevice)
+}
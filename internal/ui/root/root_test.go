@@ -0,0 +1,224 @@
+package root
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/exp/teatest"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/mock"
+)
+
+const (
+	testWidth  = 100
+	testHeight = 30
+)
+
+// startPlayer runs the player against the mock server, as --mock does, and
+// waits for the first poll to show the canned track playing.
+func startPlayer(t *testing.T) *teatest.TestModel {
+	t.Helper()
+	t.Setenv("SPOTIRICE_CONFIG_DIR", t.TempDir())
+	t.Setenv("SPOTIRICE_STATE_DIR", t.TempDir())
+	t.Setenv("SPOTIRICE_CACHE_DIR", t.TempDir())
+
+	srv := mock.New()
+	SetTransport(srv.Transport())
+	t.Cleanup(func() { SetTransport(nil) })
+
+	settings := config.DefaultSettings()
+	settings.MPRIS = false
+	m, _ := NewRootModel(srv.Client(), config.DefaultColors(), settings, "test")
+	m.tour.visible = false
+
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(testWidth, testHeight))
+	t.Cleanup(func() { tm.Quit() })
+	waitFor(t, tm, "Coastline at 3 AM")
+	return tm
+}
+
+// waitFor waits for the screen to show all of texts. The output is read
+// as it comes, so what one wait has seen the next doesn't see again: things
+// drawn together need waiting for together.
+func waitFor(t *testing.T, tm *teatest.TestModel, texts ...string) {
+	t.Helper()
+	res := make([]*regexp.Regexp, len(texts))
+	for i, text := range texts {
+		res[i] = regexp.MustCompile(regexp.QuoteMeta(text))
+	}
+	waitForMatch(t, tm, res...)
+}
+
+func waitForMatch(t *testing.T, tm *teatest.TestModel, res ...*regexp.Regexp) {
+	t.Helper()
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		for _, re := range res {
+			if !re.Match(out) {
+				return false
+			}
+		}
+		return true
+	}, teatest.WithDuration(3*time.Second), teatest.WithCheckInterval(10*time.Millisecond))
+}
+
+func press(tm *teatest.TestModel, keys ...string) {
+	for _, k := range keys {
+		switch k {
+		case "esc":
+			tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+		case "enter":
+			tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+		case "tab":
+			tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+		default:
+			tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	}
+}
+
+func click(tm *teatest.TestModel, x, y int) {
+	tm.Send(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft})
+}
+
+func TestPlaybackKeys(t *testing.T) {
+	tm := startPlayer(t)
+
+	press(tm, "n")
+	waitFor(t, tm, "Chrome Horizon")
+
+	press(tm, "+")
+	waitFor(t, tm, "70%")
+
+	// The mock has this one in Liked Songs already
+	press(tm, "l")
+	waitFor(t, tm, "Removed from Liked Songs.", "♡")
+}
+
+func TestTabs(t *testing.T) {
+	tm := startPlayer(t)
+
+	press(tm, "2")
+	waitFor(t, tm, "[2 Queue]", "Chrome Horizon")
+
+	press(tm, "tab")
+	waitFor(t, tm, "Mock Mix")
+
+	press(tm, "enter")
+	waitFor(t, tm, "Playlist: Mock Mix")
+	press(tm, "esc")
+	waitFor(t, tm, "[3 Library]")
+
+	press(tm, "tab")
+	waitFor(t, tm, "[4 Search]")
+	tm.Type("paper")
+	press(tm, "enter")
+	waitFor(t, tm, "Paper Boats")
+	press(tm, "enter")
+	waitFor(t, tm, "[1 Player]", "Paper Boats")
+}
+
+// csiSequence is how bubbletea passes on a sequence it doesn't know, like
+// the kitty protocol's keys.
+type csiSequence string
+
+func (s csiSequence) String() string { return string(s) }
+
+func TestSearchQueue(t *testing.T) {
+	tm := startPlayer(t)
+
+	press(tm, "4")
+	tm.Type("paper")
+	press(tm, "enter")
+	waitFor(t, tm, "The Attic Window", "Paper Boats")
+
+	// Shift+Enter (CSI 13;2u) queues rather than plays
+	tm.Send(csiSequence("?CSI[49 51 59 50 117]?"))
+	// So does a, once the results have the keys
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	press(tm, "a")
+	press(tm, "esc")
+	waitFor(t, tm, "Queued ")
+
+	// Both ahead of what the context has next, in the order the requests
+	// happened to reach the server
+	press(tm, "2")
+	waitForMatch(t, tm, regexp.MustCompile(`(?s)(The Attic Window.*Paper Boats|Paper Boats.*The Attic Window).*Chrome Horizon`))
+}
+
+func TestHelpOverlay(t *testing.T) {
+	tm := startPlayer(t)
+
+	press(tm, "?")
+	waitFor(t, tm, "Toggle help", "Press ESC or ? to close this screen")
+
+	press(tm, "esc")
+	waitFor(t, tm, "Spotirice vtest")
+}
+
+// TestHelpKeymap checks the help screen lists the keys of the keymap in
+// use rather than the built-in ones.
+func TestHelpKeymap(t *testing.T) {
+	keys, err := newKeyMap("vim", map[string]config.KeyList{
+		"play_pause": {"space", "x"},
+		"help":       {"f1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := RootModel{colors: config.DefaultColors(), settings: config.DefaultSettings(), keys: keys}
+	help := m.renderHelpScreen()
+
+	for _, re := range []string{
+		`Space, x +Play/Pause`,
+		`h +Seek back 10 seconds`,
+		`Ctrl\+← +Seek back 30 seconds`,
+		`\* +Like/Unlike song`,
+		`:q +Quit`,
+		`Press ESC or F1 to close`,
+	} {
+		if !regexp.MustCompile(re).MatchString(help) {
+			t.Errorf("help lacks %q:\n%s", re, help)
+		}
+	}
+	for _, re := range []string{`p, Space`, `← +Seek back 10`, `l +Like`, `Audiobooks`} {
+		if regexp.MustCompile(re).MatchString(help) {
+			t.Errorf("help has %q:\n%s", re, help)
+		}
+	}
+}
+
+func TestMouseRows(t *testing.T) {
+	tm := startPlayer(t)
+
+	// The rows counted in Update's click handling
+	const (
+		progressRow = 5
+		controlRow  = 6
+		footerRow   = 10
+	)
+
+	// The search button, found as Update finds it
+	m := RootModel{width: testWidth, isPlaying: true, settings: config.DefaultSettings()}
+	controls, buttons := m.controlsLayout()
+	containerWidth := testWidth - lipgloss.NewStyle().Border(m.border()).GetHorizontalBorderSize()
+	padding := (containerWidth - lipgloss.Width(controls)) / 2
+	click(tm, 1+padding+buttons[0].start, controlRow)
+	waitFor(t, tm, "[4 Search]")
+	press(tm, "esc")
+	waitFor(t, tm, "[1 Player]")
+
+	// The timer, left of the bar, switches to the time remaining
+	click(tm, 5, progressRow)
+	waitForMatch(t, tm, regexp.MustCompile(`-[0-9]:[0-9]{2}/3:44`))
+
+	// Halfway along the bar seeks to about halfway through the track
+	click(tm, testWidth/2, progressRow)
+	waitForMatch(t, tm, regexp.MustCompile(`-1:[45][0-9]/3:44`))
+
+	click(tm, testWidth/2, footerRow)
+	waitFor(t, tm, "Devices", "Mock Speaker")
+}
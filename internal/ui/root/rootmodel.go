@@ -3,18 +3,29 @@ package root
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zmb3/spotify/v2"
 
+	"github.com/metolius25/spotirice/internal/auth"
 	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/daemon"
+	"github.com/metolius25/spotirice/internal/devices"
+	"github.com/metolius25/spotirice/internal/keyboard"
+	"github.com/metolius25/spotirice/internal/platform"
 )
 
 type statusMsg string
+
+// noPlaybackMsg reports that nothing is playing anywhere, or with Err set
+// that the player state couldn't be fetched.
+type noPlaybackMsg struct{ Err error }
 type errMsg struct{ Err error }
 type tickMsg struct{}
 type clearStatusMsg struct{}
@@ -29,14 +40,31 @@ type playerStateMsg struct {
 	DurationMs int
 	Playing    bool
 	ID         spotify.ID
-	Liked      bool
+	Explicit   bool
 	Volume     int
+	URI        spotify.URI
+	ContextURI spotify.URI
+	AlbumURI   spotify.URI
+	AlbumName  string
+	Released   string // album release date, "2001" or "2001-03-12"
+	ArtistURI  spotify.URI
+	Device     deviceEntry
+	// Type is Spotify's currently_playing_type: "track", "episode", "ad" or
+	// "unknown" (between items).
+	Type    string
+	Shuffle bool
+	Repeat  string // "off", "track" or "context"
+	// ArtURL is the cover of the album or show, if any.
+	ArtURL string
+	// Latency is how long the state request took.
+	Latency time.Duration
 }
 
 type RootModel struct {
-	client *spotify.Client
-	status string
-	colors *config.Colors
+	client   *spotify.Client
+	status   string
+	colors   *config.Colors
+	settings *config.Settings
 
 	// player state
 	trackName       string
@@ -46,21 +74,131 @@ type RootModel struct {
 	isPlaying       bool
 	hasInitialState bool
 	currentTrackID  spotify.ID
+	currentTrackURI spotify.URI
+	contextURI      spotify.URI
 	trackIsLiked    bool
 
 	// playback state
-	volume int // 0-100
+	volume      int // 0-100
+	device      deviceEntry
+	lostDevice  string // name of the active device that disappeared mid-playback
+	lastDevice  string // ID of the device last seen playing, persisted across runs
+	readOnly    bool   // account can't control playback (not Premium)
+	title       string // last terminal title set, when terminal_title is on
+	contextName string // display name of contextURI, once resolved
+
+	albumURI        spotify.URI
+	albumName       string
+	releaseDate     string
+	artistURI       spotify.URI
+	trackExplicit   bool
+	explicitConfirm spotify.URI // explicit track awaiting a second Enter
+
+	// playingType is the currently_playing_type of the last poll ("ad", ...)
+	playingType string
+
+	// Playback modes and connection health for the indicator row
+	shuffle      bool
+	repeat       string
+	latency      time.Duration
+	pollFailures int // consecutive failed polls
+	// feed is the daemon's state notifications, which take the place of
+	// polling while attached (see AttachDaemon)
+	feed *daemon.Subscription
+
+	showRemaining bool // timer shows -remaining/total instead of elapsed/total
+
+	bigMode    bool // big-text now-playing view
+	visualizer visualizerView
+	transition transition
+
+	// Screensaver: shown after a stretch without input, until the next key
+	lastInput     time.Time
+	screensaver   bool
+	marqueeOffset int
+
+	// Pending prefix key of a two-key chord ("g a"), and when it was pressed
+	chord   string
+	chordAt time.Time
+
+	// session is the saved UI state still being restored, or nil
+	session *config.Session
+
+	// resume_last_session: whether the first poll has been looked at, and
+	// when the playing position was last recorded
+	resumeChecked   bool
+	playbackSavedAt time.Time
+
+	pausedForPower bool // pause_on paused playback, so resume_on may restart it
+
+	// Sleep timer: when it pauses, and the step of sleepSteps it was set to
+	sleepAt  time.Time
+	sleepFor time.Duration
+
+	focus focusSession
+
+	// beat sync state
+	analysisTrackID spotify.ID
+	barOffsets      []int
+	beatOffsets     []int
 
 	// UI state
 	showHelp            bool
 	burstTicksRemaining int // countdown for burst tick mode (10 ticks = 1 second at 100ms)
-	version             string
 
-	// Search state
-	isSearching   bool
-	searchInput   textinput.Model
-	searchResults []spotify.FullTrack
-	searchCursor  int
+	// Mute remembers the volume to go back to
+	muted         bool
+	preMuteVolume int
+
+	// Optimistic seeking: where we last seeked to and when
+	seekTarget int
+	seekAt     time.Time
+	version    string
+
+	// The tab showing, and the screens of the tabs besides the player
+	tabs tabsModel
+
+	// Saved episodes state
+	showEpisodes   bool
+	episodes       []savedEpisode
+	episodesCursor int
+	playedEpisodes map[string]bool
+
+	audiobooks audiobookView
+
+	// Tracks of the current (or an opened) context
+	trackList trackListView
+
+	// Liked Songs status per track ID
+	likeCache map[spotify.ID]bool
+
+	// Genres per artist ID
+	genreCache map[spotify.ID][]string
+
+	// Audio features per track ID; nil when Spotify has none
+	featureCache map[spotify.ID]*spotify.AudioFeatures
+
+	addToPlaylist addToPlaylistView
+	playlistEdit  playlistEditView
+	smartPlaylist smartPlaylistView
+	devices       deviceView
+	panel         panelView
+	party         partyView
+	share         shareView
+	genres        genreView
+	trackInfo     trackInfoView
+	tour          tourView
+	reauth        reauthView
+	art           artView
+	lyrics        lyricsView
+	keys          keyMap // [keybindings] applied to the built-in keys
+	listG         bool   // a first g of the vim preset's gg in a list
+	palette       paletteView
+	profiles      profilesView
+	artURL        string // cover of the playing item, for MPRIS clients
+	update        updateView
+	themeEditor   themeEditorView
+	playlistCache map[spotify.ID]playlistContents
 
 	width  int
 	height int
@@ -76,11 +214,27 @@ func (m RootModel) Init() tea.Cmd {
 	if m.client == nil {
 		return nil
 	}
-	return tea.Batch(
+	poll := pollStateCmd(m.client)
+	if attached {
+		poll = subscribeCmd()
+	}
+	cmds := []tea.Cmd{
 		tea.WindowSize(),
-		pollStateCmd(m.client),
+		poll,
 		tickCmd(),
-	)
+		probeAudiobooksCmd(m.client),
+		fetchAccountCmd(m.client),
+	}
+	if m.settings.PreferredDevice != "" {
+		cmds = append(cmds, checkPreferredDeviceCmd(m.client, m.settings.PreferredDevice))
+	}
+	if m.settings.Party.Addr != "" {
+		cmds = append(cmds, fetchPartyCmd(), partyPollCmd())
+	}
+	if m.settings.UpdateCheck {
+		cmds = append(cmds, checkUpdateCmd(m.version, time.Duration(m.settings.UpdateCheckHours)*time.Hour))
+	}
+	return tea.Batch(cmds...)
 }
 
 func tickCmd() tea.Cmd {
@@ -91,200 +245,327 @@ func fastTickCmd() tea.Cmd {
 	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return tickMsg{} })
 }
 
+// playingItem is the playing track or podcast episode; episodes have their
+// show instead of artists.
+type playingItem struct {
+	spotify.FullTrack
+	Show *spotify.SimpleShow `json:"show"`
+}
+
+// playerStatus is GET /me/player with the device capability flags the
+// spotify library's PlayerState leaves out.
+type playerStatus struct {
+	spotify.PlayerState
+	// Shadows PlayerState's track-only item
+	Item        *playingItem `json:"item"`
+	Device      deviceEntry  `json:"device"`
+	PlayingType string       `json:"currently_playing_type"`
+}
+
 func pollStateCmd(c *spotify.Client) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		state, err := c.PlayerState(ctx)
-		if err != nil || state == nil || state.Item == nil {
-			return statusMsg("Waiting for playback...")
+		var state *playerStatus
+		query := url.Values{"additional_types": {"episode"}}
+		started := time.Now()
+		if err := apiRequest(ctx, c, http.MethodGet, "me/player", query, &state); err != nil {
+			return noPlaybackMsg{Err: err}
 		}
+		return playerState(state, time.Since(started))
+	}
+}
 
-		track := state.Item
-		artist := ""
-		if len(track.Artists) > 0 {
-			artist = track.Artists[0].Name
-		}
-
-		// check if liked
-		liked, _ := c.UserHasTracks(ctx, track.ID)
-
+// playerState turns GET /me/player, nil when nothing is playing, into the
+// message the model takes it as.
+func playerState(state *playerStatus, latency time.Duration) tea.Msg {
+	if state == nil {
+		return noPlaybackMsg{}
+	}
+	if state.Item == nil {
+		// Ads and the gap between two items come without an item
 		return playerStateMsg{
-			TrackName:  track.Name,
-			ArtistName: artist,
-			ProgressMs: int(state.Progress),
-			DurationMs: int(track.Duration),
 			Playing:    state.Playing,
-			ID:         track.ID,
-			Liked:      len(liked) > 0 && liked[0],
 			Volume:     int(state.Device.Volume),
+			ContextURI: state.PlaybackContext.URI,
+			Device:     state.Device,
+			Type:       state.PlayingType,
+			Shuffle:    state.ShuffleState,
+			Repeat:     state.RepeatState,
+			Latency:    latency,
 		}
 	}
+
+	track := state.Item
+	artist := ""
+	var artistURI spotify.URI
+	if len(track.Artists) > 0 {
+		artist = track.Artists[0].Name
+		artistURI = track.Artists[0].URI
+	}
+	id := track.ID
+	art := artURL(track.Album.Images)
+	if state.PlayingType == "episode" {
+		// Episodes aren't in the liked songs library
+		id = ""
+		if track.Show != nil {
+			artist = track.Show.Name
+			art = artURL(track.Show.Images)
+		}
+	}
+
+	return playerStateMsg{
+		TrackName:  track.Name,
+		ArtistName: artist,
+		ProgressMs: int(state.Progress),
+		DurationMs: int(track.Duration),
+		Playing:    state.Playing,
+		ID:         id,
+		Explicit:   track.Explicit,
+		Volume:     int(state.Device.Volume),
+		URI:        track.URI,
+		ContextURI: state.PlaybackContext.URI,
+		AlbumURI:   track.Album.URI,
+		AlbumName:  track.Album.Name,
+		Released:   track.Album.ReleaseDate,
+		ArtistURI:  artistURI,
+		Device:     state.Device,
+		Type:       state.PlayingType,
+		Shuffle:    state.ShuffleState,
+		Repeat:     state.RepeatState,
+		ArtURL:     art,
+		Latency:    latency,
+	}
 }
 
 func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.handleMsg(msg)
+	switch msg.(type) {
+	case playerStateMsg, noPlaybackMsg:
+		if rm, ok := next.(RootModel); ok {
+			rm.publishPlayback()
+		}
+	}
+	return next, cmd
+}
+
+func (m RootModel) handleMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := keyboard.Translate(msg); ok {
+		return m.Update(key)
+	}
+	// Keys with modifiers tea.KeyMsg has no room for go where their legacy
+	// key would; only the search tab and [keybindings] tell them apart
+	var full keyboard.KeyMsg
+	if key, ok := msg.(keyboard.KeyMsg); ok {
+		full, msg = key, key.Legacy
+	}
 	switch msg := msg.(type) {
 
+	case keyboard.ShiftSpaceMsg:
+		// Shift+Space restarts the track; without the kitty protocol the
+		// terminal sends a plain space, which toggles play/pause instead
+		if !m.onMainView() {
+			// Text inputs and lists treat it as the space it types
+			return m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		}
+		if m.client == nil || m.durationMs == 0 {
+			return m, nil
+		}
+		if reason := m.controlBlockedReason("left"); reason != "" {
+			m.status = reason
+			return m, clearStatusCmd()
+		}
+		return m.seek(0)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 
 	case tea.KeyMsg:
-		// Handle search mode input
-		if m.isSearching {
-			switch msg.String() {
-			case "esc":
-				m.isSearching = false
-				m.searchResults = nil
-				m.searchCursor = 0
-				return m, nil
-			case "enter":
-				if len(m.searchResults) > 0 && m.searchCursor < len(m.searchResults) {
-					// Play the selected track
-					track := m.searchResults[m.searchCursor]
-					m.isSearching = false
-					m.searchResults = nil
-					m.searchCursor = 0
-					return m, playTrackCmd(m.client, track.URI)
-				} else if m.searchInput.Value() != "" {
-					// Perform search
-					return m, searchCmd(m.client, m.searchInput.Value())
-				}
-			case "up":
-				if m.searchCursor > 0 {
-					m.searchCursor--
-				}
-				return m, nil
-			case "down":
-				if m.searchCursor < len(m.searchResults)-1 {
-					m.searchCursor++
-				}
-				return m, nil
-			default:
-				// Pass input to textinput
-				var cmd tea.Cmd
-				m.searchInput, cmd = m.searchInput.Update(msg)
-				return m, cmd
-			}
+		m.lastInput = time.Now()
+		if m.screensaver {
+			// The key that wakes the player does nothing else
+			m.screensaver = false
 			return m, nil
 		}
+		if m.reauth.visible {
+			return m.updateReauth(msg)
+		}
 
-		// If help is showing, any key closes it
-		if m.showHelp {
-			if msg.String() == "esc" || msg.String() == "?" {
-				m.showHelp = false
-				return m, nil
+		if m.palette.visible {
+			return m.updatePalette(msg)
+		}
+
+		if m.keys.vim {
+			var cmd tea.Cmd
+			var done bool
+			if m, msg, cmd, done = m.vimListKey(msg); done {
+				return m, cmd
 			}
 		}
 
-		switch msg.String() {
-		case "/", "s":
-			// Enter search mode
-			m.isSearching = true
-			m.searchInput = textinput.New()
-			m.searchInput.Placeholder = "Search for songs..."
-			m.searchInput.SetValue("")
-			m.searchInput.Focus()
-			m.searchResults = nil
-			m.searchCursor = 0
-			return m, m.searchInput.Cursor.BlinkCmd()
+		if m.showEpisodes {
+			return m.updateEpisodes(msg)
+		}
 
-		case "?":
-			m.showHelp = !m.showHelp
-			return m, nil
+		if m.audiobooks.visible {
+			return m.updateAudiobooks(msg)
+		}
 
-		case "p", " ":
-			if m.client == nil {
-				return m, nil
-			}
-			m.burstTicksRemaining = 10 // Fast polling for 1 second
-			if m.isPlaying {
-				return m, pauseCmd(m.client)
-			}
-			return m, resumePlaybackCmd(m.client)
+		if m.addToPlaylist.visible {
+			return m.updateAddToPlaylist(msg)
+		}
 
-		case "n":
-			if m.client == nil {
-				return m, nil
-			}
-			m.burstTicksRemaining = 10
-			return m, nextCmd(m.client)
+		if m.playlistEdit.visible {
+			return m.updatePlaylistEdit(msg)
+		}
 
-		case "b":
-			if m.client == nil {
-				return m, nil
-			}
-			m.burstTicksRemaining = 10
-			return m, prevCmd(m.client)
+		if m.smartPlaylist.visible {
+			return m.updateSmartPlaylist(msg)
+		}
 
-		case "l":
-			if m.currentTrackID != "" {
-				m.burstTicksRemaining = 10
-				return m, toggleLikeCmd(m.client, m.currentTrackID, m.trackIsLiked)
-			}
+		if m.devices.visible {
+			return m.updateDevices(msg)
+		}
+
+		if m.profiles.visible {
+			return m.updateProfiles(msg)
+		}
+
+		if m.trackList.visible {
+			return m.updateTrackList(msg)
+		}
 
-		case "+", "=":
-			if m.client != nil {
-				newVol := m.volume + 10
-				if newVol > 100 {
-					newVol = 100
+		if m.tabs.active != tabPlayer {
+			// The search takes Tab and digits as typing
+			if m.tabs.active != tabSearch {
+				if t, ok := tabForKey(msg.String(), m.tabs.active); ok {
+					return m.switchTab(t)
 				}
-				m.burstTicksRemaining = 10
-				return m, setVolumeCmd(m.client, newVol)
+			} else if full.String() != "" {
+				return m.updateTab(full)
 			}
+			return m.updateTab(msg)
+		}
 
-		case "-", "_":
-			if m.client != nil {
-				newVol := m.volume - 10
-				if newVol < 0 {
-					newVol = 0
-				}
-				m.burstTicksRemaining = 10
-				return m, setVolumeCmd(m.client, newVol)
+		if m.panel.visible {
+			return m.updatePanel(msg)
+		}
+
+		if m.party.visible {
+			return m.updateParty(msg)
+		}
+
+		if m.share.visible {
+			return m.updateShare(msg)
+		}
+
+		if m.genres.visible {
+			return m.updateGenres(msg)
+		}
+
+		if m.trackInfo.visible {
+			return m.updateTrackInfo(msg)
+		}
+
+		if m.update.visible {
+			return m.updateUpdate(msg)
+		}
+
+		if m.themeEditor.visible {
+			return m.updateThemeEditor(msg)
+		}
+
+		if m.lyrics.visible {
+			return m.updateLyrics(msg)
+		}
+
+		if m.tour.visible {
+			return m.updateTour(msg)
+		}
+
+		key, bound := m.keys.resolve(msg.String())
+		if k, ok := m.keys.resolve(full.String()); ok {
+			key, bound = k, true
+		}
+
+		// If help is showing, any key closes it
+		if m.showHelp {
+			if msg.String() == "esc" || key == "?" {
+				m.showHelp = false
+				return m, nil
 			}
+		}
 
-		case "left":
-			if m.client != nil && m.progressMs > 0 {
-				newPos := m.progressMs - 10000
-				if newPos < 0 {
-					newPos = 0
-				}
-				m.burstTicksRemaining = 10
-				return m, seekCmd(m.client, newPos)
+		if m.chord != "" {
+			return m.finishChord(msg.String())
+		}
+		if run, ok := m.settings.ScriptKeys[msg.String()]; ok {
+			return m, m.runScriptCmd(msg.String(), run)
+		}
+		if !bound {
+			if i, ok := m.panelForKey(msg.String()); ok {
+				return m.openPanel(i)
+			}
+			if _, ok := m.chordsFor(msg.String()); ok {
+				return m.startChord(msg.String())
 			}
+		}
+
+		return m.runKey(key)
 
-		case "right":
-			if m.client != nil && m.durationMs > 0 {
-				newPos := m.progressMs + 10000
-				if newPos > m.durationMs {
-					newPos = m.durationMs - 1000
+	case tea.MouseMsg:
+		m.lastInput = time.Now()
+		if m.screensaver {
+			m.screensaver = false
+			return m, nil
+		}
+		if m.reauth.visible {
+			return m, nil
+		}
+
+		if m.audiobooks.visible {
+			return m, nil
+		}
+
+		if m.addToPlaylist.visible || m.playlistEdit.visible || m.smartPlaylist.visible || m.devices.visible || m.panel.visible || m.party.visible || m.share.visible || m.genres.visible || m.trackInfo.visible || m.update.visible || m.themeEditor.visible || m.lyrics.visible || m.tour.visible || m.palette.visible || m.profiles.visible {
+			return m, nil
+		}
+
+		if m.trackList.visible {
+			switch msg.Button {
+			case tea.MouseButtonWheelUp:
+				if m.trackList.cursor > 0 {
+					m.trackList.cursor--
+				}
+			case tea.MouseButtonWheelDown:
+				if m.trackList.cursor < len(m.trackList.tracks)-1 {
+					m.trackList.cursor++
 				}
-				m.burstTicksRemaining = 10
-				return m, seekCmd(m.client, newPos)
 			}
+			return m, nil
+		}
 
-		case "q", "ctrl+c":
-			return m, tea.Quit
+		if m.tabs.active != tabPlayer {
+			return m.updateTab(msg)
 		}
 
-	case tea.MouseMsg:
-		// Handle mouse wheel scrolling in search mode
-		if m.isSearching && len(m.searchResults) > 0 {
+		if m.showEpisodes {
 			switch msg.Button {
 			case tea.MouseButtonWheelUp:
-				if m.searchCursor > 0 {
-					m.searchCursor--
+				if m.episodesCursor > 0 {
+					m.episodesCursor--
 				}
-				return m, nil
 			case tea.MouseButtonWheelDown:
-				if m.searchCursor < len(m.searchResults)-1 {
-					m.searchCursor++
+				if m.episodesCursor < len(m.episodes)-1 {
+					m.episodesCursor++
 				}
-				return m, nil
 			}
+			return m, nil
 		}
 
-		// Ignore mouse-down events to avoid double triggering
-		if msg.Action != tea.MouseActionRelease {
+		// Ignore mouse-down events to avoid double triggering; the big-text
+		// view has no clickable rows
+		if msg.Action != tea.MouseActionRelease || m.bigMode {
 			return m, nil
 		}
 
@@ -297,60 +578,48 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		controlRow := 1 + 1 + 2 + 1 + 1
 
 		if msg.Y == controlRow && m.client != nil {
-			// Build the controls string as in View()
-			playIcon := "▶"
-			if m.isPlaying {
-				playIcon = "⏸"
-			}
-
-			heart := "♡"
-			if m.trackIsLiked {
-				heart = "♥"
-			}
-
-			controlsText := fmt.Sprintf(" [ 🔍 Search ]  [ %s ]  [ ⏮ ]  [ ⏭ ]  [ %s ] ", playIcon, heart)
+			controlsText, buttons := m.controlsLayout()
 			controlsWidth := lipgloss.Width(controlsText)
 
 			// Container width is terminal width minus borders
 			containerWidth := m.width - lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
+				Border(m.border()).
 				GetHorizontalBorderSize()
 
-			// Controls are centered in the container
+			// Controls are centered in the container, after its left border
 			padding := (containerWidth - controlsWidth) / 2
+			offset := msg.X - 1 - padding
+
+			// Only the playback buttons are blocked; search and like work
+			// in read-only mode and on restricted devices too
+			action := controlAt(buttons, offset)
+			if key, ok := map[string]string{"play": "p", "prev": "b", "next": "n"}[action]; ok {
+				if reason := m.controlBlockedReason(key); reason != "" {
+					m.status = reason
+					return m, clearStatusCmd()
+				}
+			}
 
-			// Get mouse X relative to the start of the controls string
-			relativeX := msg.X - padding
+			switch action {
+			case "search":
+				return m.switchTab(tabSearch)
 
-			// Button positions within " [ 🔍 Search ]  [ P ]  [ ⏮ ]  [ ⏭ ]  [ ♡ ] "
-			// Position:                   1-12         15-19  22-26  29-33  36-40
-			switch {
-			case relativeX >= 1 && relativeX <= 12: // Search
-				m.isSearching = true
-				m.searchInput = textinput.New()
-				m.searchInput.Placeholder = "Search for songs..."
-				m.searchInput.SetValue("")
-				m.searchInput.Focus()
-				m.searchResults = nil
-				m.searchCursor = 0
-				return m, m.searchInput.Cursor.BlinkCmd()
-
-			case relativeX >= 15 && relativeX <= 19: // Play/Pause
+			case "play":
 				m.burstTicksRemaining = 10
 				if m.isPlaying {
-					return m, pauseCmd(m.client)
+					return m, m.fadePauseCmd()
 				}
-				return m, resumePlaybackCmd(m.client)
+				return m, m.fadeResumeCmd()
 
-			case relativeX >= 22 && relativeX <= 26: // Previous
+			case "prev":
 				m.burstTicksRemaining = 10
 				return m, prevCmd(m.client)
 
-			case relativeX >= 29 && relativeX <= 33: // Next
+			case "next":
 				m.burstTicksRemaining = 10
 				return m, nextCmd(m.client)
 
-			case relativeX >= 36 && relativeX <= 40: // Heart/Like
+			case "like":
 				if m.currentTrackID != "" {
 					m.burstTicksRemaining = 10
 					return m, toggleLikeCmd(m.client, m.currentTrackID, m.trackIsLiked)
@@ -359,6 +628,14 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		}
 
+		// --- Handle footer clicks ---
+		// header(1) + container border(1) + trackInfo(2) + separator(1) + progress(1)
+		// + controls(1) + volume(1) + separator(1) + indicators(1)
+		footerRow := 1 + 1 + 2 + 1 + 1 + 1 + 1 + 1 + 1
+		if msg.Y == footerRow && m.client != nil {
+			return m.openDevices()
+		}
+
 		// --- Handle progress bar clicks ---
 		// Progress bar is on row: header(1) + container border(1) + trackInfo(2) + separator(1) = row 5
 		progressRow := 1 + 1 + 2 + 1
@@ -375,13 +652,11 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			// Progress bar format: "cur/total [bar]"
-			cur := formatTime(m.progressMs)
-			total := formatTime(m.durationMs)
-			timerWidth := len(cur) + 1 + len(total) + 1 // "cur/total " with space
+			timerWidth := len(m.timerText()) + 1 // "cur/total " with space
 
 			// Calculate where the bar starts (centered in container)
 			containerWidth := m.width - lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
+				Border(m.border()).
 				GetHorizontalBorderSize()
 
 			progressLineWidth := timerWidth + barWidth
@@ -391,6 +666,11 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			barStartX := padding + timerWidth - 2
 			barClickPos := msg.X - barStartX
 
+			if barClickPos < 0 && barClickPos >= -timerWidth {
+				// Clicks on the timer switch between elapsed and remaining
+				m.showRemaining = !m.showRemaining
+				return m, nil
+			}
 			if barClickPos >= 0 && barClickPos < barWidth {
 				// Calculate the seek position
 				ratio := float64(barClickPos) / float64(barWidth)
@@ -401,20 +681,28 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					ratio = 1
 				}
 				seekPos := int(ratio * float64(m.durationMs))
-				m.burstTicksRemaining = 10
-				return m, seekCmd(m.client, seekPos)
+				return m.seek(seekPos)
 			}
 		}
 	case tickMsg:
-		// Determine next tick rate based on burst mode
+		if m.screensaverDue() {
+			m.screensaver = true
+		}
+		if m.screensaver {
+			m.marqueeOffset++
+		}
+
+		// Determine next tick rate based on burst mode; attached, the daemon
+		// sends changes as they come, so there is nothing to poll
 		var nextTick tea.Cmd
-		if m.burstTicksRemaining > 0 {
+		if m.burstTicksRemaining > 0 && !m.settings.ReducedMotion && !attached {
 			m.burstTicksRemaining--
 			nextTick = fastTickCmd()
 		} else {
 			nextTick = tickCmd()
-			// Only smooth progress during normal ticks (not during burst mode)
-			if m.client != nil && m.isPlaying && m.hasInitialState {
+			// Only smooth progress during normal ticks (not during burst mode);
+			// with reduced motion the bar only moves when a poll says so
+			if m.client != nil && m.isPlaying && m.hasInitialState && !m.settings.ReducedMotion {
 				m.progressMs += 1000
 				if m.progressMs > m.durationMs {
 					m.progressMs = m.durationMs
@@ -422,22 +710,222 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if attached {
+			return m, nextTick
+		}
 		return m, tea.Batch(
 			pollStateCmd(m.client),
 			nextTick,
 		)
 
 	case playerStateMsg:
+		prev := m
+		// Something is active already; resume_last_session stays out of it
+		m.resumeChecked = true
+		m.playingType = msg.Type
+		m.shuffle = msg.Shuffle
+		m.repeat = msg.Repeat
+		m.latency = msg.Latency
+		m.pollFailures = 0
+		if msg.Type != "ad" && msg.TrackName == "" {
+			// Between items: keep showing the last one instead of blanking
+			m.hasInitialState = true
+			m.isPlaying = msg.Playing
+			m.device = msg.Device
+			m.lostDevice = ""
+			return m, m.announce(prev)
+		}
+
+		var restoreVolume tea.Cmd
+		if s := m.session; !m.hasInitialState && s != nil && s.DeviceID != "" &&
+			s.DeviceID == string(msg.Device.ID) && msg.Device.SupportsVolume && s.Volume != msg.Volume {
+			restoreVolume = setVolumeCmd(m.client, s.Volume)
+		}
 		m.hasInitialState = true
 		m.trackName = msg.TrackName
+		m.trackExplicit = msg.Explicit
 		m.artistName = msg.ArtistName
-		m.progressMs = msg.ProgressMs
+		if !m.seekPending(msg) {
+			m.progressMs = msg.ProgressMs
+		}
 		m.durationMs = msg.DurationMs
 		m.isPlaying = msg.Playing
 
+		var cmd tea.Cmd
+		if (m.settings.BeatSync || m.needsAnalysis(msg.ID)) && msg.ID != "" && msg.ID != m.analysisTrackID {
+			m.analysisTrackID = msg.ID
+			m.barOffsets = nil
+			m.beatOffsets = nil
+			cmd = fetchAudioAnalysisCmd(m.client, msg.ID)
+		}
+
+		if msg.ContextURI != m.contextURI {
+			m.contextName = ""
+			if msg.ContextURI != "" {
+				cmd = tea.Batch(cmd, fetchContextNameCmd(m.client, msg.ContextURI))
+			}
+		}
+
+		// Liked status is cached; look it up again only when the track changes
+		// (local files have no ID and can't be liked)
+		if msg.ID != "" && msg.ID != m.currentTrackID {
+			cmd = tea.Batch(cmd, fetchLikedCmd(m.client, []spotify.ID{msg.ID}))
+		}
+		if msg.ArtistURI != m.artistURI && m.needsGenres(msg.ArtistURI) {
+			cmd = tea.Batch(cmd, fetchArtistGenresCmd(m.client, uriID(msg.ArtistURI)))
+		}
+		trackChanged := msg.ID != m.currentTrackID
 		m.currentTrackID = msg.ID
-		m.trackIsLiked = msg.Liked
+		m.currentTrackURI = msg.URI
+		m.contextURI = msg.ContextURI
+		m.albumURI = msg.AlbumURI
+		m.albumName = msg.AlbumName
+		m.releaseDate = msg.Released
+		m.artistURI = msg.ArtistURI
+		m.trackIsLiked = m.likeCache[msg.ID]
 		m.volume = msg.Volume
+		m.device = msg.Device
+		m.lostDevice = ""
+		m.artURL = msg.ArtURL
+		var artCmd tea.Cmd
+		m, artCmd = m.updateArt(msg.ArtURL)
+		cmd = tea.Batch(cmd, restoreVolume, artCmd)
+		m.recordPlayback(trackChanged)
+		if trackChanged {
+			cmd = tea.Batch(cmd, m.skipExplicitCmd(msg.TrackName, msg.Explicit))
+		}
+		if m.panel.visible && trackChanged {
+			cmd = tea.Batch(cmd, m.runPanelCmd())
+		}
+		if m.trackInfo.visible && trackChanged {
+			m.trackInfo.err = ""
+			cmd = tea.Batch(cmd, m.trackInfoCmd())
+		}
+		if m.lyrics.visible && trackChanged {
+			var lyricsCmd tea.Cmd
+			m, lyricsCmd = m.lyricsCmd()
+			cmd = tea.Batch(cmd, lyricsCmd)
+		}
+		if m.settings.Notify && trackChanged && msg.TrackName != "" {
+			cmd = tea.Batch(cmd, notifyCmd(msg.TrackName, msg.ArtistName))
+		}
+		if m.settings.TerminalTitle {
+			title := "Spotirice"
+			if msg.TrackName != "" {
+				title = msg.ArtistName + " – " + msg.TrackName
+			}
+			if title != m.title {
+				m.title = title
+				cmd = tea.Batch(cmd, tea.SetWindowTitle(title))
+			}
+		}
+		if id := string(msg.Device.ID); id != "" && id != m.lastDevice {
+			m.lastDevice = id
+			if err := config.SaveLastDevice(id); err != nil {
+				m.status = "Couldn't remember device: " + err.Error()
+			}
+		}
+		var anim tea.Cmd
+		m, anim = m.startTransition(prev)
+		return m, tea.Batch(cmd, anim, m.announce(prev))
+
+	case audioAnalysisMsg:
+		// Ignore late results for a track that is no longer playing
+		if msg.ID == m.analysisTrackID {
+			m.barOffsets = msg.Bars
+			m.beatOffsets = msg.Beats
+		}
+
+	case noPlaybackMsg:
+		if auth.IsRevoked(msg.Err) {
+			m.reauth.visible = true
+			return m, nil
+		}
+		if msg.Err != nil {
+			// Transient API trouble; keep what's on screen until the next poll
+			m.pollFailures++
+			return m, nil
+		}
+		m.pollFailures = 0
+		// The active device went away while playing (phone locked, speaker slept)
+		if m.isPlaying && m.device.Name != "" {
+			m.lostDevice = m.device.Name
+		}
+		m.isPlaying = false
+		m.playingType = ""
+		m.trackName = ""
+		m.artistName = ""
+		m.currentTrackID = ""
+		m.currentTrackURI = ""
+		m.progressMs = 0
+		m.durationMs = 0
+		return m.resumeLastSession()
+
+	case subscribedMsg, feedMsg, feedLostMsg:
+		return m.handleFeed(msg)
+
+	case ControlMsg:
+		return m.handleControl(msg)
+
+	case PowerMsg:
+		return m.handlePower(msg)
+
+	case sleepTimerMsg:
+		return m.handleSleepTimer(msg)
+
+	case focusTimerMsg:
+		return m.handleFocusTimer(msg)
+
+	case scriptDoneMsg:
+		return m.handleScriptDone(msg)
+
+	case partyStateMsg:
+		return m.handlePartyState(msg)
+
+	case partyPollMsg:
+		return m, tea.Batch(fetchPartyCmd(), partyPollCmd())
+
+	case panelOutputMsg:
+		return m.handlePanelOutput(msg)
+
+	case panelRefreshMsg:
+		if m.panel.visible && msg.Gen == m.panel.gen {
+			return m, tea.Batch(m.runPanelCmd(), m.panelRefreshCmd())
+		}
+
+	case transitionTickMsg:
+		if m.transition.frame > 0 {
+			m.transition.frame--
+			if m.transition.frame > 0 {
+				return m, transitionTickCmd()
+			}
+		}
+
+	case visTickMsg:
+		if m.visualizer.visible && m.visualizer.cava == nil {
+			m.visualizer.advance(m)
+			return m, visTickCmd()
+		}
+
+	case visFramesMsg:
+		if m.visualizer.visible && msg.frames == m.visualizer.frames {
+			m.visualizer.levels = msg.Levels
+			return m, waitFramesCmd(msg.frames)
+		}
+
+	case visCavaExitedMsg:
+		if m.visualizer.visible && msg.frames == m.visualizer.frames {
+			// Don't leave the last frame standing
+			m.visualizer.stop()
+			m.visualizer.visible = false
+			m.status = "The visualizer stopped: cava exited."
+			return m, clearStatusCmd()
+		}
+
+	case chordTimeoutMsg:
+		if m.chord != "" && m.chordAt.Equal(msg.At) {
+			m.chord = ""
+		}
 
 	case statusMsg:
 		m.status = string(msg)
@@ -446,27 +934,335 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case clearStatusMsg:
 		m.status = ""
 
+	case accountMsg:
+		m.readOnly = !msg.Premium
+
+	case updateAvailableMsg:
+		m.update.release = &msg.Release
+
+	case lyricsMsg:
+		return m.handleLyrics(msg)
+
+	case playlistsPageMsg:
+		return m.updateTab(msg)
+
+	case likedSongsPageMsg:
+		for _, t := range msg.Tracks {
+			if t.ID != "" {
+				m.likeCache[t.ID] = true
+			}
+		}
+		return m.updateTab(msg)
+
+	case queueMsg:
+		msg.Tracks = m.filterExplicit(msg.Tracks)
+		m, cmd := m.updateTab(msg)
+		return m, tea.Batch(cmd, m.fetchMissingLikesCmd(msg.Tracks))
+
+	case closeTabMsg, playTracksMsg, playContextMsg, queueTrackMsg, addTrackToPlaylistMsg, likeTracksMsg:
+		return m.handleTabRequest(msg)
+
+	case albumArtMsg:
+		// A late result for a cover no longer shown is dropped
+		if msg.URL == m.art.url {
+			m.art.img = msg.Img
+		}
+		if p := m.trackList.playlist; p != nil && msg.URL == p.CoverURL {
+			m.trackList.cover.img = msg.Img
+		}
+
+	case loginStartedMsg:
+		m.reauth.login = msg.Login
+		return m, waitLoginCmd(msg.Login)
+
+	case loginDoneMsg:
+		if msg.Err != nil {
+			m.reauth.login = nil
+			m.reauth.err = msg.Err.Error()
+			return m, nil
+		}
+		m.client = msg.Client
+		m.reauth = reauthView{}
+		m.status = "Logged in again."
+		return m, tea.Batch(pollStateCmd(m.client), clearStatusCmd())
+
 	case errMsg:
+		if auth.IsRevoked(msg.Err) {
+			m.reauth.visible = true
+			return m, nil
+		}
+		m.smartPlaylist.building = false
+		// A failed contents check would otherwise leave Enter blocked
+		m.addToPlaylist.pending = nil
+		if isPremiumRequired(msg.Err) {
+			m.readOnly = true
+			m.status = premiumRequiredReason
+			return m, clearStatusCmd()
+		}
 		m.status = "Error: " + msg.Err.Error()
 		return m, clearStatusCmd()
 
 	case searchResultsMsg:
-		m.searchResults = msg.Tracks
-		m.searchCursor = 0
+		msg.Tracks = m.filterExplicit(msg.Tracks)
+		m, cmd := m.updateTab(msg)
+		return m, tea.Batch(cmd, m.fetchMissingLikesCmd(msg.Tracks))
+
+	case likeBatchMsg:
+		for _, id := range msg.Batch {
+			m.likeCache[id] = msg.Add
+			if id == m.currentTrackID {
+				m.trackIsLiked = msg.Add
+			}
+		}
+		verb := "Liking"
+		if !msg.Add {
+			verb = "Unliking"
+		}
+		// The tab showing lets go of its selection too
+		m, _ = m.updateTab(msg)
+		for id := range m.trackList.selected {
+			delete(m.trackList.selected, id)
+		}
+		if len(msg.Remaining) > 0 {
+			m.status = fmt.Sprintf("%s tracks... %d/%d", verb, msg.Done, msg.Total)
+			return m, batchLikeCmd(m.client, msg.Remaining, msg.Add, msg.Done, msg.Total)
+		}
+		m.status = fmt.Sprintf("%s done: %d tracks.", verb, msg.Total)
+		return m, clearStatusCmd()
+
+	case likedStatusMsg:
+		for id, liked := range msg.Liked {
+			m.likeCache[id] = liked
+		}
+		if liked, ok := msg.Liked[m.currentTrackID]; ok {
+			m.trackIsLiked = liked
+		}
+
+	case audioFeaturesMsg:
+		return m.handleAudioFeatures(msg)
+
+	case artistGenresMsg:
+		// An artist without genres is cached too, so it isn't asked again
+		if msg.Genres == nil {
+			msg.Genres = []string{}
+		}
+		m.genreCache[msg.ID] = msg.Genres
+
+	case contextNameMsg:
+		if msg.URI == m.contextURI {
+			m.contextName = msg.Name
+		}
+
+	case likeToggledMsg:
+		m.likeCache[msg.ID] = msg.Liked
+		if msg.ID == m.currentTrackID {
+			m.trackIsLiked = msg.Liked
+		}
+		m.status = "Removed from Liked Songs."
+		if msg.Liked {
+			m.status = "Added to Liked Songs."
+		}
+		return m, clearStatusCmd()
+
+	case savedEpisodesMsg:
+		m.episodes = msg.Episodes
+		if m.episodesCursor >= len(m.episodes) {
+			m.episodesCursor = 0
+		}
+		if s := m.session; s != nil && s.View == "episodes" {
+			m.episodesCursor = restoredCursor(s.EpisodesCursor, len(m.episodes))
+			s.View = ""
+		}
+
+	case audiobooksAvailableMsg:
+		m.audiobooks.available = msg.Available
+
+	case audiobooksMsg:
+		m.audiobooks.items = msg.Items
+		m.audiobooks.cursor = 0
+
+	case editablePlaylistsMsg:
+		m.addToPlaylist.playlists = msg.Playlists
+		m.addToPlaylist.cursor = 0
+
+	case playlistContentsMsg:
+		m.playlistCache[msg.ID] = msg.Contents
+		if p := m.addToPlaylist.pending; p != nil && p.ID == msg.ID {
+			return m.tryAddToPlaylist(*p)
+		}
+
+	case trackAddedToPlaylistMsg:
+		if contents, ok := m.playlistCache[msg.Playlist.ID]; ok {
+			contents.ids[msg.TrackID] = true
+			contents.snapshot = msg.Snapshot
+			m.playlistCache[msg.Playlist.ID] = contents
+		}
+		m.status = "Added to " + msg.Playlist.Name + "."
+		return m, clearStatusCmd()
+
+	case devicesMsg:
+		m.devices.devices = msg.Devices
+		if !m.devices.loaded {
+			// Start on the device that is playing, so Enter picks another one deliberately
+			m.devices.loaded = true
+			for i, d := range msg.Devices {
+				if d.Active {
+					m.devices.cursor = i
+				}
+			}
+		}
+		if m.devices.cursor >= len(msg.Devices) {
+			m.devices.cursor = 0
+		}
+
+	case deviceRefreshMsg:
+		// Keep re-enumerating while this picker instance is open
+		if m.devices.visible && msg.Gen == m.devices.gen {
+			return m, tea.Batch(fetchDevicesCmd(m.client), deviceRefreshCmd(msg.Gen))
+		}
+
+	case smartPreviewMsg:
+		m.smartPlaylist.building = false
+		if len(msg.Tracks) == 0 {
+			m.status = "No tracks matched those rules."
+			return m, clearStatusCmd()
+		}
+		m.smartPlaylist.preview = msg.Tracks
+		m.smartPlaylist.cursor = 0
+
+	case smartSavedMsg:
+		m.status = fmt.Sprintf("Saved %s with %d tracks.", msg.Name, msg.Count)
+		return m, clearStatusCmd()
+
+	case playlistUpdatedMsg:
+		if p := m.trackList.playlist; p != nil && p.ID == msg.Meta.ID {
+			meta := msg.Meta
+			m.trackList.playlist = &meta
+			m.trackList.title = "Playlist: " + meta.Name
+		}
+		m.status = "Playlist details saved."
+		return m, clearStatusCmd()
+
+	case contextTracksMsg:
+		msg.Tracks = m.filterExplicit(msg.Tracks)
+		m.trackList = trackListView{
+			visible:    true,
+			title:      msg.Title,
+			contextURI: msg.ContextURI,
+			tracks:     msg.Tracks,
+			playlist:   msg.Playlist,
+			selected:   make(map[spotify.ID]bool),
+		}
+		for i, t := range msg.Tracks {
+			if t.URI == msg.Highlight {
+				m.trackList.cursor = i
+				break
+			}
+		}
+		if s := m.session; s != nil && s.ContextURI == string(msg.ContextURI) {
+			m.trackList.cursor = restoredCursor(s.TrackCursor, len(msg.Tracks))
+			s.ContextURI = ""
+		}
+		cmd := m.fetchMissingLikesCmd(msg.Tracks)
+		if p := msg.Playlist; p != nil && p.CoverURL != "" && m.art.enabled {
+			m.trackList.cover.cache = &artRender{}
+			cmd = tea.Batch(cmd, fetchAlbumArtCmd(p.CoverURL))
+		}
+		return m, cmd
+
+	case chaptersMsg:
+		book := msg.Book
+		m.audiobooks.book = &book
+		m.audiobooks.chapters = msg.Chapters
+		m.audiobooks.chCursor = resumeChapter(msg.Chapters)
+
+	case episodeRemovedMsg:
+		for i, se := range m.episodes {
+			if se.Episode.ID == msg.ID {
+				m.episodes = append(m.episodes[:i], m.episodes[i+1:]...)
+				break
+			}
+		}
+		if m.episodesCursor >= len(m.episodes) && m.episodesCursor > 0 {
+			m.episodesCursor--
+		}
+		m.status = "Removed from Your Episodes."
+		return m, clearStatusCmd()
 	}
 
 	return m, nil
 }
 
 func (m RootModel) View() string {
-	// Show help screen if enabled
-	if m.showHelp {
-		return m.renderHelpScreen()
+	return m.withArtCleared(m.view())
+}
+
+// screenOnTop returns the render function of the screen covering the
+// player, if any. View and onMainView both go by it, so a new overlay only
+// needs adding here.
+func (m RootModel) screenOnTop() (func() string, bool) {
+	switch {
+	case m.reauth.visible:
+		return m.renderReauthScreen, true
+	case m.screensaver:
+		return m.renderScreensaver, true
+	case m.showHelp:
+		return m.renderHelpScreen, true
+	case m.showEpisodes:
+		return m.renderEpisodesScreen, true
+	case m.audiobooks.visible:
+		return m.renderAudiobooksScreen, true
+	case m.addToPlaylist.visible:
+		return m.renderAddToPlaylistScreen, true
+	case m.playlistEdit.visible:
+		return m.renderPlaylistEditScreen, true
+	case m.smartPlaylist.visible:
+		return m.renderSmartPlaylistScreen, true
+	case m.devices.visible:
+		return m.renderDevicesScreen, true
+	case m.profiles.visible:
+		return m.renderProfilesScreen, true
+	case m.trackList.visible:
+		return m.renderTrackListScreen, true
+	case m.tabs.active != tabPlayer:
+		return m.renderTabScreen, true
+	case m.palette.visible:
+		return m.renderPaletteScreen, true
+	case m.panel.visible:
+		return m.renderPanelScreen, true
+	case m.party.visible:
+		return m.renderPartyScreen, true
+	case m.share.visible:
+		return m.renderShareScreen, true
+	case m.genres.visible:
+		return m.renderGenresScreen, true
+	case m.trackInfo.visible:
+		return m.renderTrackInfoScreen, true
+	case m.update.visible:
+		return m.renderUpdateScreen, true
+	case m.themeEditor.visible:
+		return m.renderThemeEditorScreen, true
+	case m.lyrics.visible:
+		return m.renderLyricsScreen, true
+	}
+	return nil, false
+}
+
+func (m RootModel) view() string {
+	if m.width > 0 && (m.width < minWidth || m.height < minHeight) {
+		return m.renderTooSmall()
+	}
+
+	if render, ok := m.screenOnTop(); ok {
+		return render()
 	}
 
-	// Show search screen if searching
-	if m.isSearching {
-		return m.renderSearchScreen()
+	if m.settings.ScreenReader {
+		return m.renderPlainMain()
+	}
+
+	if m.bigMode {
+		return m.renderBigMain()
 	}
 
 	// Styles
@@ -476,7 +1272,7 @@ func (m RootModel) View() string {
 		Padding(0, 1)
 
 	trackPlayingStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Foreground(lipgloss.Color(m.transition.fadeColor(m.colors.Status, m.colors.TrackPlaying))).
 		Bold(true)
 
 	trackPausedStyle := lipgloss.NewStyle().
@@ -493,22 +1289,44 @@ func (m RootModel) View() string {
 		Foreground(lipgloss.Color(m.colors.Status))
 
 	containerStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(m.border()).
 		BorderForeground(lipgloss.Color(m.colors.Header))
 
 	// Header
-	header := headerStyle.Render(fmt.Sprintf(" Spotirice v%s", m.version))
+	header := withTabBar(m.colors, tabPlayer, headerStyle.Render(fmt.Sprintf(" Spotirice v%s", m.version)))
+	if notice := m.updateNotice(); notice != "" {
+		header += " " + notice
+	}
 
 	// Track Info
-	trackLine := "No track playing"
+	trackLine := "Nothing playing"
 	artistLine := ""
-	if m.trackName != "" {
+	if m.playingType == "ad" {
+		trackLine = trackPausedStyle.Render("Advertisement")
+		artistLine = statusStyle.Render("Controls return after the ad")
+	} else if m.trackName != "" {
 		if m.isPlaying {
-			trackLine = trackPlayingStyle.Render(m.trackName)
+			trackLine = trackPlayingStyle.Render(m.transition.slideTitle(m.trackName))
 		} else {
-			trackLine = trackPausedStyle.Render(m.trackName + " (paused)")
+			trackLine = trackPausedStyle.Render(m.transition.slideTitle(m.trackName + " (paused)"))
+		}
+		if m.trackExplicit {
+			badgeStyle := lipgloss.NewStyle().Reverse(true).Bold(true)
+			trackLine += " " + badgeStyle.Render(" E ")
 		}
 		artistLine = artistStyle.Render(m.artistName)
+		if m.contextName != "" {
+			artistLine += statusStyle.Render("  •  from: " + m.contextName)
+		}
+		if tags := m.renderGenreTags(); tags != "" {
+			artistLine += statusStyle.Render("  •  ") + tags
+		}
+	}
+	if m.width > 0 {
+		// Keep one line each so the rows below stay where mouse handling expects
+		inner := m.width - containerStyle.GetHorizontalBorderSize()
+		trackLine = truncate(trackLine, inner)
+		artistLine = truncate(artistLine, inner)
 	}
 
 	trackInfo := lipgloss.JoinVertical(lipgloss.Left,
@@ -516,31 +1334,51 @@ func (m RootModel) View() string {
 		artistLine,
 	)
 
-	// Controls
-	playIcon := "▶"
-	if m.isPlaying {
-		playIcon = "⏸"
-	}
-
-	// Like Song
-	heart := "♡"
-	if m.trackIsLiked {
-		heart = "♥"
+	// Controls
+	controls, _ := m.controlsLayout()
+	if m.readOnly {
+		controls = errorStyle.Render("Read-only: playback control needs Spotify Premium") +
+			statusStyle.Render("  (l like · s search · c context)")
+	}
+	if m.tourHighlights("controls") {
+		controls = highlightRow(controls)
 	}
-
-	controls := fmt.Sprintf(" [ 🔍 Search ]  [ %s ]  [ ⏮ ]  [ ⏭ ]  [ %s ] ", playIcon, heart)
 
 	// Volume bar
 	volumeLine := fmt.Sprintf("🔊 %d%%", m.volume)
+	if m.muted {
+		volumeLine = fmt.Sprintf("🔇 muted (m to restore %d%%)", m.preMuteVolume)
+	}
+	if m.hasInitialState && !m.device.SupportsVolume {
+		volumeLine = statusStyle.Render("🔈 volume n/a on this device")
+	}
 
 	// Progress Bar
 	barLine := m.renderProgressLine()
 
+	indicatorLine := m.renderIndicators()
+
 	// Status
 	statusLine := statusStyle.Render(m.status + "  |  ? for help")
 	if strings.HasPrefix(m.status, "Error:") {
 		statusLine = errorStyle.Render(m.status)
 	}
+	if m.device.Name != "" && m.lostDevice == "" {
+		// Clicking the footer opens the device picker
+		statusLine = artistStyle.Render(m.deviceIcon(m.device.Type)+" "+m.device.Name) + statusStyle.Render("  |  ") + statusLine
+	}
+	if m.lostDevice != "" {
+		statusLine = errorStyle.Render("⚠ "+m.lostDevice+" disconnected (d to pick a device)") + "  " + statusLine
+	}
+	if m.chord != "" {
+		statusLine = m.renderChordHint()
+		if m.width > 0 {
+			statusLine = truncate(statusLine, m.width-2)
+		}
+	}
+	if m.tourHighlights("status") {
+		statusLine = highlightRow(statusLine)
+	}
 
 	// Assembly
 	ui := lipgloss.JoinVertical(lipgloss.Center,
@@ -550,6 +1388,7 @@ func (m RootModel) View() string {
 		controls,
 		volumeLine,
 		"",
+		indicatorLine,
 		statusLine,
 	)
 
@@ -557,6 +1396,21 @@ func (m RootModel) View() string {
 	w := m.width - containerStyle.GetHorizontalBorderSize()
 	// Height: terminal height minus header (1 line) minus container border (2 lines)
 	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if m.tour.visible {
+		// Below the status line and no wider than the player, so the rows
+		// the cards talk about stay where they always are
+		ui = lipgloss.JoinVertical(lipgloss.Center, ui, "", m.renderTourCard(min(max(lipgloss.Width(ui), 30), w)))
+	}
+	if art := m.renderAlbumArt(h-lipgloss.Height(ui)-1, w-2); art != "" {
+		// Below the status line too, keeping the clickable rows in place
+		ui = lipgloss.JoinVertical(lipgloss.Center, ui, "", art)
+	}
+	if m.visualizer.visible {
+		// Below the status line so the clickable rows above stay put
+		if vis := m.renderVisualizer(h-lipgloss.Height(ui)-1, w); vis != "" {
+			ui = lipgloss.JoinVertical(lipgloss.Center, ui, "", vis)
+		}
+	}
 	if h < 1 {
 		h = lipgloss.Height(ui) // Fallback to content height
 	}
@@ -568,138 +1422,264 @@ func (m RootModel) View() string {
 	)
 }
 
-func (m RootModel) renderHelpScreen() string {
-	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(m.colors.Header)).
-		Bold(true).
-		Padding(0, 1)
-
-	containerStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(m.colors.Header)).
-		Padding(1, 2)
-
-	helpText := `
-Keyboard Controls
-─────────────────
-  p / Space    Play/Pause
-  n            Next track
-  b            Previous track
-  l            Like/Unlike song
+// Smallest terminal the player view fits in; below this a resize hint is
+// shown instead of a garbled layout.
+const (
+	minWidth  = 32
+	minHeight = 12
+)
 
-  + / =        Volume up (+10%)
-  - / _        Volume down (-10%)
+// renderIndicators shows shuffle, repeat and how healthy the connection to
+// the Web API is.
+func (m RootModel) renderIndicators() string {
+	if !m.hasInitialState {
+		return ""
+	}
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Status))
+	onStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.TrackPlaying))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Error))
 
-  ← / →        Seek -/+10 seconds
+	shuffle := dimStyle.Render("🔀 off")
+	if m.shuffle {
+		shuffle = onStyle.Render("🔀 on")
+	}
+	repeat := dimStyle.Render("🔁 off")
+	switch m.repeat {
+	case "context":
+		repeat = onStyle.Render("🔁 all")
+	case "track":
+		repeat = onStyle.Render("🔂 one")
+	}
 
-  s / /        Search for songs
-  ?            Toggle help
-  q / Ctrl+C   Quit
+	var conn string
+	switch {
+	case m.pollFailures >= 3:
+		conn = errorStyle.Render("● offline")
+	case m.pollFailures > 0 || m.latency > time.Second:
+		conn = errorStyle.Render(fmt.Sprintf("● slow (%dms)", m.latency.Milliseconds()))
+	case m.latency > 300*time.Millisecond:
+		conn = dimStyle.Render(fmt.Sprintf("● %dms", m.latency.Milliseconds()))
+	default:
+		conn = onStyle.Render(fmt.Sprintf("● %dms", m.latency.Milliseconds()))
+	}
 
-Press ESC or ? to close this screen
-`
+	parts := []string{shuffle, repeat}
+	if !m.sleepAt.IsZero() {
+		parts = append(parts, onStyle.Render("💤 "+m.sleepRemaining()))
+	}
+	if m.focus.active {
+		parts = append(parts, onStyle.Render(m.focusIndicator()))
+	}
+	if party := m.partyIndicator(); party != "" {
+		parts = append(parts, onStyle.Render(party))
+	}
+	parts = append(parts, conn)
+	line := strings.Join(parts, dimStyle.Render("  •  "))
+	if m.width > 0 {
+		line = truncate(line, m.width-2)
+	}
+	return line
+}
 
-	header := headerStyle.Render(" Spotirice Help")
-	helpBox := containerStyle.Render(helpText)
+func (m RootModel) renderTooSmall() string {
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status)).
+		Width(m.width).
+		Align(lipgloss.Center)
+	msg := fmt.Sprintf("Terminal too small (%dx%d)\nResize to at least %dx%d", m.width, m.height, minWidth, minHeight)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, style.Render(msg))
+}
 
-	return lipgloss.JoinVertical(lipgloss.Left,
-		header,
-		helpBox,
-	)
+// controlButton is a clickable span of the control row, in cells from the
+// start of the controls text.
+type controlButton struct {
+	start, end int
+	action     string
 }
 
-func (m RootModel) renderSearchScreen() string {
-	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(m.colors.Header)).
-		Bold(true).
-		Padding(0, 1)
+// controlsLayout builds the control row and the position of each button. The
+// labelled layout is used when it fits; narrow terminals get bare icons.
+func (m RootModel) controlsLayout() (string, []controlButton) {
+	playIcon := "▶"
+	if m.isPlaying {
+		playIcon = "⏸"
+	}
+	heart := "♡"
+	if m.trackIsLiked {
+		heart = "♥"
+	}
 
-	containerStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(m.colors.Header)).
-		Padding(1, 2)
+	labels := []string{"[ 🔍 Search ]", "[ " + playIcon + " ]", "[ ⏮ ]", "[ ⏭ ]", "[ " + heart + " ]"}
+	// 2 spaces between and 1 on each side of the five buttons
+	if m.width > 0 && m.width-2 < 2+4*2+lipgloss.Width(strings.Join(labels, "")) {
+		labels = []string{"🔍", playIcon, "⏮", "⏭", heart}
+	}
+	actions := []string{"search", "play", "prev", "next", "like"}
+	if m.playingType == "episode" {
+		// Nothing to like
+		labels, actions = labels[:4], actions[:4]
+	}
 
-	selectedStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
-		Bold(true)
+	var b strings.Builder
+	buttons := make([]controlButton, len(labels))
+	b.WriteString(" ")
+	for i, label := range labels {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		start := lipgloss.Width(b.String())
+		b.WriteString(label)
+		buttons[i] = controlButton{start: start, end: start + lipgloss.Width(label), action: actions[i]}
+	}
+	b.WriteString(" ")
+	return b.String(), buttons
+}
 
-	normalStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(m.colors.Artist))
+// controlAt returns the action of the button covering offset, or "".
+func controlAt(buttons []controlButton, offset int) string {
+	for _, b := range buttons {
+		if offset >= b.start && offset < b.end {
+			return b.action
+		}
+	}
+	return ""
+}
 
-	header := headerStyle.Render(" 🔍 Search")
-	inputLine := "Search: " + m.searchInput.View()
+// helpRow is a line of the help screen: an action, listed under the keys
+// it has in the keymap, or fixed keys. The zero helpRow is a blank line.
+type helpRow struct {
+	action string
+	keys   string
+	desc   string
+}
 
-	var resultLines []string
-	resultLines = append(resultLines, inputLine, "")
+var helpPlayback = []helpRow{
+	{action: "play_pause", desc: "Play/Pause"},
+	{action: "next", desc: "Next track"},
+	{action: "prev", desc: "Previous track"},
+	{action: "like", desc: "Like/Unlike song"},
+	{action: "add_to_playlist", desc: "Add song to a playlist"},
+	{action: "smart_playlist", desc: "Smart playlist builder"},
+	{action: "devices", desc: "Devices (transfer, volume)"},
+	{},
+	{action: "volume_up", desc: "Volume up (+10%)"},
+	{action: "volume_down", desc: "Volume down (-10%)"},
+	{action: "mute", desc: "Mute/unmute"},
+	{},
+	{action: "seek_back", desc: "Seek back 10 seconds"},
+	{action: "seek_forward", desc: "Seek forward 10 seconds"},
+	{action: "seek_back_long", desc: "Seek back 30 seconds"},
+	{action: "seek_forward_long", desc: "Seek forward 30 seconds"},
+	{keys: "Shift+Space", desc: "Restart track"},
+	{action: "remaining_time", desc: "Elapsed/remaining time"},
+	{action: "sleep_timer", desc: "Sleep timer (15–90 min, off)"},
+	{action: "focus", desc: "Focus mode (pomodoro)"},
+	{action: "party", desc: "Party requests"},
+	{action: "share", desc: "Share (QR code)"},
+	{action: "track_info", desc: "Track details (tempo, key, energy)"},
+	{action: "lyrics", desc: "Lyrics (synced where available)"},
+	{action: "big_mode", desc: "Big-text view"},
+	{action: "theme_editor", desc: "Theme editor"},
+	{action: "visualizer", desc: "Visualizer"},
+}
 
-	if len(m.searchResults) == 0 {
-		if m.searchInput.Value() != "" {
-			resultLines = append(resultLines, "Press Enter to search...")
-		} else {
-			resultLines = append(resultLines, "Type to search for songs, then press Enter")
-		}
-	} else {
-		// Scrollable results - calculate max visible based on terminal height
-		// Reserve lines for: header(1) + border(2) + padding(2) + search input(1) + blank(1) + results header(1) + blank(1) + footer(2)
-		reservedLines := 11
-		maxVisible := m.height - reservedLines
-		if maxVisible < 3 {
-			maxVisible = 3 // Minimum 3 results
-		}
-		if maxVisible > len(m.searchResults) {
-			maxVisible = len(m.searchResults)
-		}
+// helpNavigation is followed by the g chords, and the vim preset's keys.
+var helpNavigation = []helpRow{
+	{action: "search", desc: "Search for songs"},
+	{action: "context", desc: "Open current context"},
+	{action: "playlists", desc: "Your playlists"},
+	{action: "liked_songs", desc: "Liked Songs"},
+	{action: "episodes", desc: "Your Episodes"},
+	{action: "audiobooks", desc: "Audiobooks"},
+	{action: "update", desc: "Release notes of an update"},
+	{action: "profiles", desc: "Switch profile"},
+	{action: "next_tab", desc: "Next tab"},
+	{action: "prev_tab", desc: "Previous tab"},
+	{action: "player_tab", desc: "Player tab"},
+	{action: "queue_tab", desc: "Queue tab"},
+	{action: "library_tab", desc: "Library tab"},
+	{action: "search_tab", desc: "Search tab"},
+	{action: "command_palette", desc: "Command palette"},
+	{action: "help", desc: "Toggle help"},
+	{action: "quit", desc: "Quit"},
+	{keys: "Ctrl+C", desc: "Quit"},
+}
 
-		start := 0
-		if m.searchCursor >= maxVisible {
-			start = m.searchCursor - maxVisible + 1
+// helpLines renders rows with the keys of the current keymap, leaving out
+// actions that have none.
+func (m RootModel) helpLines(rows []helpRow) string {
+	byName := make(map[string]action, len(actions))
+	for _, a := range actions {
+		byName[a.name] = a
+	}
+	var b strings.Builder
+	for _, r := range rows {
+		keys := r.keys
+		if r.action != "" {
+			keys = keyLabels(m.keys.keysFor(byName[r.action]))
+			if keys == "" {
+				continue
+			}
 		}
-		end := start + maxVisible
-		if end > len(m.searchResults) {
-			end = len(m.searchResults)
+		if r.desc == "" {
+			b.WriteString("\n")
+			continue
 		}
+		fmt.Fprintf(&b, "  %-12s %s\n", keys, r.desc)
+	}
+	return b.String()
+}
 
-		resultLines = append(resultLines, fmt.Sprintf("Results %d-%d of %d (↑/↓ to scroll, Enter to play):", start+1, end, len(m.searchResults)), "")
+func (m RootModel) renderHelpScreen() string {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
 
-		if start > 0 {
-			resultLines = append(resultLines, normalStyle.Render("  ↑ more results above"))
-		}
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
 
-		for i := start; i < end; i++ {
-			track := m.searchResults[i]
-			artist := ""
-			if len(track.Artists) > 0 {
-				artist = track.Artists[0].Name
-			}
-			line := fmt.Sprintf("  %s - %s", track.Name, artist)
-			if i == m.searchCursor {
-				line = selectedStyle.Render("▶ " + line[2:])
-			} else {
-				line = normalStyle.Render(line)
+	playbackHelp := "\nKeyboard Controls\n─────────────────\n" + m.helpLines(helpPlayback)
+	navigation := append([]helpRow(nil), helpNavigation...)
+	if !m.audiobooks.available {
+		// Audiobooks are only listed where the API exposes them
+		navigation = slices.DeleteFunc(navigation, func(r helpRow) bool { return r.action == "audiobooks" })
+	}
+	for _, c := range chords["g"] {
+		navigation = append(navigation, helpRow{keys: "g " + c.key, desc: "Go to " + c.desc})
+	}
+	if m.keys.vim {
+		navigation = append(navigation,
+			helpRow{keys: "j, k", desc: "Move in lists"},
+			helpRow{keys: "gg, G", desc: "First / last in lists"},
+			helpRow{keys: ":q", desc: "Quit"})
+	}
+	closeKeys := "ESC"
+	for _, a := range actions {
+		if a.name == "help" {
+			if keys := m.keys.keysFor(a); len(keys) > 0 {
+				closeKeys += " or " + keyLabels(keys)
 			}
-			resultLines = append(resultLines, line)
-		}
-
-		if end < len(m.searchResults) {
-			resultLines = append(resultLines, normalStyle.Render("  ↓ more results below"))
 		}
 	}
+	navigationHelp := "\n" + m.helpLines(navigation) + "\nPress " + closeKeys + " to close this screen\n"
 
-	resultLines = append(resultLines, "", "Press ESC to cancel")
-
-	content := strings.Join(resultLines, "\n")
-
-	// Make container fill terminal width and height
-	w := m.width - containerStyle.GetHorizontalBorderSize()
-	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
-	if h < 1 {
-		h = lipgloss.Height(content)
+	header := headerStyle.Render(" Spotirice Help")
+	helpBox := containerStyle.Render(playbackHelp + navigationHelp)
+	if m.height > 0 && lipgloss.Height(helpBox)+1 > m.height {
+		// Short terminal: put the two halves side by side if that fits
+		wide := containerStyle.Padding(0, 2).Render(
+			lipgloss.JoinHorizontal(lipgloss.Top, playbackHelp, "    ", navigationHelp))
+		if lipgloss.Width(wide) <= m.width {
+			helpBox = wide
+		}
 	}
-	searchBox := containerStyle.Width(w).Height(h).Render(content)
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		header,
-		searchBox,
+		helpBox,
 	)
 }
 
@@ -708,7 +1688,7 @@ func (m RootModel) renderProgressLine() string {
 		return ""
 	}
 
-	progressStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.ProgressBar))
+	progressStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.progressBarColor()))
 	emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Artist)) // Use a dimmer color
 
 	w := m.width
@@ -728,6 +1708,7 @@ func (m RootModel) renderProgressLine() string {
 	if ratio > 1 {
 		ratio = 1
 	}
+	ratio = m.transition.sweepRatio(ratio)
 
 	filled := int(ratio * float64(barWidth))
 	if filled > barWidth {
@@ -736,13 +1717,60 @@ func (m RootModel) renderProgressLine() string {
 	empty := barWidth - filled
 
 	// Use distinct characters: ━ for filled (progress), ─ for empty (remaining)
-	left := progressStyle.Render(strings.Repeat("━", filled))
-	right := emptyStyle.Render(strings.Repeat("─", empty))
+	var left, right string
+	if len(m.barOffsets) == 0 || !m.settings.BeatSync {
+		left = progressStyle.Render(strings.Repeat("━", filled))
+		right = emptyStyle.Render(strings.Repeat("─", empty))
+	} else {
+		// Beat sync: mark bar starts and pulse the playhead on each beat
+		ticks := barTickCells(m.barOffsets, m.durationMs, barWidth)
+		var lb, rb strings.Builder
+		for i := 0; i < filled; i++ {
+			switch {
+			case i == filled-1 && !m.settings.ReducedMotion && onBeat(m.beatOffsets, m.progressMs):
+				lb.WriteString("╋")
+			case ticks[i]:
+				lb.WriteString("┿")
+			default:
+				lb.WriteString("━")
+			}
+		}
+		for i := filled; i < barWidth; i++ {
+			if ticks[i] {
+				rb.WriteString("┼")
+			} else {
+				rb.WriteString("─")
+			}
+		}
+		left = progressStyle.Render(lb.String())
+		right = emptyStyle.Render(rb.String())
+	}
 
-	cur := formatTime(m.progressMs)
+	return fmt.Sprintf("%s %s%s", m.timerText(), left, right)
+}
+
+// timerText is "elapsed/total", or "-remaining/total" when showRemaining is set.
+func (m RootModel) timerText() string {
 	total := formatTime(m.durationMs)
+	if m.showRemaining {
+		return "-" + formatTime(m.durationMs-m.progressMs) + "/" + total
+	}
+	return formatTime(m.progressMs) + "/" + total
+}
 
-	return fmt.Sprintf("%s/%s %s%s", cur, total, left, right)
+// progressBarColor switches to the ending accent during the final seconds of
+// a track, as a cue that the next one is coming up.
+func (m RootModel) progressBarColor() string {
+	c := m.colors
+	remaining := m.durationMs - m.progressMs
+	if c.ProgressBarEnding == "" || remaining > c.EndingSeconds*1000 {
+		return c.ProgressBar
+	}
+	if c.EndingBlink && !m.settings.ReducedMotion && (remaining/1000)%2 == 0 {
+		// The smooth-progress tick advances once a second, so this flips each tick
+		return c.ProgressBar
+	}
+	return c.ProgressBarEnding
 }
 
 func formatTime(ms int) string {
@@ -750,75 +1778,363 @@ func formatTime(ms int) string {
 		ms = 0
 	}
 	totalSec := ms / 1000
+	if totalSec >= 3600 {
+		// Podcast episodes and audiobook chapters run past an hour
+		return fmt.Sprintf("%d:%02d:%02d", totalSec/3600, totalSec/60%60, totalSec%60)
+	}
 	min := totalSec / 60
 	sec := totalSec % 60
 	return fmt.Sprintf("%d:%02d", min, sec)
 }
 
-// NewRootModel builds the root UI and starts polling.
-func NewRootModel(c *spotify.Client, colors *config.Colors, version string) (RootModel, tea.Cmd) {
-	m := RootModel{
-		client:  c,
-		status:  "Authenticated. Use p/space to play/pause, n/b to skip.",
-		colors:  colors,
-		version: version,
+// controlBlockedReason explains why a playback control key can't be used on
+// the active device, or returns "" when it can. Restricted devices (some TVs
+// and speakers) reject every Web API command with 403.
+func (m RootModel) controlBlockedReason(key string) string {
+	if m.readOnly {
+		return premiumRequiredReason
+	}
+	if m.playingType == "ad" {
+		switch key {
+		case "n", "b", "left", "right", "ctrl+left", "ctrl+right":
+			return "Ads can't be skipped or seeked."
+		}
+	}
+	if !m.hasInitialState || m.device.ID == "" {
+		return ""
+	}
+	if m.device.Restricted {
+		return m.device.Name + " is restricted: Spotify doesn't allow remote control of it."
+	}
+	switch key {
+	case "+", "=", "-", "_", "m", "0":
+		if !m.device.SupportsVolume {
+			return m.device.Name + " doesn't support remote volume control."
+		}
 	}
-	return m, m.Init()
+	return ""
 }
 
-// ------------------ Commands ------------------
+// runKey carries out what key does on the player, key being a built-in key
+// (see keyMap).
+func (m RootModel) runKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "/", "s":
+		// Enter search mode
+		return m.switchTab(tabSearch)
+
+	case "?":
+		m.showHelp = !m.showHelp
+		return m, nil
+
+	case "e":
+		if m.client != nil {
+			return m.openEpisodes()
+		}
 
-func ensureActiveDevice(c *spotify.Client) error {
-	ctx := context.Background()
+	case "a":
+		if m.client != nil && m.audiobooks.available {
+			return m.openAudiobooks()
+		}
 
-	devices, err := c.PlayerDevices(ctx)
-	if err != nil {
-		return err
-	}
-	if len(devices) == 0 {
-		return fmt.Errorf("no devices found; open Spotify on a device")
+	case "c":
+		if m.client != nil {
+			return m.openCurrentContext()
+		}
+
+	case "S":
+		if m.client != nil {
+			return m.openSmartPlaylist()
+		}
+
+	case "d":
+		if m.client != nil {
+			return m.openDevices()
+		}
+
+	case "R":
+		if m.client != nil && m.settings.Party.Addr != "" {
+			return m.openParty()
+		}
+
+	case "Q":
+		return m.openShare()
+
+	case "i":
+		if m.client != nil {
+			return m.openTrackInfo()
+		}
+
+	case "U":
+		return m.openUpdate()
+
+	case "T":
+		return m.openThemeEditor()
+
+	case "y":
+		return m.openLyrics()
+
+	case "P":
+		if m.client != nil {
+			return m.switchTab(tabLibrary)
+		}
+
+	case "O":
+		return m.openProfiles()
+
+	case "L":
+		if m.client != nil {
+			return m.openTab(tabLibrary, newLibraryTab(true))
+		}
+
+	case "A":
+		if m.client != nil && m.currentTrackID != "" {
+			track := spotify.FullTrack{}
+			track.ID = m.currentTrackID
+			track.URI = m.currentTrackURI
+			track.Name = m.trackName
+			track.Artists = []spotify.SimpleArtist{{Name: m.artistName}}
+			return m.openAddToPlaylist(track)
+		}
+
+	case "p", " ", "n", "b", "left", "right", "ctrl+left", "ctrl+right", "+", "=", "-", "_", "m", "0", "z", "F":
+		if reason := m.controlBlockedReason(key); reason != "" {
+			m.status = reason
+			return m, clearStatusCmd()
+		}
 	}
 
-	var active *spotify.PlayerDevice
-	var firstValid *spotify.PlayerDevice
+	switch key {
+	case "p", " ":
+		if m.client == nil {
+			return m, nil
+		}
+		m.burstTicksRemaining = 10 // Fast polling for 1 second
+		if m.isPlaying {
+			return m, m.fadePauseCmd()
+		}
+		return m, m.fadeResumeCmd()
+
+	case "n":
+		if m.client == nil {
+			return m, nil
+		}
+		m.burstTicksRemaining = 10
+		return m, nextCmd(m.client)
 
-	for i := range devices {
-		d := &devices[i]
+	case "b":
+		if m.client == nil {
+			return m, nil
+		}
+		m.burstTicksRemaining = 10
+		return m, prevCmd(m.client)
 
-		if d.Restricted {
-			continue
+	case "l":
+		if m.currentTrackID != "" {
+			m.burstTicksRemaining = 10
+			return m, toggleLikeCmd(m.client, m.currentTrackID, m.trackIsLiked)
 		}
-		if d.Type != "Computer" && d.Type != "Smartphone" && d.Type != "Speaker" {
-			continue
+		if m.playingType == "episode" {
+			m.status = "Episodes can't be liked; e opens Your Episodes."
+			return m, clearStatusCmd()
+		}
+		if m.trackName != "" {
+			m.status = "Local files can't be liked."
+			return m, clearStatusCmd()
+		}
+
+	case "+", "=":
+		if m.client != nil {
+			newVol := m.volume + 10
+			if newVol > 100 {
+				newVol = 100
+			}
+			m.muted = false
+			m.burstTicksRemaining = 10
+			return m, setVolumeCmd(m.client, newVol)
+		}
+
+	case "-", "_":
+		if m.client != nil {
+			newVol := m.volume - 10
+			if newVol < 0 {
+				newVol = 0
+			}
+			m.muted = false
+			m.burstTicksRemaining = 10
+			return m, setVolumeCmd(m.client, newVol)
+		}
+
+	case "m", "0":
+		if m.client != nil {
+			m.burstTicksRemaining = 10
+			if m.muted {
+				m.muted = false
+				return m, setVolumeCmd(m.client, m.preMuteVolume)
+			}
+			if m.volume > 0 {
+				m.muted = true
+				m.preMuteVolume = m.volume
+				return m, setVolumeCmd(m.client, 0)
+			}
+		}
+
+	case "left":
+		if m.client != nil && m.progressMs > 0 {
+			newPos := m.progressMs - 10000
+			if newPos < 0 {
+				newPos = 0
+			}
+			return m.seek(newPos)
+		}
+
+	case "right":
+		if m.client != nil && m.durationMs > 0 {
+			newPos := m.progressMs + 10000
+			if newPos > m.durationMs {
+				newPos = m.durationMs - 1000
+			}
+			return m.seek(newPos)
+		}
+
+	case "ctrl+left", "ctrl+right":
+		// Longer jumps for podcasts and audiobooks
+		if m.client != nil && m.durationMs > 0 {
+			newPos := m.progressMs - 30000
+			if key == "ctrl+right" {
+				newPos = m.progressMs + 30000
+			}
+			return m.seek(min(max(newPos, 0), m.durationMs-1000))
 		}
 
-		if firstValid == nil {
-			firstValid = d
+	case "t":
+		m.showRemaining = !m.showRemaining
+		return m, nil
+
+	case "z":
+		if m.client != nil {
+			return m.cycleSleepTimer()
 		}
-		if d.Active {
-			active = d
-			break
+
+	case "F":
+		if m.client != nil {
+			return m.toggleFocus()
 		}
+
+	case "B":
+		m.bigMode = !m.bigMode
+		return m, nil
+
+	case "v":
+		return m.toggleVisualizer()
+
+	case "ctrl+p":
+		return m.openPalette()
+
+	case "tab", "shift+tab", "1", "2", "3", "4":
+		t, _ := tabForKey(key, tabPlayer)
+		return m.switchTab(t)
+
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// onMainView reports whether the player itself is showing, with no other
+// screen over it.
+func (m RootModel) onMainView() bool {
+	_, covered := m.screenOnTop()
+	return !covered && !m.tour.visible
+}
+
+// IsPlaying reports whether playback was running at the last poll.
+func (m RootModel) IsPlaying() bool {
+	return m.isPlaying
+}
+
+// NewRootModel builds the root UI and starts polling.
+func NewRootModel(c *spotify.Client, colors *config.Colors, settings *config.Settings, version string) (RootModel, tea.Cmd) {
+	m := RootModel{
+		client:        c,
+		status:        "Authenticated. Use p/space to play/pause, n/b to skip.",
+		colors:        colors,
+		settings:      settings,
+		version:       version,
+		likeCache:     make(map[spotify.ID]bool),
+		genreCache:    make(map[spotify.ID][]string),
+		featureCache:  make(map[spotify.ID]*spotify.AudioFeatures),
+		playlistCache: make(map[spotify.ID]playlistContents),
+		lastInput:     time.Now(),
+	}
+	var err error
+	if m.art, err = newArtView(settings.AlbumArt); err != nil {
+		m.status = "album_art: " + err.Error()
+	}
+	if m.keys, err = newKeyMap(settings.Keymap, settings.Keybindings); err != nil {
+		m.status = "keybindings: " + err.Error()
+	}
+	m.lastDevice, _ = config.LoadLastDevice()
+	// A broken session file just means starting on the main screen
+	m.session, _ = config.LoadSession()
+	if m.session != nil {
+		m.showRemaining = m.session.ShowRemaining
+	}
+	// Someone who has played through Spotirice before knows their way around
+	m.tour.visible = m.lastDevice == "" && m.session == nil && !config.TourDone()
+	m, restore := m.restoreSession()
+	return m, tea.Batch(m.Init(), restore)
+}
+
+// ------------------ Commands ------------------
+
+func ensureActiveDevice(c *spotify.Client, preferred, last string) error {
+	ctx := context.Background()
+
+	devs, err := c.PlayerDevices(ctx)
+	if err != nil {
+		return err
+	}
+	if len(devs) == 0 {
+		return fmt.Errorf("no devices found; open Spotify on a device")
 	}
 
 	// If we already have an active device → DO NOT TRANSFER.
-	if active != nil {
-		return nil
+	for _, d := range devs {
+		if d.Active && !d.Restricted {
+			return nil
+		}
 	}
 
 	// Only transfer when absolutely required.
-	if firstValid != nil {
-		return c.TransferPlayback(ctx, firstValid.ID, false)
+	target, _ := devices.Choose(devs, preferred, last, "Computer", "Smartphone", "Speaker")
+	if target != nil {
+		return c.TransferPlayback(ctx, target.ID, false)
 	}
 
 	return fmt.Errorf("no controllable devices available")
 }
 
-func resumePlaybackCmd(c *spotify.Client) tea.Cmd {
+// checkPreferredDeviceCmd warns when the configured preferred device isn't
+// among the available devices.
+func checkPreferredDeviceCmd(c *spotify.Client, preferred string) tea.Cmd {
+	return func() tea.Msg {
+		devs, err := c.PlayerDevices(context.Background())
+		if err != nil {
+			return nil
+		}
+		if devices.Find(devs, preferred) == nil {
+			return statusMsg(fmt.Sprintf("Preferred device %q not available; using another device.", preferred))
+		}
+		return nil
+	}
+}
+
+func resumePlaybackCmd(c *spotify.Client, preferred, last string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 
-		if err := ensureActiveDevice(c); err != nil {
+		if err := ensureActiveDevice(c, preferred, last); err != nil {
 			return errMsg{Err: err}
 		}
 
@@ -847,6 +2163,16 @@ func pauseCmd(c *spotify.Client) tea.Cmd {
 	}
 }
 
+// notifyCmd shows the track that started playing as a desktop notification.
+func notifyCmd(track, artist string) tea.Cmd {
+	return func() tea.Msg {
+		if err := platform.Notify(track, artist); err != nil {
+			return statusMsg("Could not show a notification: " + err.Error())
+		}
+		return nil
+	}
+}
+
 func nextCmd(c *spotify.Client) tea.Cmd {
 	return func() tea.Msg {
 		if err := c.Next(context.Background()); err != nil {
@@ -874,14 +2200,14 @@ func toggleLikeCmd(c *spotify.Client, trackID spotify.ID, currentlyLiked bool) t
 			if err := c.RemoveTracksFromLibrary(ctx, trackID); err != nil {
 				return errMsg{Err: err}
 			}
-			return statusMsg("Removed from Liked Songs.")
+			return likeToggledMsg{ID: trackID, Liked: false}
 		}
 
 		// Add to liked
 		if err := c.AddTracksToLibrary(ctx, trackID); err != nil {
 			return errMsg{Err: err}
 		}
-		return statusMsg("Added to Liked Songs.")
+		return likeToggledMsg{ID: trackID, Liked: true}
 	}
 }
 
@@ -894,6 +2220,34 @@ func setVolumeCmd(c *spotify.Client, volume int) tea.Cmd {
 	}
 }
 
+// seekGrace is how long polls may still report the position from before a
+// seek while Spotify applies it.
+const seekGrace = 2 * time.Second
+
+// seek shows the new position right away and asks Spotify to move there.
+func (m RootModel) seek(positionMs int) (RootModel, tea.Cmd) {
+	m.progressMs = positionMs
+	m.seekTarget = positionMs
+	m.seekAt = time.Now()
+	m.burstTicksRemaining = 10
+	return m, seekCmd(m.client, positionMs)
+}
+
+// seekPending reports whether a poll predates the last seek and should not
+// move the progress bar back.
+func (m RootModel) seekPending(msg playerStateMsg) bool {
+	since := time.Since(m.seekAt)
+	if since > seekGrace || msg.ID != m.currentTrackID {
+		return false
+	}
+	expected := m.seekTarget
+	if msg.Playing {
+		expected += int(since.Milliseconds())
+	}
+	diff := msg.ProgressMs - expected
+	return diff > 1500 || diff < -1500
+}
+
 func seekCmd(c *spotify.Client, positionMs int) tea.Cmd {
 	return func() tea.Msg {
 		if err := c.Seek(context.Background(), positionMs); err != nil {
@@ -906,7 +2260,8 @@ func seekCmd(c *spotify.Client, positionMs int) tea.Cmd {
 func searchCmd(c *spotify.Client, query string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		results, err := c.Search(ctx, query, spotify.SearchTypeTrack)
+		// Passing a market makes the API report is_playable for each track
+		results, err := c.Search(ctx, query, spotify.SearchTypeTrack, spotify.Market(spotify.MarketFromToken))
 		if err != nil {
 			return errMsg{Err: err}
 		}
@@ -934,3 +2289,13 @@ func playTrackCmd(c *spotify.Client, uri spotify.URI) tea.Cmd {
 		return statusMsg("Playing selected track")
 	}
 }
+
+// queueTrackCmd adds a track after the current one without interrupting it.
+func queueTrackCmd(c *spotify.Client, track spotify.FullTrack) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.QueueSong(context.Background(), track.ID); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Queued " + track.Name)
+	}
+}
@@ -0,0 +1,123 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// border frames every screen; screen_reader mode keeps the spacing but
+// draws no box characters for the reader to spell out.
+func (m RootModel) border() lipgloss.Border {
+	if m.settings.ScreenReader {
+		return lipgloss.HiddenBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// renderPlainMain is the player view for screen_reader mode: one labelled
+// fact per line, always in the same order, without icons or bars.
+func (m RootModel) renderPlainMain() string {
+	track, artist := "Nothing playing", "-"
+	if m.playingType == "ad" {
+		track = "Advertisement"
+	} else if m.trackName != "" {
+		track, artist = m.trackName, m.artistName
+		if m.trackExplicit {
+			track += " (explicit)"
+		}
+	}
+	genres := strings.Join(m.currentGenres(), ", ")
+	if genres == "" {
+		genres = "-"
+	}
+	from := m.contextName
+	if from == "" {
+		from = "-"
+	}
+
+	state := "Paused"
+	if m.isPlaying {
+		state = "Playing"
+	}
+	if m.readOnly {
+		state += " (read-only, playback needs Spotify Premium)"
+	}
+
+	volume := fmt.Sprintf("%d%%", m.volume)
+	switch {
+	case m.hasInitialState && !m.device.SupportsVolume:
+		volume = "not available on this device"
+	case m.muted:
+		volume = "muted"
+	}
+
+	shuffle := "off"
+	if m.shuffle {
+		shuffle = "on"
+	}
+	repeat := map[string]string{"context": "all", "track": "one"}[m.repeat]
+	if repeat == "" {
+		repeat = "off"
+	}
+
+	device := "-"
+	if m.device.Name != "" {
+		device = m.device.Name + " (" + m.device.Type + ")"
+	}
+	if m.lostDevice != "" {
+		device = m.lostDevice + " disconnected, press d to pick a device"
+	}
+
+	status := m.status
+	if m.chord != "" {
+		status = m.renderChordHint()
+	}
+
+	lines := []string{
+		"Spotirice v" + m.version,
+		"Track: " + track,
+		"Artist: " + artist,
+		"Genres: " + genres,
+		"From: " + from,
+		"State: " + state,
+		"Time: " + formatTime(m.progressMs) + " of " + formatTime(m.durationMs),
+		"Volume: " + volume,
+		"Shuffle: " + shuffle + ", repeat: " + repeat,
+		"Device: " + device,
+		"Status: " + status,
+		"Press ? for help",
+	}
+	if m.tour.visible {
+		lines = append(lines, m.plainTourLines()...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// announce prints changes between two player states above the view so a
+// screen reader reads them once, rather than re-reading the whole screen.
+// Outside screen_reader mode it does nothing.
+func (m RootModel) announce(prev RootModel) tea.Cmd {
+	if !m.settings.ScreenReader || !prev.hasInitialState {
+		return nil
+	}
+	var lines []string
+	if m.trackName != prev.trackName && m.trackName != "" {
+		lines = append(lines, "Now playing: "+m.trackName+" by "+m.artistName)
+	} else if m.isPlaying != prev.isPlaying {
+		if m.isPlaying {
+			lines = append(lines, "Playing")
+		} else {
+			lines = append(lines, "Paused")
+		}
+	}
+	if m.device.Name != prev.device.Name && m.device.Name != "" {
+		lines = append(lines, "Device: "+m.device.Name)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return tea.Println(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,61 @@
+package root
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// screensaverDue reports whether the player has been idle long enough for
+// the screensaver. It never starts in screen_reader mode.
+func (m RootModel) screensaverDue() bool {
+	after := m.settings.ScreensaverMinutes
+	return after > 0 && !m.screensaver && !m.settings.ScreenReader &&
+		time.Since(m.lastInput) >= time.Duration(after)*time.Minute
+}
+
+// marquee returns a width-cell window of s, scrolled by offset when s
+// doesn't fit.
+func marquee(s string, width, offset int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	r := []rune(s + "   •   ")
+	offset %= len(r)
+	// Cut without an ellipsis; the text continues as it scrolls
+	return ansi.Truncate(string(r[offset:])+string(r[:offset]), width, "")
+}
+
+// renderScreensaver is the full-screen ambient view: the cover art as large
+// as the screen allows, over a large clock with the playing track scrolling
+// beneath it.
+func (m RootModel) renderScreensaver() string {
+	clockStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Header))
+	trackStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.TrackPlaying)).Bold(true)
+	artistStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Artist))
+
+	clock := clockStyle.Render(bigText(time.Now().Format("15:04")))
+
+	track, artist := "Nothing playing", ""
+	if m.trackName != "" {
+		track, artist = m.trackName, m.artistName
+	}
+	offset := m.marqueeOffset
+	if m.settings.ReducedMotion {
+		offset = 0
+	}
+	track = marquee(track, m.width-4, offset)
+	artist = truncate(artist, m.width-4)
+
+	body := lipgloss.JoinVertical(lipgloss.Center,
+		clock,
+		"",
+		trackStyle.Render(track),
+		artistStyle.Render(artist),
+	)
+	if art := m.art.render(m.art.img, m.art.cache, m.height-lipgloss.Height(body)-3, m.width-4); art != "" {
+		body = lipgloss.JoinVertical(lipgloss.Center, art, "", body)
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, body)
+}
@@ -0,0 +1,58 @@
+package root
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/metolius25/spotirice/internal/scripts"
+)
+
+// scriptDoneMsg carries the commands a script_keys script printed.
+type scriptDoneMsg struct {
+	Run  string
+	Cmds []scripts.Command
+	Err  error
+}
+
+// scriptState describes the current playback to a script.
+func (m RootModel) scriptState(event string) scripts.State {
+	return scripts.State{
+		Event:      event,
+		ID:         string(m.currentTrackID),
+		Track:      m.trackName,
+		Artist:     m.artistName,
+		Liked:      m.trackIsLiked,
+		Playing:    m.isPlaying,
+		ProgressMs: m.progressMs,
+		DurationMs: m.durationMs,
+		Device:     m.device.Name,
+		Volume:     m.volume,
+	}
+}
+
+// runScriptCmd runs the script bound to key with the current playback state.
+func (m RootModel) runScriptCmd(key, run string) tea.Cmd {
+	st := m.scriptState("key")
+	st.Key = key
+	return func() tea.Msg {
+		cmds, err := scripts.Run(context.Background(), run, st)
+		return scriptDoneMsg{Run: run, Cmds: cmds, Err: err}
+	}
+}
+
+// handleScriptDone carries out a script's commands in order, like lines
+// from the control pipe.
+func (m RootModel) handleScriptDone(msg scriptDoneMsg) (RootModel, tea.Cmd) {
+	if msg.Err != nil {
+		m.status = "Script " + msg.Err.Error()
+		return m, clearStatusCmd()
+	}
+	var cmds []tea.Cmd
+	for _, c := range msg.Cmds {
+		var cmd tea.Cmd
+		m, cmd = m.handleControl(ControlMsg{Cmd: c.Cmd, Args: c.Args})
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Sequence(cmds...)
+}
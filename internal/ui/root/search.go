@@ -0,0 +1,291 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/keyboard"
+)
+
+// searchTab is the search tab: a query, the field filter form and the
+// tracks found.
+type searchTab struct {
+	env     tabEnv
+	input   textinput.Model
+	filters []textinput.Model // artist/album/year/genre/isrc form
+	focus   int               // 0 = query, 1.. = filter inputs
+	results []spotify.FullTrack
+	cursor  int
+	inList  bool                // ↑/↓ gave the keys to the results, where a queues
+	picked  map[spotify.ID]bool // multi-selected results
+}
+
+// newSearchTab starts a search with a fresh query and filter form.
+func newSearchTab() searchTab {
+	s := searchTab{
+		input:   textinput.New(),
+		filters: newSearchFilterInputs(),
+		picked:  make(map[spotify.ID]bool),
+	}
+	s.input.Placeholder = "Search for songs..."
+	s.input.Focus()
+	return s
+}
+
+func (s searchTab) withEnv(env tabEnv) tabModel {
+	s.env = env
+	return s
+}
+
+func (s searchTab) Init() tea.Cmd {
+	return s.input.Cursor.BlinkCmd()
+}
+
+func (s searchTab) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case searchResultsMsg:
+		s.results = msg.Tracks
+		s.cursor = 0
+
+	case likeBatchMsg:
+		clear(s.picked)
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			if s.cursor > 0 {
+				s.cursor--
+			}
+		case tea.MouseButtonWheelDown:
+			if s.cursor < len(s.results)-1 {
+				s.cursor++
+			}
+		}
+
+	case keyboard.KeyMsg:
+		if msg.String() == "shift+enter" {
+			return s, s.queueSelected()
+		}
+		return s.updateKey(msg.Legacy)
+
+	case tea.KeyMsg:
+		return s.updateKey(msg)
+	}
+	return s, nil
+}
+
+// queueSelected queues the selected result. The search stays open to queue
+// more.
+func (s searchTab) queueSelected() tea.Cmd {
+	if s.cursor < len(s.results) {
+		return request(queueTrackMsg{Track: s.results[s.cursor]})
+	}
+	return nil
+}
+
+func (s searchTab) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return s, request(closeTabMsg{})
+	case "enter":
+		if s.cursor < len(s.results) {
+			// Play the selected track
+			return s, request(playTracksMsg{Tracks: []spotify.FullTrack{s.results[s.cursor]}})
+		} else if q := s.query(); q != "" {
+			return s, searchCmd(s.env.client, q)
+		}
+	case "alt+enter":
+		// For terminals without the kitty protocol, where Shift+Enter is
+		// plain Enter
+		return s, s.queueSelected()
+	case "ctrl+a":
+		if s.cursor < len(s.results) {
+			return s, request(addTrackToPlaylistMsg{Track: s.results[s.cursor]})
+		}
+	case "ctrl+x":
+		if s.cursor < len(s.results) {
+			id := s.results[s.cursor].ID
+			if s.picked[id] {
+				delete(s.picked, id)
+			} else if id != "" {
+				s.picked[id] = true
+			}
+		}
+	case "ctrl+l", "ctrl+r":
+		return s, request(likeTracksMsg{Tracks: s.results, Selected: s.picked, Add: msg.String() == "ctrl+l"})
+	case "tab":
+		s.inList = false
+		s.focusInput(s.focus + 1)
+	case "shift+tab":
+		s.inList = false
+		s.focusInput(s.focus - 1)
+	case "up":
+		s.enterList()
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down":
+		s.enterList()
+		if s.cursor < len(s.results)-1 {
+			s.cursor++
+		}
+	default:
+		if s.inList {
+			if msg.String() == "a" {
+				return s, s.queueSelected()
+			}
+			// Anything else is typing again
+			s.inList = false
+			s.focusInput(s.focus)
+		}
+		// Pass input to the focused textinput
+		var cmd tea.Cmd
+		if s.focus > 0 {
+			i := s.focus - 1
+			s.filters[i], cmd = s.filters[i].Update(msg)
+		} else {
+			s.input, cmd = s.input.Update(msg)
+		}
+		return s, cmd
+	}
+	return s, nil
+}
+
+// enterList gives the keys to the results, if there are any.
+func (s *searchTab) enterList() {
+	if len(s.results) == 0 {
+		return
+	}
+	s.inList = true
+	s.input.Blur()
+	for i := range s.filters {
+		s.filters[i].Blur()
+	}
+}
+
+func (s searchTab) View() string {
+	colors := s.env.colors
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(s.env.border).
+		BorderForeground(lipgloss.Color(colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Status)).
+		Faint(true)
+
+	header := withTabBar(colors, tabSearch, headerStyle.Render(" 🔍 Search"))
+	inputLine := "Search: " + s.input.View()
+
+	var resultLines []string
+	resultLines = append(resultLines, inputLine)
+	if s.showFilters() {
+		for i, field := range searchFields {
+			label := fmt.Sprintf("  %-7s ", field+":")
+			if s.focus == i+1 {
+				label = selectedStyle.Render(label)
+			}
+			resultLines = append(resultLines, label+s.filters[i].View())
+		}
+	}
+	resultLines = append(resultLines, "")
+
+	if len(s.results) == 0 {
+		if s.query() != "" {
+			resultLines = append(resultLines, "Press Enter to search...")
+		} else {
+			resultLines = append(resultLines, "Type to search for songs, then press Enter")
+			resultLines = append(resultLines, "Tab for filters, or type artist: album: year: genre: isrc:")
+		}
+	} else {
+		// Scrollable results - calculate max visible based on terminal height
+		// Reserve lines for: header(1) + border(2) + padding(2) + search input(1) + blank(1) + results header(1) + blank(1) + footer(2)
+		reservedLines := 11
+		if s.showFilters() {
+			reservedLines += len(searchFields)
+		}
+		maxVisible := s.env.height - reservedLines
+		if maxVisible < 3 {
+			maxVisible = 3 // Minimum 3 results
+		}
+		if maxVisible > len(s.results) {
+			maxVisible = len(s.results)
+		}
+
+		start := 0
+		if s.cursor >= maxVisible {
+			start = s.cursor - maxVisible + 1
+		}
+		end := start + maxVisible
+		if end > len(s.results) {
+			end = len(s.results)
+		}
+
+		resultLines = append(resultLines, fmt.Sprintf("Results %d-%d of %d (↑/↓ to scroll, Enter to play):", start+1, end, len(s.results)), "")
+
+		if start > 0 {
+			resultLines = append(resultLines, normalStyle.Render("  ↑ more results above"))
+		}
+
+		for i := start; i < end; i++ {
+			track := s.results[i]
+			artist := ""
+			if len(track.Artists) > 0 {
+				artist = track.Artists[0].Name
+			}
+			line := fmt.Sprintf("  %s%s%s - %s%s", selectionMark(s.picked, track.ID), track.Name, explicitMark(track), artist, s.env.likedMark(track.ID))
+			unplayable := unplayableReason(track) != ""
+			if unplayable {
+				line += " (unavailable)"
+			}
+			line = s.env.fitLine(line)
+			if i == s.cursor {
+				line = selectedStyle.Render("▶ " + line[2:])
+			} else if unplayable {
+				line = dimStyle.Render(line)
+			} else {
+				line = normalStyle.Render(line)
+			}
+			resultLines = append(resultLines, line)
+		}
+
+		if end < len(s.results) {
+			resultLines = append(resultLines, normalStyle.Render("  ↓ more results below"))
+		}
+	}
+
+	resultLines = append(resultLines, "", "Shift+Enter or ↓ then a queue  •  Ctrl+X select  •  Ctrl+L like selected  •  Ctrl+R unlike selected  •  ESC cancel")
+
+	content := strings.Join(resultLines, "\n")
+
+	// Make container fill terminal width and height
+	w := s.env.width - containerStyle.GetHorizontalBorderSize()
+	h := s.env.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	searchBox := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		searchBox,
+	)
+}
@@ -0,0 +1,135 @@
+package root
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// searchFields are the Spotify search field filters, in form order.
+var searchFields = []string{"artist", "album", "year", "genre", "isrc"}
+
+// newSearchFilterInputs creates one text input per search field filter.
+func newSearchFilterInputs() []textinput.Model {
+	inputs := make([]textinput.Model, len(searchFields))
+	for i, field := range searchFields {
+		ti := textinput.New()
+		ti.Prompt = ""
+		switch field {
+		case "year":
+			ti.Placeholder = "1999 or 1990-1999"
+		default:
+			ti.Placeholder = "any"
+		}
+		inputs[i] = ti
+	}
+	return inputs
+}
+
+// parseSearchQuery splits typed text into free terms and field filters.
+// A filter value runs until the next recognised "field:" token, so
+// `daft artist:daft punk year:2001` reads as artist "daft punk" and year 2001.
+func parseSearchQuery(text string) (string, map[string]string) {
+	filters := make(map[string]string)
+	var free []string
+	current := ""
+	var value []string
+
+	flush := func() {
+		if current != "" {
+			filters[current] = strings.Trim(strings.Join(value, " "), `"`)
+		}
+		value = nil
+	}
+
+	for _, word := range strings.Fields(text) {
+		if key, rest, ok := strings.Cut(word, ":"); ok && isSearchField(strings.ToLower(key)) {
+			flush()
+			current = strings.ToLower(key)
+			if rest != "" {
+				value = append(value, rest)
+			}
+			continue
+		}
+		if current == "" {
+			free = append(free, word)
+		} else {
+			value = append(value, word)
+		}
+	}
+	flush()
+
+	return strings.Join(free, " "), filters
+}
+
+func isSearchField(key string) bool {
+	for _, f := range searchFields {
+		if f == key {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSearchQuery renders free text and filters in Spotify's query syntax,
+// quoting multi-word values.
+func buildSearchQuery(free string, filters map[string]string) string {
+	parts := []string{}
+	if free = strings.TrimSpace(free); free != "" {
+		parts = append(parts, free)
+	}
+	for _, field := range searchFields {
+		v := strings.TrimSpace(filters[field])
+		if v == "" {
+			continue
+		}
+		if strings.ContainsAny(v, " \t") {
+			v = `"` + v + `"`
+		}
+		parts = append(parts, field+":"+v)
+	}
+	return strings.Join(parts, " ")
+}
+
+// query combines the typed query with the filter form. Form values win
+// over filters typed inline for the same field.
+func (s searchTab) query() string {
+	free, filters := parseSearchQuery(s.input.Value())
+	for i, field := range searchFields {
+		if i < len(s.filters) {
+			if v := strings.TrimSpace(s.filters[i].Value()); v != "" {
+				filters[field] = v
+			}
+		}
+	}
+	return buildSearchQuery(free, filters)
+}
+
+// focusInput moves keyboard focus between the query (0) and filter inputs
+// (1..n).
+func (s *searchTab) focusInput(idx int) {
+	n := len(s.filters) + 1
+	s.focus = ((idx % n) + n) % n
+	s.input.Blur()
+	for i := range s.filters {
+		s.filters[i].Blur()
+	}
+	if s.focus == 0 {
+		s.input.Focus()
+	} else {
+		s.filters[s.focus-1].Focus()
+	}
+}
+
+// showFilters reports whether the filter form should be drawn.
+func (s searchTab) showFilters() bool {
+	if s.focus > 0 {
+		return true
+	}
+	for _, f := range s.filters {
+		if f.Value() != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package root
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// Session captures the UI state to restore at the next start.
+func (m RootModel) Session() config.Session {
+	s := config.Session{
+		View:           "main",
+		EpisodesCursor: m.episodesCursor,
+		Volume:         m.volume,
+		DeviceID:       string(m.device.ID),
+		ShowRemaining:  m.showRemaining,
+		SleepAt:        m.sleepAt,
+		SleepFor:       m.sleepFor,
+	}
+	// Same precedence as View
+	switch {
+	case m.showEpisodes:
+		s.View = "episodes"
+	case m.devices.visible:
+		s.View = "devices"
+	case m.trackList.visible:
+		s.View = "tracklist"
+		s.ContextURI = string(m.trackList.contextURI)
+		s.TrackCursor = m.trackList.cursor
+	}
+	return s
+}
+
+// restoreSession reopens the view saved in m.session. Scroll positions are
+// applied once the view's contents arrive.
+func (m RootModel) restoreSession() (RootModel, tea.Cmd) {
+	s := m.session
+	if s == nil || m.client == nil {
+		return m, nil
+	}
+	timer := m.restoreSleepTimer(s.SleepAt, s.SleepFor)
+	switch s.View {
+	case "tracklist":
+		if s.ContextURI != "" {
+			return m, tea.Batch(timer, fetchContextTracksCmd(m.client, spotify.URI(s.ContextURI), ""))
+		}
+	case "episodes":
+		m, cmd := m.openEpisodes()
+		return m, tea.Batch(timer, cmd)
+	case "devices":
+		m, cmd := m.openDevices()
+		return m, tea.Batch(timer, cmd)
+	}
+	return m, timer
+}
+
+// restoredCursor returns the saved cursor clamped to a list of n items.
+func restoredCursor(saved, n int) int {
+	if saved >= n {
+		saved = n - 1
+	}
+	if saved < 0 {
+		saved = 0
+	}
+	return saved
+}
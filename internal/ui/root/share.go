@@ -0,0 +1,133 @@
+package root
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/platform"
+	"github.com/metolius25/spotirice/internal/qr"
+)
+
+// shareView is the share overlay: a QR code of the playing item's link.
+type shareView struct {
+	visible bool
+	title   string
+	url     string
+	code    []string // QR code lines, nil if the link didn't fit
+}
+
+// shareURL turns spotify:track:ID (or episode, album, ...) into its
+// open.spotify.com link.
+func shareURL(uri spotify.URI) string {
+	parts := strings.Split(string(uri), ":")
+	if len(parts) != 3 || parts[0] != "spotify" {
+		return ""
+	}
+	return "https://open.spotify.com/" + parts[1] + "/" + parts[2]
+}
+
+func (m RootModel) openShare() (RootModel, tea.Cmd) {
+	url := shareURL(m.currentTrackURI)
+	if url == "" {
+		m.status = "Nothing shareable is playing."
+		return m, clearStatusCmd()
+	}
+	m.share = shareView{visible: true, title: m.trackName, url: url}
+	if code, err := qr.Encode(url); err == nil {
+		m.share.code = code.HalfBlocks(2)
+	}
+	return m, nil
+}
+
+func copyLinkCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		if err := platform.CopyToClipboard(url); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Link copied.")
+	}
+}
+
+func (m RootModel) updateShare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "Q":
+		m.share.visible = false
+	case "y":
+		return m, copyLinkCmd(m.share.url)
+	case "o":
+		if platform.Remote() {
+			m.status = "Not opening a browser over SSH; scan the code or copy the link."
+			return m, clearStatusCmd()
+		}
+		if err := platform.OpenURL(m.share.url); err != nil {
+			m.status = "Couldn't open link: " + err.Error()
+			return m, clearStatusCmd()
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderShareScreen() string {
+	v := m.share
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	// Scanners want dark modules on a light background, whatever the
+	// terminal's own colors
+	codeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color("#ffffff"))
+
+	trackStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	var lines []string
+	// Reserve lines for: header(1) + border(2) + padding(2) + text(5)
+	fits := len(v.code) <= m.height-10 && (len(v.code) == 0 || lipgloss.Width(v.code[0]) <= m.width-6)
+	switch {
+	case m.settings.ScreenReader:
+	case v.code == nil:
+		lines = append(lines, dimStyle.Render("The link is too long for a QR code."), "")
+	case !fits:
+		lines = append(lines, dimStyle.Render("Enlarge the terminal to show the QR code."), "")
+	default:
+		for _, l := range v.code {
+			lines = append(lines, codeStyle.Render(l))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines,
+		trackStyle.Render(m.fitLine(v.title)),
+		m.fitLine(v.url),
+		"",
+		"y copy link  •  o open in browser  •  ESC close",
+	)
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 📲 Share"),
+		box,
+	)
+}
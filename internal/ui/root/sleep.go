@@ -0,0 +1,79 @@
+package root
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sleepSteps are the sleep timer durations the z key cycles through,
+// before turning it off again.
+var sleepSteps = []time.Duration{
+	15 * time.Minute, 30 * time.Minute, 45 * time.Minute, 60 * time.Minute, 90 * time.Minute,
+}
+
+// sleepTimerMsg fires when the sleep timer set for At runs out.
+type sleepTimerMsg struct{ At time.Time }
+
+// cycleSleepTimer moves the sleep timer to the next step, or off after the
+// last one.
+func (m RootModel) cycleSleepTimer() (RootModel, tea.Cmd) {
+	next := sleepSteps[0]
+	if !m.sleepAt.IsZero() {
+		next = 0
+		for i, d := range sleepSteps[:len(sleepSteps)-1] {
+			if d == m.sleepFor {
+				next = sleepSteps[i+1]
+			}
+		}
+	}
+	if next == 0 {
+		m.sleepAt = time.Time{}
+		m.sleepFor = 0
+		m.status = "Sleep timer off."
+		return m, clearStatusCmd()
+	}
+	m.sleepFor = next
+	m.sleepAt = time.Now().Add(next)
+	at := m.sleepAt
+	m.status = fmt.Sprintf("Pausing in %d minutes.", int(next.Minutes()))
+	return m, tea.Batch(clearStatusCmd(), sleepTimerCmd(at))
+}
+
+func sleepTimerCmd(at time.Time) tea.Cmd {
+	return tea.Tick(time.Until(at), func(time.Time) tea.Msg { return sleepTimerMsg{At: at} })
+}
+
+// restoreSleepTimer picks up a sleep timer saved in the session, unless it
+// ran out while Spotirice was closed.
+func (m *RootModel) restoreSleepTimer(at time.Time, step time.Duration) tea.Cmd {
+	if at.IsZero() || !at.After(time.Now()) {
+		return nil
+	}
+	m.sleepAt = at
+	m.sleepFor = step
+	return sleepTimerCmd(at)
+}
+
+// handleSleepTimer pauses when the current timer (not one replaced since)
+// runs out.
+func (m RootModel) handleSleepTimer(msg sleepTimerMsg) (RootModel, tea.Cmd) {
+	if !msg.At.Equal(m.sleepAt) {
+		return m, nil
+	}
+	m.sleepAt = time.Time{}
+	m.sleepFor = 0
+	if m.client == nil || !m.isPlaying {
+		return m, nil
+	}
+	m.burstTicksRemaining = 10
+	return m, m.fadePauseCmd()
+}
+
+// sleepRemaining formats the time left on the sleep timer, rounded up to
+// the minute.
+func (m RootModel) sleepRemaining() string {
+	left := time.Until(m.sleepAt)
+	return fmt.Sprintf("%dm", int((left+time.Minute-1)/time.Minute))
+}
@@ -0,0 +1,381 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Smart playlist form fields, in display order.
+const (
+	smartName = iota
+	smartArtists
+	smartTracks
+	smartGenres
+	smartTempo
+	smartEnergy
+	smartValence
+	smartYears
+	smartFieldCount
+)
+
+var smartFieldLabels = [smartFieldCount]string{
+	"Name", "Seed artists", "Seed tracks", "Seed genres", "Tempo (BPM)", "Energy", "Valence", "Years",
+}
+
+var smartFieldPlaceholders = [smartFieldCount]string{
+	"Smart playlist",
+	"comma separated names",
+	`comma separated names, or "current"`,
+	"e.g. house, techno",
+	"e.g. 118-128",
+	"0.0-1.0, e.g. 0.6-0.9",
+	"0.0-1.0, e.g. 0.5-1",
+	"e.g. 1990-1999",
+}
+
+// smartPlaylistView is the rules form plus the preview of the generated list.
+type smartPlaylistView struct {
+	visible  bool
+	inputs   []textinput.Model
+	focus    int
+	building bool
+	preview  []spotify.FullTrack
+	cursor   int
+}
+
+// smartRules are the parsed form values.
+type smartRules struct {
+	name     string
+	artists  []string
+	tracks   []string
+	genres   []string
+	attrs    *spotify.TrackAttributes
+	yearFrom int
+	yearTo   int
+}
+
+type smartPreviewMsg struct {
+	Tracks []spotify.FullTrack
+}
+
+type smartSavedMsg struct {
+	Name  string
+	Count int
+}
+
+// parseRange reads "a-b" or a single value "a" (meaning exactly a).
+func parseRange(s string) (float64, float64, bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, 0, false, nil
+	}
+	lo, hi, found := strings.Cut(s, "-")
+	min, err := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid range %q", s)
+	}
+	if !found {
+		return min, min, true, nil
+	}
+	max, err := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+	if err != nil || max < min {
+		return 0, 0, false, fmt.Errorf("invalid range %q", s)
+	}
+	return min, max, true, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseSmartRules validates the form and turns it into recommendation rules.
+func parseSmartRules(inputs []textinput.Model) (smartRules, error) {
+	r := smartRules{
+		name:    strings.TrimSpace(inputs[smartName].Value()),
+		artists: splitList(inputs[smartArtists].Value()),
+		tracks:  splitList(inputs[smartTracks].Value()),
+		genres:  splitList(inputs[smartGenres].Value()),
+		attrs:   spotify.NewTrackAttributes(),
+	}
+	if r.name == "" {
+		r.name = smartFieldPlaceholders[smartName]
+	}
+	if n := len(r.artists) + len(r.tracks) + len(r.genres); n == 0 {
+		return r, fmt.Errorf("add at least one seed artist, track or genre")
+	} else if n > spotify.MaxNumberOfSeeds {
+		return r, fmt.Errorf("at most %d seeds are allowed", spotify.MaxNumberOfSeeds)
+	}
+
+	if min, max, ok, err := parseRange(inputs[smartTempo].Value()); err != nil {
+		return r, err
+	} else if ok {
+		r.attrs.MinTempo(min).MaxTempo(max)
+	}
+	if min, max, ok, err := parseRange(inputs[smartEnergy].Value()); err != nil {
+		return r, err
+	} else if ok {
+		r.attrs.MinEnergy(min).MaxEnergy(max)
+	}
+	if min, max, ok, err := parseRange(inputs[smartValence].Value()); err != nil {
+		return r, err
+	} else if ok {
+		r.attrs.MinValence(min).MaxValence(max)
+	}
+	if min, max, ok, err := parseRange(inputs[smartYears].Value()); err != nil {
+		return r, err
+	} else if ok {
+		r.yearFrom, r.yearTo = int(min), int(max)
+	}
+	return r, nil
+}
+
+// buildSmartPlaylistCmd resolves the seeds, asks for recommendations and
+// applies the year filter, which the recommendations endpoint lacks.
+func buildSmartPlaylistCmd(c *spotify.Client, r smartRules, currentTrack spotify.ID) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var seeds spotify.Seeds
+		seeds.Genres = r.genres
+
+		for _, name := range r.artists {
+			res, err := c.Search(ctx, name, spotify.SearchTypeArtist, spotify.Limit(1))
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			if res.Artists == nil || len(res.Artists.Artists) == 0 {
+				return errMsg{Err: fmt.Errorf("no artist found for %q", name)}
+			}
+			seeds.Artists = append(seeds.Artists, res.Artists.Artists[0].ID)
+		}
+		for _, name := range r.tracks {
+			if strings.EqualFold(name, "current") && currentTrack != "" {
+				seeds.Tracks = append(seeds.Tracks, currentTrack)
+				continue
+			}
+			res, err := c.Search(ctx, name, spotify.SearchTypeTrack, spotify.Limit(1))
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			if res.Tracks == nil || len(res.Tracks.Tracks) == 0 {
+				return errMsg{Err: fmt.Errorf("no track found for %q", name)}
+			}
+			seeds.Tracks = append(seeds.Tracks, res.Tracks.Tracks[0].ID)
+		}
+
+		recs, err := c.GetRecommendations(ctx, seeds, r.attrs, spotify.Limit(100), spotify.Country(spotify.MarketFromToken))
+		if err != nil {
+			return errMsg{Err: err}
+		}
+
+		// Recommendations are simple tracks; fetch full tracks for album years.
+		ids := make([]spotify.ID, 0, len(recs.Tracks))
+		for _, t := range recs.Tracks {
+			ids = append(ids, t.ID)
+		}
+		var tracks []spotify.FullTrack
+		for start := 0; start < len(ids); start += 50 {
+			end := start + 50
+			if end > len(ids) {
+				end = len(ids)
+			}
+			full, err := c.GetTracks(ctx, ids[start:end])
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			for _, t := range full {
+				if t == nil {
+					continue
+				}
+				year := t.Album.ReleaseDateTime().Year()
+				if r.yearFrom > 0 && (year < r.yearFrom || year > r.yearTo) {
+					continue
+				}
+				tracks = append(tracks, *t)
+			}
+		}
+		return smartPreviewMsg{Tracks: tracks}
+	}
+}
+
+// saveSmartPlaylistCmd creates a private playlist with the previewed tracks.
+func saveSmartPlaylistCmd(c *spotify.Client, name string, tracks []spotify.FullTrack) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		user, err := c.CurrentUser(ctx)
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		pl, err := c.CreatePlaylistForUser(ctx, user.ID, name, "Built with Spotirice", false, false)
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		ids := make([]spotify.ID, 0, len(tracks))
+		for _, t := range tracks {
+			ids = append(ids, t.ID)
+		}
+		// The API accepts at most 100 tracks per request
+		for start := 0; start < len(ids); start += 100 {
+			end := start + 100
+			if end > len(ids) {
+				end = len(ids)
+			}
+			if _, err := c.AddTracksToPlaylist(ctx, pl.ID, ids[start:end]...); err != nil {
+				return errMsg{Err: err}
+			}
+		}
+		return smartSavedMsg{Name: name, Count: len(ids)}
+	}
+}
+
+func (m RootModel) openSmartPlaylist() (RootModel, tea.Cmd) {
+	v := smartPlaylistView{visible: true}
+	v.inputs = make([]textinput.Model, smartFieldCount)
+	for i := range v.inputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Placeholder = smartFieldPlaceholders[i]
+		v.inputs[i] = ti
+	}
+	v.inputs[0].Focus()
+	m.smartPlaylist = v
+	return m, v.inputs[0].Cursor.BlinkCmd()
+}
+
+func (m RootModel) updateSmartPlaylist(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.smartPlaylist
+
+	// Preview of generated tracks
+	if v.preview != nil {
+		switch msg.String() {
+		case "esc":
+			v.visible = false
+		case "b":
+			v.preview = nil
+		case "up":
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case "down":
+			if v.cursor < len(v.preview)-1 {
+				v.cursor++
+			}
+		case "s", "enter":
+			r, _ := parseSmartRules(v.inputs)
+			v.visible = false
+			return m, saveSmartPlaylistCmd(m.client, r.name, v.preview)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		v.visible = false
+		return m, nil
+	case "tab", "down", "shift+tab", "up":
+		v.inputs[v.focus].Blur()
+		if msg.String() == "tab" || msg.String() == "down" {
+			v.focus = (v.focus + 1) % smartFieldCount
+		} else {
+			v.focus = (v.focus + smartFieldCount - 1) % smartFieldCount
+		}
+		v.inputs[v.focus].Focus()
+		return m, nil
+	case "enter":
+		if v.building {
+			return m, nil
+		}
+		r, err := parseSmartRules(v.inputs)
+		if err != nil {
+			m.status = "Error: " + err.Error()
+			return m, clearStatusCmd()
+		}
+		v.building = true
+		return m, buildSmartPlaylistCmd(m.client, r, m.currentTrackID)
+	}
+
+	var cmd tea.Cmd
+	v.inputs[v.focus], cmd = v.inputs[v.focus].Update(msg)
+	return m, cmd
+}
+
+func (m RootModel) renderSmartPlaylistScreen() string {
+	v := m.smartPlaylist
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	var lines []string
+	if v.preview != nil {
+		lines = append(lines, fmt.Sprintf("%d tracks:", len(v.preview)), "")
+		// Reserve lines for: header(1) + border(2) + padding(2) + title(2) + footer(2)
+		maxVisible := m.height - 9
+		if maxVisible < 3 {
+			maxVisible = 3
+		}
+		start, end := visibleRange(v.cursor, len(v.preview), maxVisible)
+		for i := start; i < end; i++ {
+			t := v.preview[i]
+			line := m.fitLine(fmt.Sprintf("  %s%s - %s (%d)", t.Name, explicitMark(t), trackArtist(t), t.Album.ReleaseDateTime().Year()))
+			if i == v.cursor {
+				line = selectedStyle.Render("▶ " + line[2:])
+			} else {
+				line = normalStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "", "s save playlist  •  b back to rules  •  ESC cancel")
+	} else {
+		for i, in := range v.inputs {
+			label := fmt.Sprintf("%-14s", smartFieldLabels[i]+":")
+			if i == v.focus {
+				label = selectedStyle.Render(label)
+			}
+			lines = append(lines, label+in.View())
+		}
+		footer := "Tab/↑/↓ move  •  Enter preview  •  ESC cancel"
+		if v.building {
+			footer = "Building preview..."
+		}
+		lines = append(lines, "", footer)
+	}
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" ✨ Smart playlist"),
+		box,
+	)
+}
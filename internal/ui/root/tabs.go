@@ -0,0 +1,266 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// tab is one of the top-level screens switched between with Tab and the
+// number keys.
+type tab int
+
+const (
+	tabPlayer tab = iota
+	tabQueue
+	tabLibrary
+	tabSearch
+	tabCount
+)
+
+var tabNames = [tabCount]string{"Player", "Queue", "Library", "Search"}
+
+// tabsModel holds the tabs: which one is showing, and the screens of the
+// queue, library and search tabs. Those are tea.Models of their own that
+// keep their state to themselves; the player tab is RootModel's own view,
+// as the playback state it draws is what everything else hangs off.
+type tabsModel struct {
+	active  tab
+	queue   queueTab
+	library libraryTab
+	search  searchTab
+}
+
+// tabModel is the screen of a tab. What it needs from the player around it
+// is handed over with withEnv before each Update and View; what it wants
+// done outside itself, like playing a track, it asks for with a message
+// (see handleTabRequest).
+type tabModel interface {
+	tea.Model
+	withEnv(tabEnv) tabModel
+}
+
+// child returns the screen of tab t, nil for the player.
+func (t tabsModel) child(id tab) tabModel {
+	switch id {
+	case tabQueue:
+		return t.queue
+	case tabLibrary:
+		return t.library
+	case tabSearch:
+		return t.search
+	}
+	return nil
+}
+
+// setChild keeps a tab's screen as its Update returned it.
+func (t *tabsModel) setChild(next tea.Model) {
+	switch next := next.(type) {
+	case queueTab:
+		t.queue = next
+	case libraryTab:
+		t.library = next
+	case searchTab:
+		t.search = next
+	}
+}
+
+// tabEnv is what the tabs read from the player: the client, the look, the
+// terminal size and what is playing.
+type tabEnv struct {
+	client  *spotify.Client
+	colors  *config.Colors
+	border  lipgloss.Border
+	width   int
+	height  int
+	playing spotify.URI         // the playing track
+	context spotify.URI         // what it is playing from
+	liked   map[spotify.ID]bool // the like cache, not to be written to
+}
+
+func (m RootModel) tabEnv() tabEnv {
+	return tabEnv{
+		client:  m.client,
+		colors:  m.colors,
+		border:  m.border(),
+		width:   m.width,
+		height:  m.height,
+		playing: m.currentTrackURI,
+		context: m.contextURI,
+		liked:   m.likeCache,
+	}
+}
+
+func (e tabEnv) fitLine(line string) string {
+	return fitToOverlay(line, e.width)
+}
+
+func (e tabEnv) likedMark(id spotify.ID) string {
+	return heartFor(e.liked, id)
+}
+
+// What the tabs ask the player for.
+type (
+	// closeTabMsg goes back to the player tab.
+	closeTabMsg struct{}
+
+	// playTracksMsg plays Tracks[Index] within ContextURI, or followed by
+	// the rest of Tracks without one, and goes back to the player tab.
+	playTracksMsg struct {
+		ContextURI spotify.URI
+		Tracks     []spotify.FullTrack
+		Index      int
+	}
+
+	// playContextMsg plays a whole playlist.
+	playContextMsg struct {
+		URI  spotify.URI
+		Name string
+	}
+
+	// queueTrackMsg adds Track to the playback queue.
+	queueTrackMsg struct{ Track spotify.FullTrack }
+
+	// addTrackToPlaylistMsg opens the add-to-playlist dialog for Track.
+	addTrackToPlaylistMsg struct{ Track spotify.FullTrack }
+
+	// likeTracksMsg likes, or with Add false unlikes, the Selected ones
+	// of Tracks.
+	likeTracksMsg struct {
+		Tracks   []spotify.FullTrack
+		Selected map[spotify.ID]bool
+		Add      bool
+	}
+)
+
+// request returns a command that sends msg, for a tab to ask the player
+// for something.
+func request(msg tea.Msg) tea.Cmd {
+	return func() tea.Msg { return msg }
+}
+
+// handleTabRequest carries out what a tab asked for.
+func (m RootModel) handleTabRequest(msg tea.Msg) (RootModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case closeTabMsg:
+		m.tabs.active = tabPlayer
+	case playTracksMsg:
+		if !m.mayPlay(msg.Tracks[msg.Index]) {
+			return m, clearStatusCmd()
+		}
+		m.tabs.active = tabPlayer
+		m.burstTicksRemaining = 10
+		return m, playInContextCmd(m.client, msg.ContextURI, msg.Tracks, msg.Index)
+	case playContextMsg:
+		if m.readOnly {
+			m.status = premiumRequiredReason
+			return m, clearStatusCmd()
+		}
+		m.burstTicksRemaining = 10
+		return m, playContextCmd(m.client, msg.URI, msg.Name)
+	case queueTrackMsg:
+		if !m.mayPlay(msg.Track) {
+			return m, clearStatusCmd()
+		}
+		return m, queueTrackCmd(m.client, msg.Track)
+	case addTrackToPlaylistMsg:
+		return m.openAddToPlaylist(msg.Track)
+	case likeTracksMsg:
+		return m.startBatchLike(msg.Tracks, msg.Selected, msg.Add)
+	}
+	return m, nil
+}
+
+// mayPlay reports whether t can be played or queued from a list, setting
+// the status to why not when it can't.
+func (m *RootModel) mayPlay(t spotify.FullTrack) bool {
+	if m.readOnly {
+		m.status = premiumRequiredReason
+		return false
+	}
+	if reason := unplayableReason(t); reason != "" {
+		m.status = reason
+		return false
+	}
+	return m.confirmExplicit(t)
+}
+
+// tabForKey returns the tab key switches to from cur: Tab and Shift+Tab
+// cycle, 1 to 4 pick one.
+func tabForKey(key string, cur tab) (tab, bool) {
+	switch key {
+	case "tab":
+		return (cur + 1) % tabCount, true
+	case "shift+tab":
+		return (cur + tabCount - 1) % tabCount, true
+	case "1", "2", "3", "4":
+		return tab(key[0] - '1'), true
+	}
+	return 0, false
+}
+
+// switchTab shows tab t. The queue and the library are fetched afresh each
+// time, and the search starts out empty.
+func (m RootModel) switchTab(t tab) (RootModel, tea.Cmd) {
+	switch t {
+	case tabQueue:
+		return m.openTab(t, queueTab{})
+	case tabLibrary:
+		return m.openTab(t, newLibraryTab(false))
+	case tabSearch:
+		return m.openTab(t, newSearchTab())
+	}
+	return m.openTab(t, nil)
+}
+
+// openTab shows tab t with child as its screen.
+func (m RootModel) openTab(t tab, child tabModel) (RootModel, tea.Cmd) {
+	m.tabs.active = tabPlayer
+	if m.client == nil || child == nil {
+		return m, nil
+	}
+	m.tabs.active = t
+	m.tabs.setChild(child)
+	return m, child.withEnv(m.tabEnv()).Init()
+}
+
+// updateTab hands msg to the screen of the tab that is showing.
+func (m RootModel) updateTab(msg tea.Msg) (RootModel, tea.Cmd) {
+	child := m.tabs.child(m.tabs.active)
+	if child == nil {
+		return m, nil
+	}
+	next, cmd := child.withEnv(m.tabEnv()).Update(msg)
+	m.tabs.setChild(next)
+	return m, cmd
+}
+
+func (m RootModel) renderTabScreen() string {
+	return m.tabs.child(m.tabs.active).withEnv(m.tabEnv()).View()
+}
+
+// withTabBar adds the tab bar, with cur picked out, to the header of a
+// tab's screen.
+func withTabBar(colors *config.Colors, cur tab, header string) string {
+	currentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.TrackPlaying)).
+		Bold(true)
+	otherStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colors.Status))
+
+	parts := make([]string, tabCount)
+	for t, name := range tabNames {
+		label := fmt.Sprintf("%d %s", t+1, name)
+		if tab(t) == cur {
+			parts[t] = currentStyle.Render("[" + label + "]")
+		} else {
+			parts[t] = otherStyle.Render(" " + label + " ")
+		}
+	}
+	return header + "  " + strings.Join(parts, "")
+}
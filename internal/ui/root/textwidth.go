@@ -0,0 +1,38 @@
+package root
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// truncate shortens s to at most width terminal cells, ending in "…" when it
+// had to cut. Width is measured in display cells, so wide (CJK, emoji) and
+// zero-width (combining) characters are counted correctly, and ANSI styling
+// is preserved.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return ansi.Truncate(s, width, "…")
+}
+
+// padRight fits s into exactly width cells, truncating or padding with spaces.
+func padRight(s string, width int) string {
+	s = truncate(s, width)
+	return s + strings.Repeat(" ", width-ansi.StringWidth(s))
+}
+
+// fitLine truncates a list line to the inside of the padded overlay
+// containers: a border and two cells of padding on each side.
+func (m RootModel) fitLine(line string) string {
+	return fitToOverlay(line, m.width)
+}
+
+// fitToOverlay is fitLine for a terminal width cells wide.
+func fitToOverlay(line string, width int) string {
+	if width <= 0 {
+		return line
+	}
+	return truncate(line, width-6)
+}
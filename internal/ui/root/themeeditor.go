@@ -0,0 +1,260 @@
+package root
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// paletteColumns is how many swatches make up a row of the palette.
+const paletteColumns = 14
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// themeEditorView is the theme editor (T): every color of the theme, changed
+// through a palette or a hex code and shown live all over the interface
+// until saved to config.toml or thrown away.
+type themeEditorView struct {
+	visible bool
+	cursor  int             // index into config.ColorKeys
+	saved   config.Colors   // the colors as last saved, to revert to
+	changed map[string]bool // keys edited since opening
+
+	// Exactly one way of picking a color is open at a time, if any
+	palette    bool
+	swatch     int // index into config.Palette()
+	hexEditing bool
+	hex        textinput.Model
+}
+
+func (m RootModel) openThemeEditor() (RootModel, tea.Cmd) {
+	m.themeEditor = themeEditorView{visible: true, saved: *m.colors, changed: make(map[string]bool)}
+	return m, nil
+}
+
+// setThemeColor previews value for the selected key.
+func (m RootModel) setThemeColor(value string) RootModel {
+	key := config.ColorKeys[m.themeEditor.cursor]
+	*m.colors.Color(key) = value
+	m.themeEditor.changed[key] = true
+	return m
+}
+
+func (m RootModel) updateThemeEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.themeEditor
+	palette := config.Palette()
+
+	if v.hexEditing {
+		switch msg.String() {
+		case "esc":
+			v.hexEditing = false
+			return m, nil
+		case "enter":
+			value := strings.TrimSpace(v.hex.Value())
+			if !strings.HasPrefix(value, "#") {
+				value = "#" + value
+			}
+			if !hexColor.MatchString(value) {
+				m.status = "Colors are hex codes like #88c0d0."
+				return m, clearStatusCmd()
+			}
+			v.hexEditing = false
+			return m.setThemeColor(strings.ToLower(value)), nil
+		}
+		var cmd tea.Cmd
+		v.hex, cmd = v.hex.Update(msg)
+		return m, cmd
+	}
+
+	if v.palette {
+		switch msg.String() {
+		case "esc", "p":
+			v.palette = false
+		case "left", "h":
+			v.swatch = max(v.swatch-1, 0)
+		case "right", "l":
+			v.swatch = min(v.swatch+1, len(palette)-1)
+		case "up", "k":
+			v.swatch = max(v.swatch-paletteColumns, 0)
+		case "down", "j":
+			v.swatch = min(v.swatch+paletteColumns, len(palette)-1)
+		case "enter":
+			v.palette = false
+			return m.setThemeColor(palette[v.swatch]), nil
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "T":
+		// Unsaved changes are only a preview
+		*m.colors = v.saved
+		v.visible = false
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(config.ColorKeys)-1 {
+			v.cursor++
+		}
+	case "p":
+		v.palette = true
+		current := strings.ToLower(*m.colors.Color(config.ColorKeys[v.cursor]))
+		for i, c := range palette {
+			if c == current {
+				v.swatch = i
+			}
+		}
+	case "enter", "x":
+		v.hexEditing = true
+		v.hex = textinput.New()
+		v.hex.Prompt = "#"
+		v.hex.CharLimit = 7
+		v.hex.SetValue(strings.TrimPrefix(*m.colors.Color(config.ColorKeys[v.cursor]), "#"))
+		v.hex.Focus()
+		return m, v.hex.Cursor.BlinkCmd()
+	case "r":
+		// Back to the saved color
+		key := config.ColorKeys[v.cursor]
+		*m.colors.Color(key) = *v.saved.Color(key)
+		delete(v.changed, key)
+	case "w", "ctrl+s":
+		if len(v.changed) == 0 {
+			m.status = "No changes to save."
+			return m, clearStatusCmd()
+		}
+		values := make(map[string]string)
+		for key := range v.changed {
+			values[key] = *m.colors.Color(key)
+		}
+		if err := config.SaveColors(values); err != nil {
+			m.status = "Couldn't save theme: " + err.Error()
+			return m, clearStatusCmd()
+		}
+		v.saved = *m.colors
+		v.changed = make(map[string]bool)
+		m.status = "Theme saved to config.toml."
+		return m, clearStatusCmd()
+	}
+	return m, nil
+}
+
+// swatch is a block of the given color, or a placeholder for unset colors.
+func swatch(color string, width int) string {
+	if color == "" {
+		return strings.Repeat("·", width)
+	}
+	return lipgloss.NewStyle().Background(lipgloss.Color(color)).Render(strings.Repeat(" ", width))
+}
+
+func (m RootModel) renderThemeEditorScreen() string {
+	v := m.themeEditor
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	var lines []string
+	for i, key := range config.ColorKeys {
+		value := *m.colors.Color(key)
+		label := fmt.Sprintf("  %-20s", key)
+		if i == v.cursor {
+			label = selectedStyle.Render(fmt.Sprintf("▸ %-20s", key))
+		}
+		shown := value
+		switch {
+		case i == v.cursor && v.hexEditing:
+			shown = v.hex.View()
+		case value == "":
+			shown = dimStyle.Render("unset")
+		case v.changed[key]:
+			shown += dimStyle.Render(" (was " + orUnset(*v.saved.Color(key)) + ")")
+		}
+		lines = append(lines, label+" "+swatch(value, 4)+" "+shown)
+	}
+
+	if v.palette {
+		lines = append(lines, "")
+		palette := config.Palette()
+		for row := 0; row < len(palette); row += paletteColumns {
+			var cells []string
+			for i := row; i < min(row+paletteColumns, len(palette)); i++ {
+				cell := swatch(palette[i], 2)
+				if i == v.swatch {
+					cell = "[" + cell + "]"
+				} else {
+					cell = " " + cell + " "
+				}
+				cells = append(cells, cell)
+			}
+			lines = append(lines, strings.Join(cells, ""))
+		}
+		lines = append(lines, dimStyle.Render(palette[v.swatch]))
+	}
+
+	// A sample of the player in the edited colors
+	lines = append(lines, "",
+		lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.TrackPlaying)).Bold(true).Render("Playing track")+"  "+
+			lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.TrackPaused)).Render("Paused track")+"  "+
+			lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Artist)).Render("Artist"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.ProgressBar)).Render(strings.Repeat("━", 12))+
+			lipgloss.NewStyle().Foreground(lipgloss.Color(orDefault(m.colors.ProgressBarEnding, m.colors.ProgressBar))).Render(strings.Repeat("━", 4))+
+			dimStyle.Render(strings.Repeat("─", 8)),
+		dimStyle.Render("Status line")+"  "+
+			lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.Error)).Bold(true).Render("Error"),
+		"",
+	)
+
+	switch {
+	case v.hexEditing:
+		lines = append(lines, "Enter apply  •  ESC cancel")
+	case v.palette:
+		lines = append(lines, "←/→/↑/↓ pick  •  Enter apply  •  ESC cancel")
+	default:
+		lines = append(lines, "↑/↓ select  •  p palette  •  Enter hex  •  r revert  •  w save  •  ESC close")
+	}
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" 🎨 Theme editor"),
+		box,
+	)
+}
+
+func orUnset(color string) string {
+	return orDefault(color, "unset")
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
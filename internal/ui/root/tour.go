@@ -0,0 +1,132 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// tourStep is one card of the onboarding tour. highlight names the player
+// row the card is about ("controls" or "status"), if any.
+type tourStep struct {
+	title     string
+	text      string
+	highlight string
+}
+
+var tourSteps = []tourStep{
+	{"Welcome to Spotirice",
+		"Spotirice controls whatever Spotify is playing, on this computer or any other device. This tour shows the keys you'll use most.",
+		""},
+	{"Playback",
+		"Space or p plays and pauses, n and b skip, ← and → seek, + and - change the volume. The buttons above can be clicked too.",
+		"controls"},
+	{"Search and devices",
+		"s or / searches for songs and Enter plays one. d picks the device to play on, l likes the track and c opens the playlist or album that's playing.",
+		""},
+	{"Help",
+		"? shows every key whenever you need it; the footer is a reminder. Enjoy the music!",
+		"status"},
+}
+
+// tourView is the onboarding tour shown under the player on first launch.
+type tourView struct {
+	visible bool
+	step    int
+}
+
+// tourHighlights reports whether the current tour card is about row.
+func (m RootModel) tourHighlights(row string) bool {
+	return m.tour.visible && tourSteps[m.tour.step].highlight == row
+}
+
+// highlightRow makes a player row stand out for the tour. The row's own
+// colors are dropped so the reverse video reads the same in every theme.
+func highlightRow(row string) string {
+	return lipgloss.NewStyle().Reverse(true).Render(ansi.Strip(row))
+}
+
+// endTour closes the tour for good, whether it was finished or skipped.
+func (m RootModel) endTour() (tea.Model, tea.Cmd) {
+	m.tour.visible = false
+	if err := config.SaveTourDone(); err != nil {
+		m.status = "Couldn't remember the tour was seen: " + err.Error()
+		return m, clearStatusCmd()
+	}
+	return m, nil
+}
+
+func (m RootModel) updateTour(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		return m.endTour()
+	case "enter", "right", " ", "tab":
+		if m.tour.step == len(tourSteps)-1 {
+			return m.endTour()
+		}
+		m.tour.step++
+	case "left", "backspace", "shift+tab":
+		if m.tour.step > 0 {
+			m.tour.step--
+		}
+	}
+	return m, nil
+}
+
+// tourFooter is the key hint under each card.
+func (m RootModel) tourFooter() string {
+	next := "Enter next"
+	if m.tour.step == len(tourSteps)-1 {
+		next = "Enter finish"
+	}
+	hints := []string{next}
+	if m.tour.step > 0 {
+		hints = append(hints, "← back")
+	}
+	return strings.Join(append(hints, "ESC skip tour"), "  •  ")
+}
+
+// renderTourCard draws the current tour step in a box at most width cells
+// wide.
+func (m RootModel) renderTourCard(width int) string {
+	step := tourSteps[m.tour.step]
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	cardStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(0, 1)
+
+	inner := min(width, 64) - cardStyle.GetHorizontalFrameSize()
+	if inner < 10 {
+		inner = 10
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(fmt.Sprintf("%s  (%d/%d)", step.title, m.tour.step+1, len(tourSteps))),
+		lipgloss.NewStyle().Width(inner).Render(step.text),
+		"",
+		dimStyle.Render(truncate(m.tourFooter(), inner)),
+	)
+	return cardStyle.Width(inner + cardStyle.GetHorizontalPadding()).Render(content)
+}
+
+// plainTourLines is the current tour step for screen_reader mode.
+func (m RootModel) plainTourLines() []string {
+	step := tourSteps[m.tour.step]
+	return []string{
+		fmt.Sprintf("Tour %d of %d: %s", m.tour.step+1, len(tourSteps), step.title),
+		step.text,
+		m.tourFooter(),
+	}
+}
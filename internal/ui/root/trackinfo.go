@@ -0,0 +1,184 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// trackInfoView is the track detail popup: what's playing, plus tempo, key
+// and feel from Spotify's audio features.
+type trackInfoView struct {
+	visible bool
+	err     string // why the features couldn't be loaded this time
+}
+
+type audioFeaturesMsg struct {
+	ID       spotify.ID
+	Features *spotify.AudioFeatures
+	Err      error
+}
+
+// fetchAudioFeaturesCmd loads the audio features of one track.
+func fetchAudioFeaturesCmd(c *spotify.Client, id spotify.ID) tea.Cmd {
+	return func() tea.Msg {
+		features, err := c.GetAudioFeatures(context.Background(), id)
+		if err != nil {
+			return audioFeaturesMsg{ID: id, Err: err}
+		}
+		msg := audioFeaturesMsg{ID: id}
+		if len(features) > 0 {
+			msg.Features = features[0]
+		}
+		return msg
+	}
+}
+
+// featuresDenied reports whether err means this app may not read audio
+// features at all, as with apps registered after Spotify restricted the
+// endpoint. Asking again won't help, unlike with network trouble.
+func featuresDenied(err error) bool {
+	var se spotify.Error
+	return errors.As(err, &se) && (se.Status == http.StatusForbidden || se.Status == http.StatusNotFound)
+}
+
+// handleAudioFeatures caches features, or their absence, per track.
+func (m RootModel) handleAudioFeatures(msg audioFeaturesMsg) (RootModel, tea.Cmd) {
+	if msg.Err != nil && !featuresDenied(msg.Err) {
+		if msg.ID == m.currentTrackID {
+			m.trackInfo.err = msg.Err.Error()
+		}
+		return m, nil
+	}
+	// A nil entry records that Spotify has no features for the track
+	m.featureCache[msg.ID] = msg.Features
+	return m, nil
+}
+
+// trackInfoCmd fetches the playing track's features unless they're cached.
+func (m RootModel) trackInfoCmd() tea.Cmd {
+	if m.currentTrackID == "" {
+		return nil
+	}
+	if _, ok := m.featureCache[m.currentTrackID]; ok {
+		return nil
+	}
+	return fetchAudioFeaturesCmd(m.client, m.currentTrackID)
+}
+
+func (m RootModel) openTrackInfo() (RootModel, tea.Cmd) {
+	if m.trackName == "" {
+		m.status = "Nothing is playing."
+		return m, clearStatusCmd()
+	}
+	m.trackInfo = trackInfoView{visible: true}
+	return m, m.trackInfoCmd()
+}
+
+func (m RootModel) updateTrackInfo(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "i":
+		m.trackInfo.visible = false
+	}
+	return m, nil
+}
+
+var pitchClasses = []string{"C", "C♯", "D", "E♭", "E", "F", "F♯", "G", "A♭", "A", "B♭", "B"}
+
+// formatKey names a key the way musicians and DJs read it, e.g.
+// "A minor (8A)", with its Camelot wheel position for harmonic mixing.
+func formatKey(key spotify.Numeric, mode spotify.Numeric) string {
+	if key < 0 || int(key) >= len(pitchClasses) {
+		return "unknown"
+	}
+	name, letter, tonic := "major", "B", int(key)
+	if mode == spotify.Numeric(spotify.Minor) {
+		// Minor keys share a wheel number with their relative major
+		name, letter, tonic = "minor", "A", tonic+3
+	}
+	// Each step around the wheel is a fifth; C major sits at 8B
+	camelot := (tonic*7+7)%12 + 1
+	return fmt.Sprintf("%s %s (%d%s)", pitchClasses[key], name, camelot, letter)
+}
+
+func (m RootModel) renderTrackInfoScreen() string {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	trackStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	artistStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	lines := []string{
+		trackStyle.Render(m.fitLine(m.trackName)),
+		artistStyle.Render(m.fitLine(m.artistName)),
+	}
+	if m.albumName != "" {
+		album := m.albumName
+		if year, _, _ := strings.Cut(m.releaseDate, "-"); year != "" {
+			album += " (" + year + ")"
+		}
+		lines = append(lines, dimStyle.Render(m.fitLine(album)))
+	}
+	lines = append(lines, dimStyle.Render("Length "+formatTime(m.durationMs)), "")
+
+	features, cached := m.featureCache[m.currentTrackID]
+	switch {
+	case m.currentTrackID == "":
+		lines = append(lines, dimStyle.Render("Audio features are only available for Spotify tracks."))
+	case m.trackInfo.err != "" && !cached:
+		lines = append(lines, dimStyle.Render(m.fitLine("Couldn't load audio features: "+m.trackInfo.err)))
+	case !cached:
+		lines = append(lines, dimStyle.Render("Loading audio features..."))
+	case features == nil:
+		lines = append(lines, dimStyle.Render("Spotify doesn't provide audio features for this track to Spotirice."))
+	default:
+		row := func(label, value string) string {
+			return dimStyle.Render(fmt.Sprintf("%-13s", label)) + value
+		}
+		tempo := fmt.Sprintf("%.0f BPM", features.Tempo)
+		if features.TimeSignature > 0 {
+			tempo += fmt.Sprintf(" (%d/4)", features.TimeSignature)
+		}
+		lines = append(lines,
+			row("Tempo", tempo),
+			row("Key", formatKey(features.Key, features.Mode)),
+			row("Energy", fmt.Sprintf("%.0f%%", features.Energy*100)),
+			row("Danceability", fmt.Sprintf("%.0f%%", features.Danceability*100)),
+		)
+	}
+
+	lines = append(lines, "", "ESC close")
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" ℹ Track details"),
+		box,
+	)
+}
@@ -0,0 +1,341 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+// maxContextTracks caps how many tracks are loaded for a context so very
+// large playlists don't stall the UI.
+const maxContextTracks = 500
+
+type contextNameMsg struct {
+	URI  spotify.URI
+	Name string
+}
+
+// fetchContextNameCmd resolves the display name of a playback context, e.g.
+// "album: Discovery" or "Discover Weekly".
+func fetchContextNameCmd(c *spotify.Client, uri spotify.URI) tea.Cmd {
+	return func() tea.Msg {
+		kind := uriKind(uri)
+		switch kind {
+		case "collection":
+			return contextNameMsg{URI: uri, Name: "Liked Songs"}
+		case "playlist", "album", "artist", "show", "audiobook":
+		default:
+			return nil
+		}
+
+		var out struct {
+			Name string `json:"name"`
+		}
+		query := url.Values{}
+		if kind == "playlist" {
+			// Skip the (possibly huge) track listing
+			query.Set("fields", "name")
+		}
+		path := kind + "s/" + string(uriID(uri))
+		if err := apiRequest(context.Background(), c, http.MethodGet, path, query, &out); err != nil || out.Name == "" {
+			// Some contexts (e.g. Spotify's own mixes) can't be looked up
+			return nil
+		}
+		name := out.Name
+		if kind != "playlist" {
+			name = kind + ": " + name
+		}
+		return contextNameMsg{URI: uri, Name: name}
+	}
+}
+
+// trackListView is a scrollable list of tracks belonging to a playback
+// context (album, playlist, artist). Enter plays the track within that
+// context so playback continues through the list.
+type trackListView struct {
+	visible    bool
+	title      string
+	contextURI spotify.URI
+	tracks     []spotify.FullTrack
+	cursor     int
+	playlist   *playlistMeta // set for playlist contexts
+	cover      playlistCover
+	selected   map[spotify.ID]bool
+}
+
+type contextTracksMsg struct {
+	Title      string
+	ContextURI spotify.URI
+	Tracks     []spotify.FullTrack
+	// Highlight is the URI of the track to scroll to, usually the one playing.
+	Highlight spotify.URI
+	Playlist  *playlistMeta
+}
+
+// fetchContextTracksCmd loads the tracks of an album, playlist or artist
+// context.
+func fetchContextTracksCmd(c *spotify.Client, contextURI, highlight spotify.URI) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		id := uriID(contextURI)
+		msg := contextTracksMsg{ContextURI: contextURI, Highlight: highlight}
+
+		switch uriKind(contextURI) {
+		case "album":
+			album, err := c.GetAlbum(ctx, id, spotify.Market(spotify.MarketFromToken))
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			msg.Title = "Album: " + album.Name
+			page := &album.Tracks
+			for {
+				for _, st := range page.Tracks {
+					msg.Tracks = append(msg.Tracks, spotify.FullTrack{SimpleTrack: st, Album: album.SimpleAlbum})
+				}
+				if len(msg.Tracks) >= maxContextTracks {
+					break
+				}
+				if err := c.NextPage(ctx, page); err != nil {
+					if !errors.Is(err, spotify.ErrNoMorePages) {
+						return errMsg{Err: err}
+					}
+					break
+				}
+			}
+
+		case "playlist":
+			pl, err := c.GetPlaylist(ctx, id, spotify.Fields("id,name,description,owner,followers,public,collaborative,images"))
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			user, err := c.CurrentUser(ctx)
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			msg.Title = "Playlist: " + pl.Name
+			msg.Playlist = newPlaylistMeta(pl, user.ID)
+			page, err := c.GetPlaylistItems(ctx, id, spotify.Market(spotify.MarketFromToken))
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			for {
+				for _, item := range page.Items {
+					if item.Track.Track != nil {
+						msg.Tracks = append(msg.Tracks, *item.Track.Track)
+					}
+				}
+				if len(msg.Tracks) >= maxContextTracks {
+					break
+				}
+				if err := c.NextPage(ctx, page); err != nil {
+					if !errors.Is(err, spotify.ErrNoMorePages) {
+						return errMsg{Err: err}
+					}
+					break
+				}
+			}
+
+		case "artist":
+			artist, err := c.GetArtist(ctx, id)
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			msg.Title = "Artist: " + artist.Name
+			tracks, err := c.GetArtistsTopTracks(ctx, id, spotify.MarketFromToken)
+			if err != nil {
+				return errMsg{Err: err}
+			}
+			msg.Tracks = tracks
+
+		default:
+			return statusMsg("Nothing to open for the current context.")
+		}
+
+		return msg
+	}
+}
+
+// playInContextCmd plays tracks[idx], keeping the surrounding context so the
+// following tracks continue playing.
+func playInContextCmd(c *spotify.Client, contextURI spotify.URI, tracks []spotify.FullTrack, idx int) tea.Cmd {
+	return func() tea.Msg {
+		track := tracks[idx]
+		opts := &spotify.PlayOptions{}
+		switch uriKind(contextURI) {
+		case "album", "playlist":
+			opts.PlaybackContext = &contextURI
+			opts.PlaybackOffset = &spotify.PlaybackOffset{URI: track.URI}
+		default:
+			// Artist contexts don't accept an offset; queue the list instead
+			for _, t := range tracks[idx:] {
+				if unplayableReason(t) == "" {
+					opts.URIs = append(opts.URIs, t.URI)
+				}
+			}
+		}
+		if err := c.PlayOpt(context.Background(), opts); err != nil {
+			return errMsg{Err: err}
+		}
+		return statusMsg("Playing " + track.Name)
+	}
+}
+
+func (m RootModel) openCurrentContext() (RootModel, tea.Cmd) {
+	if m.contextURI == "" {
+		m.status = "Nothing is playing from a playlist, album or artist."
+		return m, clearStatusCmd()
+	}
+	return m, fetchContextTracksCmd(m.client, m.contextURI, m.currentTrackURI)
+}
+
+func (m RootModel) updateTrackList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.trackList
+	switch msg.String() {
+	case "esc", "c":
+		v.visible = false
+	case "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down":
+		if v.cursor < len(v.tracks)-1 {
+			v.cursor++
+		}
+	case "A":
+		if v.cursor < len(v.tracks) {
+			v.visible = false
+			return m.openAddToPlaylist(v.tracks[v.cursor])
+		}
+	case "x":
+		if v.cursor < len(v.tracks) {
+			id := v.tracks[v.cursor].ID
+			if v.selected[id] {
+				delete(v.selected, id)
+			} else if id != "" {
+				v.selected[id] = true
+			}
+		}
+	case "L", "U":
+		return m.startBatchLike(v.tracks, v.selected, msg.String() == "L")
+	case "E":
+		if v.playlist != nil {
+			return m.openPlaylistEdit(*v.playlist)
+		}
+	case "enter":
+		if v.cursor < len(v.tracks) {
+			if m.readOnly {
+				m.status = premiumRequiredReason
+				return m, clearStatusCmd()
+			}
+			if reason := unplayableReason(v.tracks[v.cursor]); reason != "" {
+				m.status = reason
+				return m, clearStatusCmd()
+			}
+			if !m.confirmExplicit(v.tracks[v.cursor]) {
+				return m, clearStatusCmd()
+			}
+			v.visible = false
+			m.burstTicksRemaining = 10
+			return m, playInContextCmd(m.client, v.contextURI, v.tracks, v.cursor)
+		}
+	}
+	return m, nil
+}
+
+func (m RootModel) renderTrackListScreen() string {
+	v := m.trackList
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPlaying)).
+		Bold(true)
+
+	playingStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.TrackPaused))
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Artist))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status)).
+		Faint(true)
+
+	// Reserve lines for: header(1) + border(2) + padding(2) + footer(2)
+	maxVisible := m.height - 7
+	if maxVisible < 3 {
+		maxVisible = 3
+	}
+
+	var lines []string
+	if v.playlist != nil {
+		info := m.renderPlaylistMeta(*v.playlist)
+		lines = append(lines, info...)
+		maxVisible -= len(info)
+		if maxVisible < 3 {
+			maxVisible = 3
+		}
+	}
+	if len(v.tracks) == 0 {
+		lines = append(lines, "No tracks.")
+	}
+	start, end := visibleRange(v.cursor, len(v.tracks), maxVisible)
+	for i := start; i < end; i++ {
+		t := v.tracks[i]
+		marker := "  "
+		if t.URI == m.currentTrackURI {
+			marker = "♪ "
+		}
+		line := fmt.Sprintf("  %s%s%s%s - %s%s", selectionMark(v.selected, t.ID), marker, t.Name, explicitMark(t), trackArtist(t), m.likedMark(t.ID))
+		unplayable := unplayableReason(t) != ""
+		if unplayable {
+			line += " (unavailable)"
+		}
+		line = m.fitLine(line)
+		switch {
+		case i == v.cursor:
+			line = selectedStyle.Render("▶ " + line[2:])
+		case unplayable:
+			line = dimStyle.Render(line)
+		case t.URI == m.currentTrackURI:
+			line = playingStyle.Render(line)
+		default:
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	footer := "Enter play  •  A add to playlist  •  x select  •  L/U like/unlike selected  •  ESC close"
+	if v.playlist != nil && v.playlist.Owned {
+		footer += "  •  E edit details"
+	}
+	lines = append(lines, "", footer)
+	content := strings.Join(lines, "\n")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" "+v.title),
+		box,
+	)
+}
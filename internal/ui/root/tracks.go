@@ -0,0 +1,52 @@
+package root
+
+import (
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// isLocalTrack reports whether a track is a local file. Local files have no
+// Spotify ID and can't be played, liked or queued through the Web API.
+func isLocalTrack(t spotify.FullTrack) bool {
+	return strings.HasPrefix(string(t.URI), "spotify:local:")
+}
+
+// unplayableReason returns a user-facing explanation when a track can't be
+// played through the Web API, or "" when it can.
+func unplayableReason(t spotify.FullTrack) string {
+	if isLocalTrack(t) {
+		return "Local files can't be played from Spotirice."
+	}
+	if t.IsPlayable != nil && !*t.IsPlayable {
+		return "This track isn't available in your market."
+	}
+	return ""
+}
+
+// uriID extracts the ID from a URI such as "spotify:album:<id>".
+func uriID(uri spotify.URI) spotify.ID {
+	s := string(uri)
+	return spotify.ID(s[strings.LastIndex(s, ":")+1:])
+}
+
+// uriKind returns the type segment of a URI ("album", "playlist", ...).
+func uriKind(uri spotify.URI) string {
+	parts := strings.Split(string(uri), ":")
+	if len(parts) < 3 {
+		return ""
+	}
+	// Liked Songs contexts look like "spotify:user:<id>:collection"
+	if parts[1] == "user" && parts[len(parts)-1] == "collection" {
+		return "collection"
+	}
+	return parts[1]
+}
+
+// trackArtist returns the first artist name of a track.
+func trackArtist(t spotify.FullTrack) string {
+	if len(t.Artists) > 0 {
+		return t.Artists[0].Name
+	}
+	return ""
+}
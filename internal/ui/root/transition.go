@@ -0,0 +1,105 @@
+package root
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	transitionFrames = 8
+	transitionFrame  = 40 * time.Millisecond
+)
+
+// transition is the short animation played when the track changes: the new
+// title fades and slides in while the progress bar sweeps back from where
+// the previous track ended.
+type transition struct {
+	frame    int     // frames left; 0 when idle
+	fromFill float64 // progress ratio of the previous track
+}
+
+type transitionTickMsg struct{}
+
+func transitionTickCmd() tea.Cmd {
+	return tea.Tick(transitionFrame, func(time.Time) tea.Msg { return transitionTickMsg{} })
+}
+
+// startTransition begins the track-change animation, if enabled.
+func (m RootModel) startTransition(prev RootModel) (RootModel, tea.Cmd) {
+	if !m.settings.Transitions || m.settings.ReducedMotion || m.settings.ScreenReader ||
+		!prev.hasInitialState || prev.trackName == "" || prev.trackName == m.trackName {
+		return m, nil
+	}
+	from := 0.0
+	if prev.durationMs > 0 {
+		from = float64(prev.progressMs) / float64(prev.durationMs)
+	}
+	running := m.transition.frame > 0
+	m.transition = transition{frame: transitionFrames, fromFill: from}
+	if running {
+		// The tick already scheduled keeps driving the new run
+		return m, nil
+	}
+	return m, transitionTickCmd()
+}
+
+// step is how far the animation has come, from 0 (start) to 1 (done).
+func (t transition) step() float64 {
+	return 1 - float64(t.frame)/transitionFrames
+}
+
+// slideTitle shifts the title right by the part of the slide still to go.
+func (t transition) slideTitle(title string) string {
+	if t.frame == 0 {
+		return title
+	}
+	return strings.Repeat(" ", t.frame) + title
+}
+
+// fadeColor blends from the start color to the final one as the
+// transition runs. Non-hex colors (ANSI numbers) switch at the end instead.
+func (t transition) fadeColor(from, to string) string {
+	if t.frame == 0 {
+		return to
+	}
+	a, okA := parseHex(from)
+	b, okB := parseHex(to)
+	if !okA || !okB {
+		return from
+	}
+	s := t.step()
+	var mixed [3]int
+	for i := range mixed {
+		mixed[i] = a[i] + int(float64(b[i]-a[i])*s)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", mixed[0], mixed[1], mixed[2])
+}
+
+// sweepRatio is the progress ratio to draw while the bar sweeps back to the
+// new track's position.
+func (t transition) sweepRatio(current float64) float64 {
+	if t.frame == 0 {
+		return current
+	}
+	s := t.step()
+	return t.fromFill + (current-t.fromFill)*s
+}
+
+func parseHex(c string) ([3]int, bool) {
+	var rgb [3]int
+	if len(c) != 7 || c[0] != '#' {
+		return rgb, false
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(c[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return rgb, false
+		}
+		rgb[i] = int(v)
+	}
+	return rgb, true
+}
@@ -0,0 +1,142 @@
+package root
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/platform"
+	"github.com/metolius25/spotirice/internal/update"
+)
+
+// updateCheckTimeout keeps a check on a slow or offline network from
+// lingering; it is retried at the next start.
+const updateCheckTimeout = 5 * time.Second
+
+// updateView is the changelog overlay of a newer release (U).
+type updateView struct {
+	visible bool
+	release *update.Release // nil until a newer release is known
+	offset  int             // first changelog line shown
+}
+
+type updateAvailableMsg struct{ Release update.Release }
+
+// checkUpdateCmd looks for a release newer than version, reusing the last
+// check while it is younger than maxAge. Failures are silent: the notice is
+// a courtesy, not worth an error line.
+func checkUpdateCmd(version string, maxAge time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		last, _ := config.LoadUpdateCheck()
+		if last == nil || time.Since(last.CheckedAt) >= maxAge {
+			ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+			defer cancel()
+			r, err := update.Latest(ctx)
+			if err != nil {
+				return nil
+			}
+			last = &config.UpdateCheck{CheckedAt: time.Now(), Version: r.Version, Notes: r.Notes, URL: r.URL}
+			_ = config.SaveUpdateCheck(*last)
+		}
+		if !update.Newer(last.Version, version) {
+			return nil
+		}
+		return updateAvailableMsg{Release: update.Release{Version: last.Version, Notes: last.Notes, URL: last.URL}}
+	}
+}
+
+// updateNotice is the header's hint at a newer release, or "".
+func (m RootModel) updateNotice() string {
+	if m.update.release == nil {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status)).
+		Render("v" + m.update.release.Version + " available (U)")
+}
+
+func (m RootModel) openUpdate() (RootModel, tea.Cmd) {
+	if m.update.release == nil {
+		return m, nil
+	}
+	m.update.visible = true
+	m.update.offset = 0
+	return m, nil
+}
+
+func (m RootModel) updateUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "U":
+		m.update.visible = false
+	case "up", "k":
+		if m.update.offset > 0 {
+			m.update.offset--
+		}
+	case "down", "j":
+		if m.update.offset < len(m.changelogLines())-1 {
+			m.update.offset++
+		}
+	case "o":
+		if platform.Remote() {
+			m.status = "Not opening a browser over SSH: " + m.update.release.URL
+			return m, clearStatusCmd()
+		}
+		if err := platform.OpenURL(m.update.release.URL); err != nil {
+			m.status = "Couldn't open link: " + err.Error()
+			return m, clearStatusCmd()
+		}
+	}
+	return m, nil
+}
+
+// changelogLines wraps the release notes to the overlay's width.
+func (m RootModel) changelogLines() []string {
+	notes := strings.TrimSpace(strings.ReplaceAll(m.update.release.Notes, "\r\n", "\n"))
+	if notes == "" {
+		notes = "This release has no notes."
+	}
+	if m.width > 6 {
+		notes = lipgloss.NewStyle().Width(m.width - 6).Render(notes)
+	}
+	return strings.Split(notes, "\n")
+}
+
+func (m RootModel) renderUpdateScreen() string {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Header)).
+		Bold(true).
+		Padding(0, 1)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(lipgloss.Color(m.colors.Header)).
+		Padding(1, 2)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.Status))
+
+	lines := m.changelogLines()
+	// Reserve lines for: header(1) + border(2) + padding(2) + footer(2)
+	if rows := m.height - 7; rows > 0 && len(lines) > rows {
+		start := min(m.update.offset, len(lines)-rows)
+		lines = lines[start : start+rows]
+	}
+	content := strings.Join(lines, "\n") + "\n\n" +
+		dimStyle.Render("↑/↓ scroll  •  o open release page  •  ESC close")
+
+	w := m.width - containerStyle.GetHorizontalBorderSize()
+	h := m.height - 1 - containerStyle.GetVerticalBorderSize()
+	if h < 1 {
+		h = lipgloss.Height(content)
+	}
+	box := containerStyle.Width(w).Height(h).Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(" ⬆ Spotirice v"+m.update.release.Version+" (you have v"+m.version+")"),
+		box,
+	)
+}
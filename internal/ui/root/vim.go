@@ -0,0 +1,99 @@
+package root
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// vimBindings is the vim keymap preset: h/l seek, j/k change the volume
+// and * likes, as l is taken. Lists also get j/k, gg and G, and :q quits
+// (see vimListKey and vimChords).
+var vimBindings = map[string]config.KeyList{
+	"seek_back":    {"h"},
+	"seek_forward": {"l"},
+	"volume_down":  {"j"},
+	"volume_up":    {"k"},
+	"like":         {"*"},
+}
+
+// vimChords are the prefix keys only the vim preset has.
+var vimChords = map[string][]chord{
+	":": {
+		{"q", "quit", func(m RootModel) (RootModel, tea.Cmd) {
+			return m, tea.Quit
+		}},
+	},
+}
+
+// chordsFor returns the continuations of prefix in the active keymap.
+func (m RootModel) chordsFor(prefix string) ([]chord, bool) {
+	if m.keys.vim {
+		if c, ok := vimChords[prefix]; ok {
+			return c, true
+		}
+	}
+	c, ok := chords[prefix]
+	return c, ok
+}
+
+// activeList returns the cursor and length of the list on screen, if the
+// screen taking keys is one; it follows the order Update checks them in.
+func (m *RootModel) activeList() (*int, int) {
+	switch {
+	case m.showEpisodes:
+		return &m.episodesCursor, len(m.episodes)
+	case m.audiobooks.visible || m.addToPlaylist.visible || m.playlistEdit.visible || m.smartPlaylist.visible:
+		return nil, 0
+	case m.devices.visible:
+		return &m.devices.cursor, len(m.devices.devices)
+	case m.trackList.visible:
+		return &m.trackList.cursor, len(m.trackList.tracks)
+	case m.tabs.active == tabQueue:
+		return &m.tabs.queue.cursor, len(m.tabs.queue.tracks)
+	case m.tabs.active == tabLibrary && m.tabs.library.liked:
+		return &m.tabs.library.likedSongs.cursor, len(m.tabs.library.likedSongs.tracks)
+	case m.tabs.active == tabLibrary:
+		return &m.tabs.library.playlists.cursor, len(m.tabs.library.playlists.playlists)
+	case m.panel.visible || m.party.visible || m.share.visible:
+		return nil, 0
+	case m.genres.visible:
+		return &m.genres.cursor, len(m.genres.genres)
+	}
+	return nil, 0
+}
+
+// vimListKey gives lists the vim preset's keys: j and k are turned into
+// down and up for the list to handle, while gg and G jump to either end
+// right here (done is true then).
+func (m RootModel) vimListKey(msg tea.KeyMsg) (next RootModel, key tea.KeyMsg, cmd tea.Cmd, done bool) {
+	cursor, n := m.activeList()
+	if cursor == nil {
+		return m, msg, nil, false
+	}
+	pending := m.listG
+	m.listG = false
+	switch msg.String() {
+	case "j":
+		return m, tea.KeyMsg{Type: tea.KeyDown}, nil, false
+	case "k":
+		return m, tea.KeyMsg{Type: tea.KeyUp}, nil, false
+	case "g":
+		if pending {
+			*cursor = 0
+		} else {
+			m.listG = true
+		}
+		return m, msg, nil, true
+	case "G":
+		*cursor = max(n-1, 0)
+		// Lists loaded page by page fetch the next one from there
+		if m.tabs.active == tabLibrary && !m.trackList.visible {
+			l := m.tabs.library
+			l.env = m.tabEnv()
+			m.tabs.library, cmd = l.more()
+		}
+		return m, msg, cmd, true
+	}
+	return m, msg, nil, false
+}
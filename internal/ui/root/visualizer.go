@@ -0,0 +1,251 @@
+package root
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	visFrame     = 100 * time.Millisecond
+	visBars      = 32
+	visMaxHeight = 8
+)
+
+// visualizerView is the bar pane under the player. Levels come either from
+// cava reading the local audio monitor, or are synthesized from the beat
+// markers of the track's audio analysis.
+type visualizerView struct {
+	visible bool
+	levels  []float64 // 0-1 per bar
+	posMs   int       // playback position between polls, for beat timing
+
+	// cava mode
+	cava   *exec.Cmd
+	frames chan []float64
+}
+
+type visTickMsg struct{}
+type visFramesMsg struct {
+	Levels []float64
+	frames chan []float64 // source, to drop frames from a stopped cava
+}
+
+// visCavaExitedMsg reports that cava stopped sending frames on its own.
+type visCavaExitedMsg struct{ frames chan []float64 }
+
+func visTickCmd() tea.Cmd {
+	return tea.Tick(visFrame, func(time.Time) tea.Msg { return visTickMsg{} })
+}
+
+func (m RootModel) toggleVisualizer() (RootModel, tea.Cmd) {
+	v := &m.visualizer
+	if v.visible {
+		v.stop()
+		v.visible = false
+		return m, nil
+	}
+	if m.settings.ReducedMotion {
+		m.status = "The visualizer is off with reduced_motion."
+		return m, clearStatusCmd()
+	}
+
+	v.visible = true
+	v.posMs = m.progressMs
+	if m.settings.Visualizer == "cava" {
+		if err := v.startCava(); err != nil {
+			v.visible = false
+			m.status = "Error: " + err.Error()
+			return m, clearStatusCmd()
+		}
+		return m, waitFramesCmd(v.frames)
+	}
+	var cmd tea.Cmd
+	if m.currentTrackID != "" && m.currentTrackID != m.analysisTrackID {
+		m.analysisTrackID = m.currentTrackID
+		cmd = fetchAudioAnalysisCmd(m.client, m.currentTrackID)
+	}
+	return m, tea.Batch(cmd, visTickCmd())
+}
+
+// needsAnalysis reports whether a newly playing track's audio analysis
+// should be fetched for the visualizer.
+func (m RootModel) needsAnalysis(id spotify.ID) bool {
+	return m.visualizer.visible && m.settings.Visualizer != "cava" && id != ""
+}
+
+// advance moves the synthesized bars on by one frame.
+func (v *visualizerView) advance(m RootModel) {
+	if m.isPlaying {
+		v.posMs += int(visFrame / time.Millisecond)
+	}
+	if d := v.posMs - m.progressMs; d > 1500 || d < -1500 {
+		// Seeked, skipped or drifted; follow the polled position
+		v.posMs = m.progressMs
+	}
+	v.levels = beatLevels(m.beatOffsets, v.posMs, visBars)
+}
+
+// beatLevels peaks every bar on a beat and lets it decay until the next,
+// with a per-beat pattern so neighbouring bars don't move in lockstep.
+func beatLevels(beats []int, posMs, n int) []float64 {
+	levels := make([]float64, n)
+	i := sort.SearchInts(beats, posMs+1) - 1
+	if i < 0 {
+		return levels
+	}
+	period := 500
+	if i+1 < len(beats) {
+		period = beats[i+1] - beats[i]
+	}
+	decay := 1 - float64(posMs-beats[i])/float64(period)
+	if decay < 0 {
+		decay = 0
+	}
+	for b := range levels {
+		// Cheap integer hash of (beat, bar) for a stable pseudo-random shape
+		h := uint32(i*7919 + b*104729)
+		h ^= h >> 16
+		h *= 0x45d9f3b
+		h ^= h >> 16
+		levels[b] = (0.35 + 0.65*float64(h>>24)/255) * decay
+	}
+	return levels
+}
+
+// cavaConfig makes cava print one line of semicolon-separated levels
+// (0-100) per frame on stdout.
+const cavaConfig = `[general]
+bars = %d
+framerate = 20
+
+[output]
+method = raw
+raw_target = /dev/stdout
+data_format = ascii
+ascii_max_range = 100
+`
+
+func (v *visualizerView) startCava() error {
+	if _, err := exec.LookPath("cava"); err != nil {
+		return fmt.Errorf(`visualizer = "cava" needs cava installed`)
+	}
+	f, err := os.CreateTemp("", "spotirice-cava-*.conf")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, cavaConfig, visBars)
+	f.Close()
+
+	cmd := exec.Command("cava", "-p", f.Name())
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	frames := make(chan []float64)
+	go func() {
+		defer os.Remove(f.Name())
+		defer close(frames)
+		sc := bufio.NewScanner(out)
+		for sc.Scan() {
+			var levels []float64
+			for _, field := range strings.Split(strings.TrimSuffix(sc.Text(), ";"), ";") {
+				n, _ := strconv.Atoi(field)
+				levels = append(levels, float64(n)/100)
+			}
+			frames <- levels
+		}
+	}()
+	v.cava = cmd
+	v.frames = frames
+	return nil
+}
+
+// waitFramesCmd delivers the next cava frame, or visCavaExitedMsg once cava
+// exits.
+func waitFramesCmd(frames chan []float64) tea.Cmd {
+	return func() tea.Msg {
+		levels, ok := <-frames
+		if !ok {
+			return visCavaExitedMsg{frames: frames}
+		}
+		return visFramesMsg{Levels: levels, frames: frames}
+	}
+}
+
+// stop ends cava, if it runs. Its reader goroutine unblocks on the closed
+// pipe and closes frames.
+func (v *visualizerView) stop() {
+	if v.cava != nil {
+		_ = v.cava.Process.Kill()
+		go v.cava.Wait()
+		// Unblock the reader if it's waiting to hand over a frame
+		go func(frames chan []float64) {
+			for range frames {
+			}
+		}(v.frames)
+		v.cava = nil
+		v.frames = nil
+	}
+	v.levels = nil
+}
+
+// Close releases what the model started outside the program, currently
+// the visualizer's cava process.
+func (m RootModel) Close() {
+	m.visualizer.stop()
+}
+
+// renderVisualizer draws the levels as bars of at most height rows, with as
+// many bars as fit in width (two cells each).
+func (m RootModel) renderVisualizer(height, width int) string {
+	if height > visMaxHeight {
+		height = visMaxHeight
+	}
+	bars := min(visBars, width/2)
+	if height < 1 || bars < 1 {
+		return ""
+	}
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.ProgressBar))
+	levels := make([]float64, bars)
+	if src := m.visualizer.levels; len(src) > 0 {
+		// Narrow panes show every other bar or so, spread over the spectrum
+		for i := range levels {
+			levels[i] = src[i*len(src)/bars]
+		}
+	}
+
+	// Eighth blocks give each row 8 steps
+	steps := []rune(" ▁▂▃▄▅▆▇█")
+	rows := make([]string, height)
+	for r := 0; r < height; r++ {
+		var b strings.Builder
+		floor := float64(height-1-r) / float64(height)
+		for _, l := range levels {
+			fill := (l - floor) * float64(height) * 8
+			switch {
+			case fill >= 8:
+				b.WriteRune('█')
+			case fill <= 0:
+				b.WriteRune(' ')
+			default:
+				b.WriteRune(steps[int(fill)])
+			}
+			b.WriteRune(' ')
+		}
+		rows[r] = style.Render(b.String())
+	}
+	return strings.Join(rows, "\n")
+}
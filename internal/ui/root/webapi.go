@@ -0,0 +1,66 @@
+package root
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+const webAPIBaseURL = "https://api.spotify.com/v1/"
+
+// apiClient sends apiRequest's requests; see SetTransport.
+var apiClient = http.DefaultClient
+
+// SetTransport routes the requests made outside the spotify client through
+// rt, so they reach the same API as the client does (the mock server, say).
+func SetTransport(rt http.RoundTripper) {
+	apiClient = &http.Client{Transport: rt}
+}
+
+// apiRequest calls a Web API endpoint that the spotify client library doesn't
+// wrap yet, reusing the client's (auto-refreshing) OAuth token. The response
+// body is decoded into out when out is non-nil.
+func apiRequest(ctx context.Context, c *spotify.Client, method, path string, query url.Values, out interface{}) error {
+	tok, err := c.Token()
+	if err != nil {
+		return err
+	}
+
+	u := webAPIBaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+	tok.SetAuthHeader(req)
+
+	resp, err := apiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error spotify.Error `json:"error"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(body, &e) == nil && e.Error.Message != "" {
+			return e.Error
+		}
+		return fmt.Errorf("spotify: HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,89 @@
+// Package update asks GitHub whether a newer Spotirice release is out.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const latestURL = "https://api.github.com/repos/metolius25/spotirice/releases/latest"
+
+// Release is a published release.
+type Release struct {
+	Version string `json:"version"` // without the leading "v"
+	Notes   string `json:"notes"`   // the changelog, in Markdown
+	URL     string `json:"url"`     // the release page
+}
+
+// Latest fetches the newest non-prerelease release. ctx bounds the request,
+// so an offline machine gives up quickly.
+func Latest(ctx context.Context) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestURL, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("GitHub releases: %s", resp.Status)
+	}
+
+	var r struct {
+		TagName string `json:"tag_name"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return Release{}, err
+	}
+	return Release{Version: strings.TrimPrefix(r.TagName, "v"), Notes: r.Body, URL: r.HTMLURL}, nil
+}
+
+// Newer reports whether version latest comes after current. Versions that
+// aren't dotted numbers, like the "dev" of source builds, are never behind.
+func Newer(latest, current string) bool {
+	l, ok := parse(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parse(current)
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(l) || i < len(c); i++ {
+		var a, b int
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(c) {
+			b = c[i]
+		}
+		if a != b {
+			return a > b
+		}
+	}
+	return false
+}
+
+// parse splits "v1.2.3" (or "1.2.3-rc1", ignoring the suffix) into numbers.
+func parse(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "-")
+	var nums []int
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		nums = append(nums, n)
+	}
+	return nums, true
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/auth"
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// runLogin logs in to Spotify afresh and saves the token. With --headless it
+// doesn't wait for the browser on this machine, so it works on machines
+// without one; over SSH that happens anyway.
+func runLogin(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	headless := fs.Bool("headless", false, "paste the redirect address instead of waiting for the browser")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var client *spotify.Client
+	var err error
+	if *headless {
+		client, err = auth.AuthenticateHeadless(os.Stdin, os.Stdout)
+	} else {
+		client, err = auth.Reauthenticate()
+	}
+	if err != nil {
+		return err
+	}
+	user, err := client.CurrentUser(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Println("Logged in as", user.DisplayName)
+	return nil
+}
+
+// runSecret saves the Spotify app's client secret in the system keyring, so
+// config.toml needn't hold it. It is read from the terminal without echo,
+// or as a line from stdin when that is piped.
+func runSecret() error {
+	var secret string
+	if term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Print("Client secret: ")
+		b, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Println()
+		if err != nil {
+			return err
+		}
+		secret = string(b)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		secret = line
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return errors.New("no secret entered")
+	}
+	if err := config.SaveClientSecret(secret); err != nil {
+		return err
+	}
+	fmt.Println("Saved to the keyring; client_secret can now be removed from config.toml.")
+	return nil
+}
@@ -2,15 +2,25 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/zmb3/spotify/v2"
 
 	"github.com/metolius25/spotirice/internal/auth"
 	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/daemon"
+	"github.com/metolius25/spotirice/internal/devices"
+	"github.com/metolius25/spotirice/internal/keyboard"
+	"github.com/metolius25/spotirice/internal/platform"
 	"github.com/metolius25/spotirice/internal/spotifylauncher"
 	"github.com/metolius25/spotirice/internal/ui/root"
 )
@@ -20,25 +30,40 @@ var Version = "dev"
 type clientMsg struct{ Client *spotify.Client }
 type errMsg struct{ Err error }
 type launchingSpotifyMsg struct{}
+type spotifyLaunchedMsg struct{}
+type devicePollMsg struct{ Found bool }
+
+// Cold starts of the Spotify client can take a while before it registers as
+// a Connect device, so poll instead of sleeping for a fixed time.
+const (
+	devicePollInterval = time.Second
+	devicePollTimeout  = 30 * time.Second
+)
 
 type model struct {
 	client          *spotify.Client
+	authenticate    func() (*spotify.Client, error)
 	status          string
 	colors          *config.Colors
+	settings        *config.Settings
 	launchAttempted bool
+	launchedAt      time.Time
 }
 
-func initialModel(colors *config.Colors) model {
-	return model{status: "Authenticating...", colors: colors}
+func initialModel(colors *config.Colors, settings *config.Settings, authenticate func() (*spotify.Client, error)) model {
+	return model{status: "Authenticating...", colors: colors, settings: settings, authenticate: authenticate}
 }
 
 // Trigger authentication only.
 func (m model) Init() tea.Cmd {
-	return startAuthCmd
+	if m.settings.EnhancedKeyboard {
+		return tea.Batch(m.startAuthCmd, keyboard.Enable)
+	}
+	return m.startAuthCmd
 }
 
-func startAuthCmd() tea.Msg {
-	client, err := auth.Authenticate()
+func (m model) startAuthCmd() tea.Msg {
+	client, err := m.authenticate()
 	if err != nil {
 		return errMsg{err}
 	}
@@ -47,24 +72,19 @@ func startAuthCmd() tea.Msg {
 
 func (m model) runDeviceAutoSelect() tea.Cmd {
 	return func() tea.Msg {
-		devices, err := m.client.PlayerDevices(context.Background())
+		devs, err := m.client.PlayerDevices(context.Background())
 		if err != nil {
 			return errMsg{Err: err}
 		}
 
-		if len(devices) == 0 {
+		if len(devs) == 0 {
 			// No devices found - try to launch Spotify
 			return launchingSpotifyMsg{}
 		}
 
-		var valid *spotify.PlayerDevice
-		for _, d := range devices {
-			if !d.Restricted && (d.Type == "Computer" || d.Type == "Smartphone") {
-				valid = &d
-				break
-			}
-		}
-
+		// A missing state file just means no device has been used yet
+		last, _ := config.LoadLastDevice()
+		valid, _ := devices.Choose(devs, m.settings.PreferredDevice, last, "Computer", "Smartphone")
 		if valid != nil {
 			_ = m.client.TransferPlayback(context.Background(), valid.ID, false)
 		}
@@ -73,18 +93,34 @@ func (m model) runDeviceAutoSelect() tea.Cmd {
 	}
 }
 
-func launchSpotifyCmd() tea.Cmd {
+func launchSpotifyCmd(settings *config.Settings) tea.Cmd {
 	return func() tea.Msg {
-		if err := spotifylauncher.LaunchSpotify(); err != nil {
+		headless := settings.HeadlessPlayer
+		err := spotifylauncher.LaunchSpotify(spotifylauncher.Options{Minimized: settings.LaunchMinimized})
+		if err != nil && headless != "" {
+			// No official client; fall back to the configured headless player
+			err = spotifylauncher.LaunchHeadless(headless)
+		}
+		if err != nil {
 			return errMsg{Err: err}
 		}
-		// Wait for Spotify to start up
-		time.Sleep(3 * time.Second)
-		return nil // Signal to retry device detection
+		return spotifyLaunchedMsg{}
 	}
 }
 
+// pollDevicesCmd checks once, after devicePollInterval, whether any device
+// has appeared.
+func (m model) pollDevicesCmd() tea.Cmd {
+	return tea.Tick(devicePollInterval, func(time.Time) tea.Msg {
+		devs, err := m.client.PlayerDevices(context.Background())
+		return devicePollMsg{Found: err == nil && len(devs) > 0}
+	})
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := keyboard.Translate(msg); ok {
+		return m.Update(key)
+	}
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
@@ -97,31 +133,56 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// First time: store client & init device selection
 		if m.client == nil {
 			m.client = msg.Client
+			if daemon.Running() {
+				// A daemon is already looking after playback; skip device detection
+				return root.NewRootModel(msg.Client, m.colors, m.settings, Version)
+			}
 			m.status = "Authenticated! Detecting devices..."
 			return m, m.runDeviceAutoSelect()
 		}
 
 		// Second time: all done → switch to root UI
-		return root.NewRootModel(msg.Client, m.colors, Version)
+		return root.NewRootModel(msg.Client, m.colors, m.settings, Version)
 
 	case launchingSpotifyMsg:
+		if platform.Remote() {
+			// A client launched here wouldn't be the one the user wants to hear
+			m.status = "No devices found. Open Spotify on the device you want to control."
+			return m, func() tea.Msg { return clientMsg{Client: m.client} }
+		}
 		if !m.launchAttempted {
 			m.launchAttempted = true
 			m.status = "No Spotify devices found. Launching Spotify..."
-			return m, launchSpotifyCmd()
+			return m, launchSpotifyCmd(m.settings)
 		}
 		// Already tried, just proceed without device
 		m.status = "No devices found. Please open Spotify manually."
 		return m, func() tea.Msg { return clientMsg{Client: m.client} }
 
-	case nil:
-		// Returned from launchSpotifyCmd, retry device detection
-		if m.client != nil {
-			m.status = "Spotify launched! Detecting devices..."
+	case spotifyLaunchedMsg:
+		m.launchedAt = time.Now()
+		m.status = "Spotify launched! Waiting for it to appear as a device..."
+		return m, m.pollDevicesCmd()
+
+	case devicePollMsg:
+		if msg.Found {
+			m.status = "Spotify is ready! Detecting devices..."
 			return m, m.runDeviceAutoSelect()
 		}
+		waited := time.Since(m.launchedAt)
+		if waited >= devicePollTimeout {
+			// runDeviceAutoSelect will give up now that launchAttempted is set
+			return m, m.runDeviceAutoSelect()
+		}
+		m.status = fmt.Sprintf("Waiting for Spotify to start... (%ds/%ds)",
+			int(waited.Seconds()), int(devicePollTimeout.Seconds()))
+		return m, m.pollDevicesCmd()
 
 	case errMsg:
+		if m.client != nil && auth.IsRevoked(msg.Err) {
+			// The player offers a new login in place
+			return root.NewRootModel(m.client, m.colors, m.settings, Version)
+		}
 		m.status = "Error: " + msg.Err.Error()
 	}
 
@@ -132,22 +193,204 @@ func (m model) View() string {
 	return "Spotirice\n" + m.status
 }
 
+// applyColorMode forces the color profile lipgloss renders with. Detection
+// (COLORTERM, TERM, NO_COLOR) already maps theme hex values to the nearest
+// color the terminal supports; this covers terminals that misreport.
+func applyColorMode(mode string) error {
+	profiles := map[string]termenv.Profile{
+		"truecolor": termenv.TrueColor,
+		"256":       termenv.ANSI256,
+		"16":        termenv.ANSI,
+		"none":      termenv.Ascii,
+	}
+	if mode == "" || mode == "auto" {
+		return nil
+	}
+	p, ok := profiles[mode]
+	if !ok {
+		return fmt.Errorf("unknown color_mode %q", mode)
+	}
+	lipgloss.SetColorProfile(p)
+	return nil
+}
+
 func main() {
+	configFile := flag.String("config", "", "read settings and colors from this config.toml")
+	portable := flag.Bool("portable", false, "keep all files next to the binary")
+	mockMode := flag.Bool("mock", false, "run the player against a built-in fake Spotify")
+	demoFile := flag.String("demo", "", "replay a session recorded with --record")
+	recordFile := flag.String("record", "", "record the session's API calls to this file")
+	profile := flag.String("profile", os.Getenv("SPOTIRICE_PROFILE"), "use this account profile's config and login")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Dir(exe)
+		if _, err := os.Stat(filepath.Join(dir, config.PortableMarker)); err == nil || *portable {
+			config.SetPortable(filepath.Join(dir, "spotirice-data"))
+		}
+	}
+	if *configFile != "" {
+		config.SetConfigFile(*configFile)
+	}
+	if err := config.SetProfile(*profile); err != nil {
+		log.Fatal(err)
+	}
+
+	// Commands read the config too, so upgrade it before any of them runs
+	if backups, err := config.Migrate(); err != nil {
+		log.Fatal("Failed to upgrade config:", err)
+	} else if len(backups) > 0 {
+		log.Printf("Upgraded config to version %d; the old files are kept as %s", config.ConfigVersion, strings.Join(backups, ", "))
+	}
+
+	if (*mockMode || *demoFile != "" || *recordFile != "") && flag.NArg() > 0 {
+		log.Fatal("--mock, --demo and --record only apply to the player, not to commands")
+	}
+	if *recordFile != "" && (*mockMode || *demoFile != "") {
+		log.Fatal("--record needs a real account, not --mock or --demo")
+	}
+	if code, ok := runCommand(flag.Args()); ok {
+		os.Exit(code)
+	}
+
 	colors, err := config.LoadColors()
 	if err != nil {
 		log.Fatal("Failed to load colors:", err)
 	}
 
-	// Set initial terminal size to 90x11 (works in most terminals)
-	fmt.Print("\033[8;11;90t")
+	settings, err := config.LoadSettings()
+	if err != nil {
+		log.Fatal("Failed to load settings:", err)
+	}
+
+	authenticate := auth.Authenticate
+	cleanupMock := func() {}
+	saveRecording := func() error { return nil }
+	switch {
+	case *mockMode && *demoFile != "":
+		log.Fatal("--mock and --demo can't be combined")
+	case *mockMode:
+		authenticate, cleanupMock, err = startMock(settings)
+		if err != nil {
+			log.Fatal("Failed to start mock mode:", err)
+		}
+	case *demoFile != "":
+		authenticate, cleanupMock, err = startDemo(settings, *demoFile)
+		if err != nil {
+			log.Fatal("Failed to load demo session:", err)
+		}
+	case *recordFile != "":
+		saveRecording = startRecording(*recordFile)
+	case daemon.Running():
+		// Attach: the daemon makes the API calls, with its login, and
+		// sends the state it polls
+		root.AttachDaemon()
+		authenticate = func() (*spotify.Client, error) { return daemon.Client(), nil }
+	case platform.Remote():
+		// Over SSH logging in means pasting an address into the terminal,
+		// which has to happen before the program takes it over
+		client, err := auth.Authenticate()
+		if err != nil {
+			log.Fatal("Failed to log in:", err)
+		}
+		authenticate = func() (*spotify.Client, error) { return client, nil }
+	}
 
-	p := tea.NewProgram(
-		initialModel(colors),
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if settings.ScreenReader {
+		// Stay in the normal screen so announcements scroll like any other
+		// output, and keep redraws to a minimum
+		opts = nil
+		settings.ReducedMotion = true
+	}
+	// Shares the terminal with CopyToClipboard's OSC 52 sequence
+	opts = append(opts, tea.WithOutput(platform.Stdout))
 
-	if err := p.Start(); err != nil {
+	if err := applyColorMode(settings.ColorMode); err != nil {
 		log.Fatal(err)
 	}
+
+	if settings.TerminalTitle {
+		// Save the current title on xterm's title stack so it can be restored
+		fmt.Print("\033[22;0t")
+	}
+
+	p := tea.NewProgram(initialModel(colors, settings, authenticate), opts...)
+
+	// With a daemon running, it owns the control pipe and the power events
+	ctx, stopListeners := context.WithCancel(context.Background())
+	removeFIFO := func() {}
+	if settings.ControlFIFO && !daemon.Running() {
+		if path, err := daemon.FIFOPath(); err == nil {
+			removeFIFO = func() {
+				// Don't wait for ServeFIFO's cleanup: a leftover pipe
+				// would block writers
+				_ = os.Remove(path)
+			}
+			go func() {
+				// The pipe is optional; a failure just leaves it out
+				_ = daemon.ServeFIFO(ctx, path, func(req daemon.Request) {
+					p.Send(root.ControlMsg{Cmd: req.Cmd, Args: req.Args})
+				})
+			}()
+		}
+	}
+
+	if len(settings.PauseOn) > 0 && !daemon.Running() {
+		go func() {
+			// Unsupported platforms just don't pause
+			_ = platform.WatchPower(ctx, func(event string) {
+				p.Send(root.PowerMsg{Event: event})
+			})
+		}()
+	}
+
+	stopMPRIS := func() {}
+	if settings.MPRIS {
+		stopMPRIS = startMPRIS(p)
+	}
+
+	final, err := p.Run()
+	stopListeners()
+	removeFIFO()
+	stopMPRIS()
+	if settings.EnhancedKeyboard {
+		fmt.Print(keyboard.Disable)
+	}
+	if settings.TerminalTitle {
+		fmt.Print("\033[23;0t")
+	}
+	// The headless player's lifetime is tied to ours
+	spotifylauncher.StopHeadless()
+	if err := saveRecording(); err != nil {
+		log.Println("Could not save recording:", err)
+	}
+	if err != nil {
+		cleanupMock()
+		log.Fatal(err)
+	}
+
+	if rm, ok := final.(root.RootModel); ok {
+		rm.Close()
+		if err := config.SaveSession(rm.Session()); err != nil {
+			log.Println("Could not save session:", err)
+		}
+	}
+
+	cleanupMock()
+
+	if settings.QuitSpotifyOnExit && spotifylauncher.LaunchedSpotify() {
+		// Leave the client running if music is still playing
+		if rm, ok := final.(root.RootModel); !ok || !rm.IsPlaying() {
+			_ = spotifylauncher.QuitSpotify()
+		}
+	}
+
+	if rm, ok := final.(root.RootModel); ok {
+		if name, ok := rm.SwitchProfile(); ok {
+			if err := switchProfile(name); err != nil {
+				log.Fatal("Could not switch profile:", err)
+			}
+		}
+	}
 }
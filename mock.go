@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+	"github.com/metolius25/spotirice/internal/mock"
+	"github.com/metolius25/spotirice/internal/ui/root"
+)
+
+// startMock points the player at the built-in mock server for --mock.
+func startMock(settings *config.Settings) (authenticate func() (*spotify.Client, error), cleanup func(), err error) {
+	cleanup, err = isolate(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	srv := mock.New()
+	root.SetTransport(srv.Transport())
+	authenticate = func() (*spotify.Client, error) { return srv.Client(), nil }
+	return authenticate, cleanup, nil
+}
+
+// isolate prepares a session against a fake Spotify (--mock, --demo). State
+// and cache go to a temporary directory, removed by cleanup, so the session
+// leaves the real last device, history and session alone. Features that
+// reach outside the process (the control pipe, power events, party requests
+// from the daemon, MPRIS) are switched off.
+func isolate(settings *config.Settings) (cleanup func(), err error) {
+	dir, err := os.MkdirTemp("", "spotirice-mock-")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"SPOTIRICE_STATE_DIR", "SPOTIRICE_CACHE_DIR"} {
+		if err := os.Setenv(name, dir); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+	}
+
+	settings.ControlFIFO = false
+	settings.PauseOn = nil
+	settings.MPRIS = false
+	settings.Party = config.Party{}
+	return func() { os.RemoveAll(dir) }, nil
+}
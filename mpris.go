@@ -0,0 +1,21 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/metolius25/spotirice/internal/mpris"
+	"github.com/metolius25/spotirice/internal/ui/root"
+)
+
+// startMPRIS offers the TUI to MPRIS clients until stop is called. Without
+// a session bus (no desktop, another OS) it quietly does nothing.
+func startMPRIS(p *tea.Program) (stop func()) {
+	server, err := mpris.Serve(func(cmd string, args ...string) {
+		p.Send(root.ControlMsg{Cmd: cmd, Args: args})
+	})
+	if err != nil {
+		return func() {}
+	}
+	root.OnPlayback(server.Update)
+	return func() { _ = server.Close() }
+}
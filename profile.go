@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// withProfile returns the command-line arguments args with --profile set to
+// name, replacing any --profile already among them.
+func withProfile(args []string, name string) []string {
+	out := make([]string, 0, len(args)+1)
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		switch {
+		case a == "-profile" || a == "--profile":
+			i++ // and its value
+			continue
+		case strings.HasPrefix(a, "-profile=") || strings.HasPrefix(a, "--profile="):
+			continue
+		}
+		out = append(out, a)
+	}
+	return append([]string{"--profile=" + name}, out...)
+}
+
+// switchProfile starts the player again with profile name, in place of this
+// process where the OS allows.
+func switchProfile(name string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return restart(exe, withProfile(os.Args[1:], name))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// maxQueueTracks caps how many tracks of an album or playlist get queued.
+const maxQueueTracks = 100
+
+// spotifyURI turns a spotify: URI or an open.spotify.com link into a URI.
+// ok is false for anything else, which is then treated as a search query.
+func spotifyURI(arg string) (spotify.URI, bool) {
+	if strings.HasPrefix(arg, "spotify:") {
+		return spotify.URI(arg), true
+	}
+	u, err := url.Parse(arg)
+	if err != nil || u.Host != "open.spotify.com" {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	// Localised links carry a prefix: /intl-de/track/<id>
+	if len(parts) == 3 && strings.HasPrefix(parts[0], "intl-") {
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return "", false
+	}
+	return spotify.URI("spotify:" + parts[0] + ":" + parts[1]), true
+}
+
+// runQueue adds a track, or the tracks of an album or playlist, to the
+// queue. Anything that isn't a Spotify URI or link is searched for and its
+// first track result queued.
+func runQueue(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: spotirice queue <uri|url|query>")
+	}
+	arg := strings.Join(args, " ")
+
+	client, err := apiClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	tracks, from, err := resolveTracks(ctx, client, arg)
+	if err != nil {
+		return err
+	}
+	if err := queueTracks(ctx, client, tracks); err != nil {
+		return err
+	}
+	if len(tracks) == 1 {
+		fmt.Println("Queued:", describeTrack(tracks[0]))
+	} else {
+		fmt.Printf("Queued %d tracks from %s\n", len(tracks), from)
+	}
+	return nil
+}
+
+// resolveTracks returns the tracks arg stands for, and a name for where they
+// came from.
+func resolveTracks(ctx context.Context, c *spotify.Client, arg string) ([]spotify.SimpleTrack, string, error) {
+	uri, ok := spotifyURI(arg)
+	if !ok {
+		res, err := c.Search(ctx, arg, spotify.SearchTypeTrack, spotify.Limit(1))
+		if err != nil {
+			return nil, "", err
+		}
+		if res.Tracks == nil || len(res.Tracks.Tracks) == 0 {
+			return nil, "", fmt.Errorf("no track found for %q", arg)
+		}
+		return []spotify.SimpleTrack{res.Tracks.Tracks[0].SimpleTrack}, "", nil
+	}
+
+	parts := strings.Split(string(uri), ":")
+	if len(parts) != 3 {
+		return nil, "", fmt.Errorf("can't queue %s", uri)
+	}
+	id := spotify.ID(parts[2])
+	switch parts[1] {
+	case "track":
+		t, err := c.GetTrack(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		return []spotify.SimpleTrack{t.SimpleTrack}, "", nil
+	case "album":
+		album, err := c.GetAlbum(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		tracks := album.Tracks.Tracks
+		if len(tracks) > maxQueueTracks {
+			tracks = tracks[:maxQueueTracks]
+		}
+		return tracks, album.Name, nil
+	case "playlist":
+		pl, err := c.GetPlaylist(ctx, id, spotify.Fields("name"))
+		if err != nil {
+			return nil, "", err
+		}
+		items, err := c.GetPlaylistItems(ctx, id, spotify.Limit(maxQueueTracks))
+		if err != nil {
+			return nil, "", err
+		}
+		var tracks []spotify.SimpleTrack
+		for _, item := range items.Items {
+			// Episodes and unavailable local files can't go through QueueSong
+			if t := item.Track.Track; t != nil && t.ID != "" {
+				tracks = append(tracks, t.SimpleTrack)
+			}
+		}
+		if len(tracks) == 0 {
+			return nil, "", fmt.Errorf("%s has no tracks to queue", pl.Name)
+		}
+		return tracks, pl.Name, nil
+	}
+	return nil, "", fmt.Errorf("only tracks, albums and playlists can be queued, not %s", parts[1])
+}
+
+// queueTracks adds tracks to the queue in order.
+func queueTracks(ctx context.Context, c *spotify.Client, tracks []spotify.SimpleTrack) error {
+	for _, t := range tracks {
+		if err := c.QueueSong(ctx, t.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeTrack(t spotify.SimpleTrack) string {
+	if len(t.Artists) == 0 {
+		return t.Name
+	}
+	return t.Artists[0].Name + " – " + t.Name
+}
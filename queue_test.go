@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func TestSpotifyURI(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want spotify.URI // "" for a search query
+	}{
+		{"spotify:track:4cOdK2wGLETKBW3PvgPWqT", "spotify:track:4cOdK2wGLETKBW3PvgPWqT"},
+		{"spotify:playlist:37i9dQZF1DX4sWSpwq3LiO", "spotify:playlist:37i9dQZF1DX4sWSpwq3LiO"},
+		{"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT", "spotify:track:4cOdK2wGLETKBW3PvgPWqT"},
+		{"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc123", "spotify:track:4cOdK2wGLETKBW3PvgPWqT"},
+		{"https://open.spotify.com/album/1DFixLWuPkv3KT3TnV35m3/", "spotify:album:1DFixLWuPkv3KT3TnV35m3"},
+		{"https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT", "spotify:track:4cOdK2wGLETKBW3PvgPWqT"},
+		{"http://open.spotify.com/playlist/37i9dQZF1DX4sWSpwq3LiO", "spotify:playlist:37i9dQZF1DX4sWSpwq3LiO"},
+		{"https://open.spotify.com/", ""},
+		{"https://open.spotify.com/track", ""},
+		{"https://open.spotify.com/user/me/playlist/37i9dQZF1DX4sWSpwq3LiO", ""},
+		{"https://example.com/track/4cOdK2wGLETKBW3PvgPWqT", ""},
+		{"daft punk around the world", ""},
+		{"spotify", ""},
+	}
+	for _, tt := range tests {
+		got, ok := spotifyURI(tt.arg)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("spotifyURI(%q) = %q, %v; want %q", tt.arg, got, ok, tt.want)
+		}
+	}
+}
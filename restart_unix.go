@@ -0,0 +1,13 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// restart replaces this process with exe run with args.
+func restart(exe string, args []string) error {
+	return syscall.Exec(exe, append([]string{os.Args[0]}, args...), os.Environ())
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/daemon"
+)
+
+// rpcPollInterval is tighter than the daemon's so state notifications
+// reach editor plugins promptly.
+const rpcPollInterval = 2 * time.Second
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// rpcTrack is a track in search and queue results.
+type rpcTrack struct {
+	URI    string `json:"uri"`
+	Name   string `json:"name"`
+	Artist string `json:"artist"`
+	Album  string `json:"album,omitempty"`
+}
+
+func newRPCTrack(t spotify.SimpleTrack, album string) rpcTrack {
+	rt := rpcTrack{URI: string(t.URI), Name: t.Name, Album: album}
+	if len(t.Artists) > 0 {
+		rt.Artist = t.Artists[0].Name
+	}
+	return rt
+}
+
+type rpcServer struct {
+	client *spotify.Client
+	ctrl   *daemon.Controller
+
+	mu  sync.Mutex // serializes writes to stdout
+	enc *json.Encoder
+}
+
+func (s *rpcServer) write(v any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.enc.Encode(v)
+}
+
+// runRPC speaks newline-delimited JSON-RPC 2.0 on stdin/stdout until stdin
+// closes, for editor plugins that embed Spotirice as a backend. Methods:
+// status, control {command, args}, search {query, limit} and queue {target}.
+// A "state" notification is sent whenever the player state changes.
+func runRPC() error {
+	// Login prompts go to stderr so they can't corrupt the JSON stream
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	ctrl, err := playerController()
+	os.Stdout = stdout
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := &rpcServer{client: ctrl.Client(), ctrl: ctrl, enc: json.NewEncoder(stdout)}
+	s.ctrl.OnChange = func(st daemon.Status) {
+		s.write(rpcNotification{JSONRPC: "2.0", Method: "state", Params: st})
+	}
+	go s.ctrl.Poll(ctx, rpcPollInterval)
+
+	// Calls still running when stdin closes get to send their replies
+	var calls sync.WaitGroup
+	defer calls.Wait()
+
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		var req rpcRequest
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			s.write(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+			continue
+		}
+		// Requests run concurrently so a slow search doesn't hold up control
+		calls.Add(1)
+		go func() {
+			defer calls.Done()
+			result, rerr := s.call(ctx, req)
+			if req.ID == nil {
+				return // notification from the client; no reply
+			}
+			resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}
+			if rerr == nil && result == nil {
+				resp.Result = true
+			}
+			s.write(resp)
+		}()
+	}
+	return sc.Err()
+}
+
+func (s *rpcServer) call(ctx context.Context, req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "status":
+		resp := s.ctrl.Do(ctx, daemon.Request{Cmd: "status"})
+		return resp.Status, nil
+
+	case "control":
+		var p struct {
+			Command string   `json:"command"`
+			Args    []string `json:"args"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil || p.Command == "" || p.Command == "status" {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: `params must be {"command": "...", "args": [...]}`}
+		}
+		if resp := s.ctrl.Do(ctx, daemon.Request{Cmd: p.Command, Args: p.Args}); resp.Error != "" {
+			return nil, &rpcError{Code: rpcServerError, Message: resp.Error}
+		}
+		return nil, nil
+
+	case "search":
+		var p struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil || p.Query == "" {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: `params must be {"query": "...", "limit": N}`}
+		}
+		if p.Limit <= 0 || p.Limit > 50 {
+			p.Limit = 10
+		}
+		res, err := s.client.Search(ctx, p.Query, spotify.SearchTypeTrack, spotify.Limit(p.Limit))
+		if err != nil {
+			return nil, &rpcError{Code: rpcServerError, Message: err.Error()}
+		}
+		tracks := []rpcTrack{}
+		if res.Tracks != nil {
+			for _, t := range res.Tracks.Tracks {
+				tracks = append(tracks, newRPCTrack(t.SimpleTrack, t.Album.Name))
+			}
+		}
+		return tracks, nil
+
+	case "queue":
+		var p struct {
+			Target string `json:"target"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil || p.Target == "" {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: `params must be {"target": "<uri|url|query>"}`}
+		}
+		tracks, from, err := resolveTracks(ctx, s.client, p.Target)
+		if err == nil {
+			err = queueTracks(ctx, s.client, tracks)
+		}
+		if err != nil {
+			return nil, &rpcError{Code: rpcServerError, Message: err.Error()}
+		}
+		queued := make([]rpcTrack, 0, len(tracks))
+		for _, t := range tracks {
+			queued = append(queued, newRPCTrack(t, from))
+		}
+		return queued, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/metolius25/spotirice/internal/auth"
+	"github.com/metolius25/spotirice/internal/daemon"
+)
+
+// statusJSON is the --json form of the player state. Scripts rely on it, so
+// fields may be added but never renamed or removed.
+type statusJSON struct {
+	Track      string    `json:"track"`
+	Artist     string    `json:"artist"`
+	Album      string    `json:"album"`
+	IDs        statusIDs `json:"ids"`
+	ProgressMs int       `json:"progress_ms"`
+	DurationMs int       `json:"duration_ms"`
+	Playing    bool      `json:"playing"`
+	Device     string    `json:"device"`
+	Volume     int       `json:"volume"`
+	Shuffle    bool      `json:"shuffle"`
+	Repeat     string    `json:"repeat"`
+}
+
+// statusIDs are the Spotify IDs of the playing track, its (first) artist and
+// its album; empty when nothing is playing.
+type statusIDs struct {
+	Track  string `json:"track"`
+	Artist string `json:"artist"`
+	Album  string `json:"album"`
+}
+
+// runStatus prints what is playing, as a line or, with --json, as one JSON
+// object.
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the state as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	read, err := statusReader()
+	if err != nil {
+		return err
+	}
+	st, err := read(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		repeat := st.Repeat
+		if repeat == "" {
+			repeat = "off"
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statusJSON{
+			Track:      st.Track,
+			Artist:     st.Artist,
+			Album:      st.Album,
+			IDs:        statusIDs{Track: st.ID, Artist: st.ArtistID, Album: st.AlbumID},
+			ProgressMs: st.ProgressMs,
+			DurationMs: st.DurationMs,
+			Playing:    st.Playing,
+			Device:     st.Device,
+			Volume:     st.Volume,
+			Shuffle:    st.Shuffle,
+			Repeat:     repeat,
+		})
+	}
+
+	if st.Track == "" {
+		fmt.Println("Nothing playing")
+		return nil
+	}
+	state := "Paused"
+	if st.Playing {
+		state = "Playing"
+	}
+	line := fmt.Sprintf("%s: %s – %s", state, st.Artist, st.Track)
+	if st.Album != "" {
+		line += " (" + st.Album + ")"
+	}
+	fmt.Println(line)
+	fmt.Printf("%s / %s on %s, volume %d%%\n", barDuration(st.ProgressMs), barDuration(st.DurationMs), st.Device, st.Volume)
+	return nil
+}
+
+// statusReader returns how to read the player state: from the daemon when
+// one is running, which costs no API calls of its own, and from Spotify
+// otherwise.
+func statusReader() (func(context.Context) (daemon.Status, error), error) {
+	if daemon.Running() {
+		return func(context.Context) (daemon.Status, error) {
+			resp, err := daemon.Send(daemon.Request{Cmd: "status"})
+			if err != nil {
+				return daemon.Status{}, err
+			}
+			if resp.Error != "" {
+				return daemon.Status{}, errors.New(resp.Error)
+			}
+			if resp.Status == nil {
+				return daemon.Status{}, errors.New("the daemon sent no status")
+			}
+			return *resp.Status, nil
+		}, nil
+	}
+	client, err := auth.Authenticate()
+	if err != nil {
+		return nil, err
+	}
+	return daemon.NewController(client).Refresh, nil
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/metolius25/spotirice/internal/config"
+)
+
+// wrappedRanges are the API's top item periods, shortest first.
+var wrappedRanges = []struct {
+	Range spotify.Range
+	Label string
+}{
+	{spotify.ShortTermRange, "Last 4 weeks"},
+	{spotify.MediumTermRange, "Last 6 months"},
+	{spotify.LongTermRange, "All time"},
+}
+
+type wrappedItem struct {
+	Name   string `json:"name"`
+	Artist string `json:"artist,omitempty"`
+	Plays  int    `json:"plays,omitempty"`
+}
+
+type wrappedPeriod struct {
+	Range   string        `json:"range"`
+	Label   string        `json:"label"`
+	Tracks  []wrappedItem `json:"tracks"`
+	Artists []wrappedItem `json:"artists"`
+}
+
+// historySummary aggregates the local history (record_history).
+type historySummary struct {
+	Plays            int           `json:"plays"`
+	Since            time.Time     `json:"since"`
+	ListeningMinutes int           `json:"listening_minutes"`
+	BusiestHour      int           `json:"busiest_hour"`
+	BusiestDay       string        `json:"busiest_day"`
+	Tracks           []wrappedItem `json:"tracks"`
+	Artists          []wrappedItem `json:"artists"`
+}
+
+type wrappedReport struct {
+	Periods []wrappedPeriod `json:"periods"`
+	History *historySummary `json:"history,omitempty"`
+}
+
+// runWrapped prints top tracks and artists for each period, plus what the
+// local history says, as text, Markdown or JSON.
+func runWrapped(args []string) error {
+	fs := flag.NewFlagSet("wrapped", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format: text, markdown or json")
+	limit := fs.Int("limit", 10, "number of tracks and artists per list (1-50)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *limit < 1 || *limit > 50 {
+		return errors.New("--limit must be between 1 and 50")
+	}
+
+	client, err := apiClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var report wrappedReport
+	for _, r := range wrappedRanges {
+		p, err := fetchWrappedPeriod(ctx, client, r.Range, *limit)
+		if err != nil {
+			var se spotify.Error
+			if errors.As(err, &se) && se.Status == http.StatusForbidden {
+				return errors.New("your saved login can't read top items yet; delete token.json from the state directory and log in again")
+			}
+			return err
+		}
+		p.Label = r.Label
+		report.Periods = append(report.Periods, p)
+	}
+
+	history, err := config.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("could not read history: %w", err)
+	}
+	if len(history) > 0 {
+		report.History = summarizeHistory(history, *limit)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "markdown", "md":
+		writeWrapped(os.Stdout, report, true)
+	case "text":
+		writeWrapped(os.Stdout, report, false)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	return nil
+}
+
+func fetchWrappedPeriod(ctx context.Context, c *spotify.Client, r spotify.Range, limit int) (wrappedPeriod, error) {
+	p := wrappedPeriod{Range: string(r), Tracks: []wrappedItem{}, Artists: []wrappedItem{}}
+	tracks, err := c.CurrentUsersTopTracks(ctx, spotify.Timerange(r), spotify.Limit(limit))
+	if err != nil {
+		return p, err
+	}
+	for _, t := range tracks.Tracks {
+		item := wrappedItem{Name: t.Name}
+		if len(t.Artists) > 0 {
+			item.Artist = t.Artists[0].Name
+		}
+		p.Tracks = append(p.Tracks, item)
+	}
+	artists, err := c.CurrentUsersTopArtists(ctx, spotify.Timerange(r), spotify.Limit(limit))
+	if err != nil {
+		return p, err
+	}
+	for _, a := range artists.Artists {
+		p.Artists = append(p.Artists, wrappedItem{Name: a.Name})
+	}
+	return p, nil
+}
+
+func summarizeHistory(entries []config.HistoryEntry, limit int) *historySummary {
+	s := &historySummary{Plays: len(entries), Since: entries[0].Time}
+	trackPlays := map[string]*wrappedItem{}
+	artistPlays := map[string]*wrappedItem{}
+	var hours [24]int
+	var days [7]int
+	var ms int
+	for _, e := range entries {
+		ms += e.DurationMs
+		local := e.Time.Local()
+		hours[local.Hour()]++
+		days[local.Weekday()]++
+		if t := trackPlays[e.TrackID]; t != nil {
+			t.Plays++
+		} else {
+			trackPlays[e.TrackID] = &wrappedItem{Name: e.Track, Artist: e.Artist, Plays: 1}
+		}
+		if e.Artist != "" {
+			if a := artistPlays[e.Artist]; a != nil {
+				a.Plays++
+			} else {
+				artistPlays[e.Artist] = &wrappedItem{Name: e.Artist, Plays: 1}
+			}
+		}
+	}
+	// Durations are full track lengths, so skips count in full
+	s.ListeningMinutes = ms / 60000
+	s.BusiestHour = maxIndex(hours[:])
+	s.BusiestDay = time.Weekday(maxIndex(days[:])).String()
+	s.Tracks = topItems(trackPlays, limit)
+	s.Artists = topItems(artistPlays, limit)
+	return s
+}
+
+func maxIndex(counts []int) int {
+	best := 0
+	for i, n := range counts {
+		if n > counts[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// topItems sorts by plays, then name, and keeps the first limit.
+func topItems(items map[string]*wrappedItem, limit int) []wrappedItem {
+	out := make([]wrappedItem, 0, len(items))
+	for _, it := range items {
+		out = append(out, *it)
+	}
+	slices.SortFunc(out, func(a, b wrappedItem) int {
+		if c := cmp.Compare(b.Plays, a.Plays); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return out[:min(limit, len(out))]
+}
+
+// writeWrapped renders the report as plain text or Markdown.
+func writeWrapped(w io.Writer, r wrappedReport, markdown bool) {
+	heading := func(level int, title string) {
+		if markdown {
+			fmt.Fprintf(w, "%s %s\n\n", strings.Repeat("#", level), title)
+			return
+		}
+		underline := "-"
+		if level == 1 {
+			underline = "="
+		}
+		fmt.Fprintln(w, title)
+		fmt.Fprintln(w, strings.Repeat(underline, len([]rune(title))))
+	}
+	list := func(title string, items []wrappedItem) {
+		if markdown {
+			fmt.Fprintf(w, "**%s**\n\n", title)
+		} else {
+			fmt.Fprintf(w, "%s:\n", title)
+		}
+		if len(items) == 0 {
+			if markdown {
+				fmt.Fprintln(w, "_None yet._")
+			} else {
+				fmt.Fprintln(w, "  (none yet)")
+			}
+		}
+		for i, it := range items {
+			line := it.Name
+			if it.Artist != "" {
+				line += " – " + it.Artist
+			}
+			switch {
+			case it.Plays == 1:
+				line += " (1 play)"
+			case it.Plays > 1:
+				line += fmt.Sprintf(" (%d plays)", it.Plays)
+			}
+			if markdown {
+				fmt.Fprintf(w, "%d. %s\n", i+1, line)
+			} else {
+				fmt.Fprintf(w, "  %2d. %s\n", i+1, line)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	heading(1, "Spotirice Wrapped")
+	if !markdown {
+		fmt.Fprintln(w)
+	}
+	for _, p := range r.Periods {
+		heading(2, p.Label)
+		list("Top tracks", p.Tracks)
+		list("Top artists", p.Artists)
+	}
+
+	heading(2, "Local history")
+	h := r.History
+	if h == nil {
+		fmt.Fprintln(w, "No plays recorded yet; set record_history = true in config.toml.")
+		return
+	}
+	amount := fmt.Sprintf("about %d hours", h.ListeningMinutes/60)
+	if h.ListeningMinutes < 120 {
+		amount = fmt.Sprintf("%d minutes", h.ListeningMinutes)
+	}
+	fmt.Fprintf(w, "%d plays since %s, %s of music.\n", h.Plays, h.Since.Local().Format("2 Jan 2006"), amount)
+	fmt.Fprintf(w, "You listen most on %ss, and around %02d:00.\n\n", h.BusiestDay, h.BusiestHour)
+	list("Most played tracks", h.Tracks)
+	list("Most played artists", h.Artists)
+}